@@ -0,0 +1,92 @@
+package cpuinfo
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Feature is a bit set of SIMD instruction set extensions.
+type Feature uint32
+
+const (
+	FeatureMMX Feature = 1 << iota
+	FeatureSSE
+	FeatureSSE2
+	FeatureSSE3
+	FeatureSSE41
+	FeatureSSE42
+	FeatureAVX
+	FeatureAVX2
+	FeatureAVX512F
+	FeatureNEON
+)
+
+// defaultCacheLineSize is used when the cache line size cannot be determined.
+const defaultCacheLineSize = 64
+
+// Info describes the processor and memory of the machine.
+type Info struct {
+	Count         int     // number of logical CPUs
+	CacheLineSize int     // L1 cache line size in bytes
+	Features      Feature // supported SIMD extensions
+	SystemRAM     int     // system memory in MiB, 0 if unknown
+}
+
+// Has returns whether every feature in f is supported.
+func (i *Info) Has(f Feature) bool {
+	return i.Features&f == f
+}
+
+var (
+	once    sync.Once
+	current *Info
+)
+
+func get() *Info {
+	once.Do(func() {
+		info, err := Load("/")
+		if err != nil {
+			info = &Info{Count: runtime.NumCPU(), CacheLineSize: defaultCacheLineSize}
+		}
+		current = info
+	})
+	return current
+}
+
+// GetPlatform returns the name of the platform the program is running on.
+func GetPlatform() string {
+	switch runtime.GOOS {
+	case "linux":
+		return "Linux"
+	case "windows":
+		return "Windows"
+	case "darwin":
+		return "Mac OS X"
+	case "freebsd":
+		return "FreeBSD"
+	case "netbsd":
+		return "NetBSD"
+	case "openbsd":
+		return "OpenBSD"
+	case "android":
+		return "Android"
+	case "ios":
+		return "iOS"
+	}
+	return runtime.GOOS
+}
+
+func GetCPUCount() int         { return get().Count }
+func GetCPUCacheLineSize() int { return get().CacheLineSize }
+func GetSystemRAM() int        { return get().SystemRAM }
+func GetFeatures() Feature     { return get().Features }
+func HasMMX() bool             { return get().Has(FeatureMMX) }
+func HasSSE() bool             { return get().Has(FeatureSSE) }
+func HasSSE2() bool            { return get().Has(FeatureSSE2) }
+func HasSSE3() bool            { return get().Has(FeatureSSE3) }
+func HasSSE41() bool           { return get().Has(FeatureSSE41) }
+func HasSSE42() bool           { return get().Has(FeatureSSE42) }
+func HasAVX() bool             { return get().Has(FeatureAVX) }
+func HasAVX2() bool            { return get().Has(FeatureAVX2) }
+func HasAVX512F() bool         { return get().Has(FeatureAVX512F) }
+func HasNEON() bool            { return get().Has(FeatureNEON) }
@@ -0,0 +1,128 @@
+// +build linux
+
+package cpuinfo
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var cpuFlags = map[string]Feature{
+	"mmx":     FeatureMMX,
+	"sse":     FeatureSSE,
+	"sse2":    FeatureSSE2,
+	"pni":     FeatureSSE3,
+	"sse4_1":  FeatureSSE41,
+	"sse4_2":  FeatureSSE42,
+	"avx":     FeatureAVX,
+	"avx2":    FeatureAVX2,
+	"avx512f": FeatureAVX512F,
+	"neon":    FeatureNEON,
+	"asimd":   FeatureNEON,
+}
+
+// Load reads the processor information from the proc and sys file systems
+// mounted below root, root is "/" for the running system.
+func Load(root string) (*Info, error) {
+	info := &Info{}
+	if err := parseCPUInfo(root, info); err != nil {
+		return nil, err
+	}
+	if info.Count == 0 {
+		info.Count = runtime.NumCPU()
+	}
+	if filepath.Clean(root) == "/" && runtime.GOARCH == "arm64" {
+		// Advanced SIMD is mandatory on aarch64, other roots describe
+		// another machine so only their flags count
+		info.Features |= FeatureNEON
+	}
+
+	sizePath := filepath.Join(root, "sys/devices/system/cpu/cpu0/cache/index0/coherency_line_size")
+	if size, err := readInt(sizePath); err == nil && size > 0 {
+		info.CacheLineSize = size
+	}
+	if info.CacheLineSize == 0 {
+		info.CacheLineSize = defaultCacheLineSize
+	}
+
+	if err := parseMemInfo(root, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func parseCPUInfo(root string, info *Info) error {
+	f, err := os.Open(filepath.Join(root, "proc/cpuinfo"))
+	if err != nil {
+		return errors.Wrap(err, "unable to open cpuinfo")
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 4096), 1<<20)
+	for scanner.Scan() {
+		key, value := splitField(scanner.Text())
+		switch key {
+		case "processor":
+			info.Count++
+		case "flags", "Features":
+			for _, flag := range strings.Fields(value) {
+				info.Features |= cpuFlags[flag]
+			}
+		case "cache_alignment":
+			if size, err := strconv.Atoi(value); err == nil {
+				info.CacheLineSize = size
+			}
+		}
+	}
+	return errors.Wrap(scanner.Err(), "unable to read cpuinfo")
+}
+
+func parseMemInfo(root string, info *Info) error {
+	f, err := os.Open(filepath.Join(root, "proc/meminfo"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to open meminfo")
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value := splitField(scanner.Text())
+		if key != "MemTotal" {
+			continue
+		}
+		kb, err := strconv.Atoi(strings.TrimSuffix(value, " kB"))
+		if err != nil {
+			return errors.Wrap(err, "invalid MemTotal")
+		}
+		info.SystemRAM = kb / 1024
+		return nil
+	}
+	return errors.Wrap(scanner.Err(), "unable to read meminfo")
+}
+
+func splitField(line string) (string, string) {
+	i := strings.IndexByte(line, ':')
+	if i < 0 {
+		return strings.TrimSpace(line), ""
+	}
+	return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+}
+
+func readInt(path string) (int, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
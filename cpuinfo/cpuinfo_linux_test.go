@@ -0,0 +1,88 @@
+// +build linux
+
+package cpuinfo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const x86CPUInfo = `processor	: 0
+vendor_id	: GenuineIntel
+flags		: fpu vme de pse tsc msr mmx fxsr sse sse2 ss ht pni ssse3 sse4_1 sse4_2 avx f16c avx2
+cache_alignment	: 64
+
+processor	: 1
+vendor_id	: GenuineIntel
+flags		: fpu vme de pse tsc msr mmx fxsr sse sse2 ss ht pni ssse3 sse4_1 sse4_2 avx f16c avx2
+cache_alignment	: 64
+`
+
+const armCPUInfo = `processor	: 0
+BogoMIPS	: 38.40
+Features	: half thumb fastmult vfp edsp neon vfpv3 tls vfpv4 idiva idivt
+CPU implementer	: 0x41
+`
+
+const memInfo = `MemTotal:       16333852 kB
+MemFree:         1263936 kB
+`
+
+func writeTree(t *testing.T, files map[string]string) string {
+	root, err := ioutil.TempDir("", "cpuinfo")
+	require.NoError(t, err)
+	for name, data := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(data), 0644))
+	}
+	return root
+}
+
+func TestLoadX86(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"proc/cpuinfo": x86CPUInfo,
+		"proc/meminfo": memInfo,
+		"sys/devices/system/cpu/cpu0/cache/index0/coherency_line_size": "128\n",
+	})
+	defer os.RemoveAll(root)
+
+	info, err := Load(root)
+	require.NoError(t, err)
+	assert.Equal(t, 2, info.Count)
+	assert.Equal(t, 128, info.CacheLineSize)
+	assert.Equal(t, 15951, info.SystemRAM)
+	assert.True(t, info.Has(FeatureMMX|FeatureSSE|FeatureSSE2|FeatureSSE3|FeatureSSE41|FeatureSSE42|FeatureAVX|FeatureAVX2))
+	assert.False(t, info.Has(FeatureAVX512F))
+	assert.False(t, info.Has(FeatureNEON))
+}
+
+func TestLoadARM(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"proc/cpuinfo": armCPUInfo,
+	})
+	defer os.RemoveAll(root)
+
+	info, err := Load(root)
+	require.NoError(t, err)
+	assert.Equal(t, 1, info.Count)
+	assert.Equal(t, defaultCacheLineSize, info.CacheLineSize)
+	assert.Equal(t, 0, info.SystemRAM)
+	assert.Equal(t, FeatureNEON, info.Features)
+}
+
+func TestLoadMissingRoot(t *testing.T) {
+	_, err := Load(filepath.Join(os.TempDir(), "cpuinfo-does-not-exist"))
+	assert.Error(t, err)
+}
+
+func TestSystem(t *testing.T) {
+	assert.Equal(t, "Linux", GetPlatform())
+	assert.True(t, GetCPUCount() > 0)
+	assert.True(t, GetCPUCacheLineSize() > 0)
+}
@@ -0,0 +1,18 @@
+// +build !linux
+
+package cpuinfo
+
+import "runtime"
+
+// Load returns what can be determined about the processor without platform
+// support, root is ignored.
+func Load(root string) (*Info, error) {
+	info := &Info{
+		Count:         runtime.NumCPU(),
+		CacheLineSize: defaultCacheLineSize,
+	}
+	if runtime.GOARCH == "arm64" {
+		info.Features |= FeatureNEON
+	}
+	return info, nil
+}
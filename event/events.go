@@ -21,8 +21,16 @@ const (
 	AppDidEnterForeground
 )
 
-// Data is the raw event data,
-type Data [56]byte
+// NewQuitEvent creates an event requesting that the application quit.
+func NewQuitEvent() Data {
+	qe := Data{}
+	binary.LittleEndian.PutUint32(qe[0:4], Quit)
+	return qe
+}
+
+// Data is the raw event data, the first 56 bytes follow the SDL event layout
+// and the trailing 8 bytes hold the nanosecond timestamp.
+type Data [64]byte
 
 func (ed Data) Type() uint32 {
 	return binary.LittleEndian.Uint32(ed[0:4])
@@ -32,6 +40,10 @@ func (ed Data) Timestamp() uint32 {
 	return binary.LittleEndian.Uint32(ed[4:8])
 }
 
+func (ed Data) TimestampNS() uint64 {
+	return binary.LittleEndian.Uint64(ed[56:64])
+}
+
 func (ed Data) Raw() *Data {
 	return &ed
 }
@@ -39,6 +51,7 @@ func (ed Data) Raw() *Data {
 type Event interface {
 	Type() uint32
 	Timestamp() uint32
+	TimestampNS() uint64
 	Raw() *Data
 }
 
@@ -101,3 +114,10 @@ const (
 	RenderTargetsReset = 0x2000 + iota
 	RenderDeviceReset
 )
+
+// NewRenderEvent creates a RenderTargetsReset or RenderDeviceReset event.
+func NewRenderEvent(evType uint32) Data {
+	re := Data{}
+	binary.LittleEndian.PutUint32(re[0:4], evType)
+	return re
+}
@@ -44,7 +44,86 @@ func (mbe MouseButton) X() int32 {
 }
 
 func (mbe MouseButton) Y() int32 {
-	return int32(binary.LittleEndian.Uint16(mbe[24:26]))
+	return int32(binary.LittleEndian.Uint32(mbe[24:28]))
+}
+
+// SetPosition moves the event, for watchers that map it to other
+// coordinates.
+func (mbe *MouseButton) SetPosition(x, y int32) {
+	binary.LittleEndian.PutUint32(mbe[20:24], uint32(x))
+	binary.LittleEndian.PutUint32(mbe[24:28], uint32(y))
+}
+
+// NewMouseButtonEvent creates a MouseButtonDown or MouseButtonUp event.
+func NewMouseButtonEvent(evType, windowID, which uint32, button, state, clicks uint8, x, y int32) Data {
+	mbe := Data{}
+	binary.LittleEndian.PutUint32(mbe[0:4], evType)
+	binary.LittleEndian.PutUint32(mbe[8:12], windowID)
+	binary.LittleEndian.PutUint32(mbe[12:16], which)
+	mbe[16] = button
+	mbe[17] = state
+	mbe[18] = clicks
+	binary.LittleEndian.PutUint32(mbe[20:24], uint32(x))
+	binary.LittleEndian.PutUint32(mbe[24:28], uint32(y))
+	return mbe
+}
+
+// Mouse motion event structure (event.motion.*)
+type MouseMotionEvent Data
+
+func (mme MouseMotionEvent) WindowID() uint32 {
+	return binary.LittleEndian.Uint32(mme[8:12])
+}
+
+func (mme MouseMotionEvent) Which() uint32 {
+	return binary.LittleEndian.Uint32(mme[12:16])
+}
+
+func (mme MouseMotionEvent) State() uint32 {
+	return binary.LittleEndian.Uint32(mme[16:20])
+}
+
+func (mme MouseMotionEvent) X() int32 {
+	return int32(binary.LittleEndian.Uint32(mme[20:24]))
+}
+
+func (mme MouseMotionEvent) Y() int32 {
+	return int32(binary.LittleEndian.Uint32(mme[24:28]))
+}
+
+func (mme MouseMotionEvent) XRel() int32 {
+	return int32(binary.LittleEndian.Uint32(mme[28:32]))
+}
+
+func (mme MouseMotionEvent) YRel() int32 {
+	return int32(binary.LittleEndian.Uint32(mme[32:36]))
+}
+
+// SetPosition moves the event, for watchers that map it to other
+// coordinates.
+func (mme *MouseMotionEvent) SetPosition(x, y int32) {
+	binary.LittleEndian.PutUint32(mme[20:24], uint32(x))
+	binary.LittleEndian.PutUint32(mme[24:28], uint32(y))
+}
+
+// SetRel changes the relative motion of the event.
+func (mme *MouseMotionEvent) SetRel(xrel, yrel int32) {
+	binary.LittleEndian.PutUint32(mme[28:32], uint32(xrel))
+	binary.LittleEndian.PutUint32(mme[32:36], uint32(yrel))
+}
+
+// NewMouseMotionEvent creates a MouseMotion event.
+func NewMouseMotionEvent(windowID, which, state uint32, x, y, xrel, yrel int32) Data {
+	mme := Data{}
+	binary.LittleEndian.PutUint32(mme[0:4], MouseMotion)
+	binary.LittleEndian.PutUint32(mme[8:12], windowID)
+	binary.LittleEndian.PutUint32(mme[12:16], which)
+	binary.LittleEndian.PutUint32(mme[16:20], state)
+	binary.LittleEndian.PutUint32(mme[20:24], uint32(x))
+	binary.LittleEndian.PutUint32(mme[24:28], uint32(y))
+	binary.LittleEndian.PutUint32(mme[28:32], uint32(xrel))
+	binary.LittleEndian.PutUint32(mme[32:36], uint32(yrel))
+	return mme
 }
 
 type Mouse struct {
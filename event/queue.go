@@ -200,7 +200,10 @@ func (q *Queue) Peep(events []Event, action int, minType, maxType uint32) (int,
 		// TODO(mde): deal with wmmsg types
 		fallthrough
 	case Peek:
-		for entry := q.head; entry != nil && (events == nil || used < len(events)); entry = entry.next {
+		var next *Entry
+		for entry := q.head; entry != nil && (events == nil || used < len(events)); entry = next {
+			// Cut moves the entry to the free list, so remember what follows it
+			next = entry.next
 			if !(minType <= entry.ev.Type() && entry.ev.Type() <= maxType) {
 				continue
 			}
@@ -247,7 +250,9 @@ func (q *Queue) FlushTypes(minType, maxType uint32) error {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 
-	for entry := q.head; entry != nil; entry = entry.next {
+	var next *Entry
+	for entry := q.head; entry != nil; entry = next {
+		next = entry.next
 		if minType <= entry.ev.Type() && entry.ev.Type() <= maxType {
 			q.Cut(entry)
 		}
@@ -272,9 +277,9 @@ func (q *Queue) Wait() (Event, error) {
 }
 
 func (q *Queue) WaitTimeout(timeout time.Duration) (Event, error) {
-	var expiration time.Time
+	var expiration time.Duration
 	if timeout > 0 {
-		expiration = time.Now().Add(timeout)
+		expiration = ticker.Get() + timeout
 	}
 
 	for {
@@ -286,29 +291,35 @@ func (q *Queue) WaitTimeout(timeout time.Duration) (Event, error) {
 			return nil, errors.Wrap(err, "queue peep error")
 		case n == 1:
 			return buf[0], nil
-		case n == 0 && timeout != -1 && (timeout == 0 || time.Now().After(expiration)):
+		case n == 0 && timeout != -1 && (timeout == 0 || ticker.Get() >= expiration):
 			return nil, WaitTimeoutExceeded
 		default:
 			// I don't really like this, but they do the same in SDL2
-			time.Sleep(10 * time.Millisecond)
+			ticker.Delay(10 * time.Millisecond)
 		}
 	}
 }
 
 func (q *Queue) Push(ev Event) (bool, error) {
-	binary.LittleEndian.PutUint32(ev.Raw()[0:4], ticker.GetAsMS())
-	if !q.ok(q.okdata, ev) {
+	raw := ev.Raw()
+	binary.LittleEndian.PutUint32(raw[4:8], ticker.GetAsMS())
+	binary.LittleEndian.PutUint64(raw[56:64], ticker.GetTicksNS())
+	ev = *raw
+	if q.ok != nil && !q.ok(q.okdata, ev) {
 		return false, nil
 	}
 
+	// watchers get the event data itself as a *Data and may change it before
+	// it is queued. They are called without the lock, so they can push
+	// events of their own.
 	q.wmu.Lock()
-	for _, w := range q.watchers {
-		w.Callback(w.Userdata, ev)
-	}
+	watchers := append([]*Watcher(nil), q.watchers...)
 	q.wmu.Unlock()
+	for _, w := range watchers {
+		w.Callback(w.Userdata, raw)
+	}
 
-	buf := make([]Event, 1)
-	_, err := q.Peep(buf, Add, 0, 0)
+	_, err := q.Peep([]Event{*raw}, Add, 0, 0)
 	if err != nil {
 		return true, errors.Wrap(err, "unable to add event to queue")
 	}
@@ -343,7 +354,7 @@ func (q *Queue) DelWatch(watcher *Watcher) {
 	defer q.wmu.Unlock()
 	updatedWatchers := q.watchers[:0]
 	for _, w := range q.watchers {
-		if !(w == watcher) {
+		if w != watcher {
 			updatedWatchers = append(updatedWatchers, w)
 		}
 	}
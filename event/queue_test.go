@@ -0,0 +1,131 @@
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elliotmr/gdl/ticker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueuePushTimestamps(t *testing.T) {
+	fc := ticker.NewFakeClock(time.Unix(0, 0))
+	prev := ticker.SetClock(fc)
+	defer ticker.SetClock(prev)
+
+	q := &Queue{}
+	require.NoError(t, q.Start())
+	defer q.Stop()
+
+	fc.Advance(3*time.Second + 42*time.Nanosecond)
+	ok, err := q.Push(NewWindowEvent(7, WindowShown, 0, 0))
+	assert.True(t, ok)
+	require.NoError(t, err)
+
+	ev, err := q.Poll()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(WindowStateChange), ev.Type())
+	assert.Equal(t, uint32(3000), ev.Timestamp())
+	assert.Equal(t, uint64(3*time.Second+42*time.Nanosecond), ev.TimestampNS())
+	assert.Equal(t, uint32(7), Window(*ev.Raw()).WindowID())
+}
+
+func TestQueueWaitTimeout(t *testing.T) {
+	fc := ticker.NewFakeClock(time.Unix(0, 0))
+	prev := ticker.SetClock(fc)
+	defer ticker.SetClock(prev)
+
+	q := &Queue{}
+	require.NoError(t, q.Start())
+	defer q.Stop()
+
+	_, err := q.WaitTimeout(time.Second)
+	assert.Equal(t, WaitTimeoutExceeded, err)
+	assert.True(t, ticker.Get() >= time.Second)
+	assert.True(t, ticker.Get() < time.Second+20*time.Millisecond)
+}
+
+func TestQueueWatcherChangesEvent(t *testing.T) {
+	q := &Queue{}
+	require.NoError(t, q.Start())
+	defer q.Stop()
+
+	w := &Watcher{Callback: func(userdata interface{}, ev Event) bool {
+		if ev.Type() == MouseMotion {
+			(*MouseMotionEvent)(ev.(*Data)).SetPosition(1, 2)
+		}
+		return true
+	}}
+	q.AddWatch(w)
+	_, err := q.Push(NewMouseMotionEvent(3, 0, 0, 100, 200, -5, 6))
+	require.NoError(t, err)
+	q.DelWatch(w)
+	_, err = q.Push(NewTouchFingerEvent(FingerDown, 1, 2, 0.25, 0.5, 0, 0, 1, 3))
+	require.NoError(t, err)
+
+	ev, err := q.Poll()
+	require.NoError(t, err)
+	mme := MouseMotionEvent(*ev.Raw())
+	assert.Equal(t, []int32{1, 2, -5, 6}, []int32{mme.X(), mme.Y(), mme.XRel(), mme.YRel()})
+	assert.Equal(t, uint32(3), mme.WindowID())
+
+	ev, err = q.Poll()
+	require.NoError(t, err)
+	tfe := TouchFinger(*ev.Raw())
+	assert.Equal(t, uint32(FingerDown), ev.Type())
+	assert.Equal(t, []float32{0.25, 0.5, 1}, []float32{tfe.X(), tfe.Y(), tfe.Pressure()})
+	assert.Equal(t, int64(2), tfe.FingerID())
+	assert.Equal(t, uint32(3), tfe.WindowID())
+}
+
+func TestQueueGetAndFlushMany(t *testing.T) {
+	q := &Queue{}
+	require.NoError(t, q.Start())
+	defer q.Stop()
+
+	for _, evType := range []uint32{RenderTargetsReset, RenderDeviceReset, RenderTargetsReset, RenderDeviceReset, RenderTargetsReset} {
+		_, err := q.Push(NewRenderEvent(evType))
+		require.NoError(t, err)
+	}
+	events := make([]Event, 8)
+	n, err := q.Peep(events, Get, RenderTargetsReset, RenderTargetsReset)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	require.NoError(t, q.FlushType(RenderDeviceReset))
+	has, err := q.HasTypes(RenderTargetsReset, RenderDeviceReset)
+	require.NoError(t, err)
+	assert.False(t, has)
+}
+
+func TestQueueWatcherPushes(t *testing.T) {
+	q := &Queue{}
+	require.NoError(t, q.Start())
+	defer q.Stop()
+
+	q.AddWatch(&Watcher{Callback: func(userdata interface{}, ev Event) bool {
+		if ev.Type() == RenderDeviceReset {
+			q.Push(NewRenderEvent(RenderTargetsReset))
+		}
+		return true
+	}})
+	done := make(chan error)
+	go func() {
+		_, err := q.Push(NewRenderEvent(RenderDeviceReset))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("push from a watcher deadlocked")
+	}
+
+	// the watcher's event is queued first
+	events := make([]Event, 2)
+	n, err := q.Peep(events, Get, FirstEvent, LastEvent)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	assert.Equal(t, []uint32{RenderTargetsReset, RenderDeviceReset}, []uint32{events[0].Type(), events[1].Type()})
+}
@@ -0,0 +1,64 @@
+package event
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Touch finger event structure (event.tfinger.*), the position and motion
+// are normalized to the range 0-1.
+type TouchFinger Data
+
+func (tfe TouchFinger) TouchID() int64 {
+	return int64(binary.LittleEndian.Uint64(tfe[8:16]))
+}
+
+func (tfe TouchFinger) FingerID() int64 {
+	return int64(binary.LittleEndian.Uint64(tfe[16:24]))
+}
+
+func (tfe TouchFinger) X() float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(tfe[24:28]))
+}
+
+func (tfe TouchFinger) Y() float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(tfe[28:32]))
+}
+
+func (tfe TouchFinger) DX() float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(tfe[32:36]))
+}
+
+func (tfe TouchFinger) DY() float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(tfe[36:40]))
+}
+
+func (tfe TouchFinger) Pressure() float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(tfe[40:44]))
+}
+
+func (tfe TouchFinger) WindowID() uint32 {
+	return binary.LittleEndian.Uint32(tfe[44:48])
+}
+
+// SetPosition moves the event, for watchers that map it to other
+// coordinates.
+func (tfe *TouchFinger) SetPosition(x, y float32) {
+	binary.LittleEndian.PutUint32(tfe[24:28], math.Float32bits(x))
+	binary.LittleEndian.PutUint32(tfe[28:32], math.Float32bits(y))
+}
+
+// NewTouchFingerEvent creates a FingerDown, FingerUp or FingerMotion event.
+func NewTouchFingerEvent(evType uint32, touchID, fingerID int64, x, y, dx, dy, pressure float32, windowID uint32) Data {
+	tfe := Data{}
+	binary.LittleEndian.PutUint32(tfe[0:4], evType)
+	binary.LittleEndian.PutUint64(tfe[8:16], uint64(touchID))
+	binary.LittleEndian.PutUint64(tfe[16:24], uint64(fingerID))
+	binary.LittleEndian.PutUint32(tfe[24:28], math.Float32bits(x))
+	binary.LittleEndian.PutUint32(tfe[28:32], math.Float32bits(y))
+	binary.LittleEndian.PutUint32(tfe[32:36], math.Float32bits(dx))
+	binary.LittleEndian.PutUint32(tfe[36:40], math.Float32bits(dy))
+	binary.LittleEndian.PutUint32(tfe[40:44], math.Float32bits(pressure))
+	binary.LittleEndian.PutUint32(tfe[44:48], windowID)
+	return tfe
+}
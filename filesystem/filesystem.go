@@ -0,0 +1,95 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GetBasePath returns the directory the application was run from, with a
+// trailing path separator. Symbolic links to the executable are resolved.
+func GetBasePath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", errors.Wrap(err, "unable to find executable")
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to resolve executable path")
+	}
+	return withSeparator(filepath.Dir(exe)), nil
+}
+
+// GetPrefPath returns a writable per-user directory for the application's
+// data, such as save games, creating it if necessary. The organization may
+// be empty. The returned path has a trailing path separator.
+func GetPrefPath(org, app string) (string, error) {
+	return userPath(dataHome, org, app)
+}
+
+// GetConfigPath returns a writable per-user directory for the application's
+// settings, creating it if necessary.
+func GetConfigPath(org, app string) (string, error) {
+	return userPath(configHome, org, app)
+}
+
+// GetCachePath returns a writable per-user directory for data the
+// application can regenerate, creating it if necessary.
+func GetCachePath(org, app string) (string, error) {
+	return userPath(cacheHome, org, app)
+}
+
+func userPath(home func() (string, error), org, app string) (string, error) {
+	app = sanitize(app)
+	if app == "" {
+		return "", errors.New("invalid application name")
+	}
+	base, err := home()
+	if err != nil {
+		return "", err
+	}
+
+	path := base
+	if org = sanitize(org); org != "" {
+		path = filepath.Join(path, org)
+	}
+	path = filepath.Join(path, app)
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return "", errors.Wrapf(err, "unable to create directory (%s)", path)
+	}
+	return withSeparator(path), nil
+}
+
+// xdgHome returns the directory in the environment variable, or fallback
+// relative to the home directory when it is unset or not absolute.
+func xdgHome(env, fallback string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home := os.Getenv("HOME")
+	if home == "" {
+		return "", errors.Errorf("neither %s nor HOME is set", env)
+	}
+	return filepath.Join(home, fallback), nil
+}
+
+// sanitize makes a name safe to use as a single path element.
+func sanitize(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.TrimRight(strings.TrimSpace(name), ".")
+	return name
+}
+
+func withSeparator(path string) string {
+	if strings.HasSuffix(path, string(filepath.Separator)) {
+		return path
+	}
+	return path + string(filepath.Separator)
+}
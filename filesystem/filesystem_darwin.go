@@ -0,0 +1,15 @@
+// +build darwin
+
+package filesystem
+
+func dataHome() (string, error) {
+	return xdgHome("XDG_DATA_HOME", "Library/Application Support")
+}
+
+func configHome() (string, error) {
+	return xdgHome("XDG_CONFIG_HOME", "Library/Preferences")
+}
+
+func cacheHome() (string, error) {
+	return xdgHome("XDG_CACHE_HOME", "Library/Caches")
+}
@@ -0,0 +1,15 @@
+// +build !windows,!darwin
+
+package filesystem
+
+func dataHome() (string, error) {
+	return xdgHome("XDG_DATA_HOME", ".local/share")
+}
+
+func configHome() (string, error) {
+	return xdgHome("XDG_CONFIG_HOME", ".config")
+}
+
+func cacheHome() (string, error) {
+	return xdgHome("XDG_CACHE_HOME", ".cache")
+}
@@ -0,0 +1,83 @@
+// +build !windows,!darwin
+
+package filesystem
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setenv(t *testing.T, name, value string) func() {
+	prev, ok := os.LookupEnv(name)
+	require.NoError(t, os.Setenv(name, value))
+	return func() {
+		if ok {
+			os.Setenv(name, prev)
+		} else {
+			os.Unsetenv(name)
+		}
+	}
+}
+
+func TestGetBasePath(t *testing.T) {
+	path, err := GetBasePath()
+	require.NoError(t, err)
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	exe, err = filepath.EvalSymlinks(exe)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Dir(exe)+"/", path)
+}
+
+func TestGetPrefPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filesystem")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer setenv(t, "XDG_DATA_HOME", filepath.Join(dir, "data"))()
+
+	path, err := GetPrefPath("My Company", "Game: Part 2")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "data", "My Company", "Game_ Part 2")+"/", path)
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.True(t, stat.IsDir())
+	assert.Equal(t, os.FileMode(0700), stat.Mode().Perm())
+
+	path, err = GetPrefPath("", "game")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "data", "game")+"/", path)
+
+	path, err = GetPrefPath("..", "../../escape")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "data", ".._.._escape")+"/", path)
+
+	_, err = GetPrefPath("org", "..")
+	assert.Error(t, err)
+}
+
+func TestXDGFallback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filesystem")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer setenv(t, "HOME", dir)()
+	defer setenv(t, "XDG_DATA_HOME", "")()
+	defer setenv(t, "XDG_CONFIG_HOME", "relative/is/ignored")()
+	defer setenv(t, "XDG_CACHE_HOME", filepath.Join(dir, "cache"))()
+
+	path, err := GetPrefPath("org", "app")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".local/share/org/app")+"/", path)
+
+	path, err = GetConfigPath("org", "app")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".config/org/app")+"/", path)
+
+	path, err = GetCachePath("org", "app")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "cache/org/app")+"/", path)
+}
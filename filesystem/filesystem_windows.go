@@ -0,0 +1,29 @@
+// +build windows
+
+package filesystem
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+func knownFolder(env string) (string, error) {
+	dir := os.Getenv(env)
+	if dir == "" {
+		return "", errors.Errorf("%s is not set", env)
+	}
+	return dir, nil
+}
+
+func dataHome() (string, error) {
+	return knownFolder("APPDATA")
+}
+
+func configHome() (string, error) {
+	return knownFolder("APPDATA")
+}
+
+func cacheHome() (string, error) {
+	return knownFolder("LOCALAPPDATA")
+}
@@ -1,8 +1,12 @@
 package gdl
 
 import (
+	"sync"
+
 	"github.com/elliotmr/gdl/event"
+	"github.com/elliotmr/gdl/hint"
 	"github.com/elliotmr/gdl/ticker"
+	"github.com/elliotmr/gdl/video"
 	"github.com/pkg/errors"
 )
 
@@ -21,7 +25,69 @@ const InitEverything = InitTimer | InitAudio | InitVideo | InitJoystick | InitHa
 
 var EventLoop *event.Queue
 
-func Init(flags uint32) error {
+type subsystem struct {
+	flag uint32
+	init func() error
+	quit func()
+}
+
+// subsystems are listed in initialization order, they are shut down in reverse.
+var subsystems = []subsystem{
+	{flag: InitEvents, init: initEvents, quit: quitEvents},
+	{flag: InitTimer, init: initTimer},
+	{flag: InitVideo, init: initVideo, quit: video.VideoQuit},
+	{flag: InitAudio},
+	{flag: InitJoystick, init: helperWindowAcquire, quit: helperWindowRelease},
+	{flag: InitGameController},
+	{flag: InitHaptic, init: helperWindowAcquire, quit: helperWindowRelease},
+	{flag: InitNoParachute},
+}
+
+var (
+	mu         sync.Mutex
+	refCount   = make(map[uint32]int)
+	helperRefs int
+)
+
+func initEvents() error {
+	EventLoop = event.Q
+	return EventLoop.Start()
+}
+
+func quitEvents() {
+	EventLoop.Stop()
+	EventLoop = nil
+}
+
+func initTimer() error {
+	ticker.Initialize()
+	return nil
+}
+
+func initVideo() error {
+	return video.VideoInit("")
+}
+
+// the helper window is shared between the joystick and haptic subsystems.
+func helperWindowAcquire() error {
+	if helperRefs == 0 {
+		if err := helperWindowCreate(); err != nil {
+			return err
+		}
+	}
+	helperRefs++
+	return nil
+}
+
+func helperWindowRelease() {
+	helperRefs--
+	if helperRefs == 0 {
+		helperWindowDestroy()
+	}
+}
+
+// impliedFlags adds the subsystems that the requested subsystems depend on.
+func impliedFlags(flags uint32) uint32 {
 	if flags&InitGameController > 0 {
 		// game controller implies joystick
 		flags |= InitJoystick
@@ -31,17 +97,91 @@ func Init(flags uint32) error {
 		// video or joystick implies event
 		flags |= InitEvents
 	}
+	return flags
+}
 
-	if flags&(InitHaptic|InitJoystick) > 0 {
-		if err := helperWindowCreate(); err != nil {
-			return errors.Wrap(err, "failed initializing joystick")
+// Init initializes the requested subsystems, it is the same as InitSubSystem.
+func Init(flags uint32) error {
+	return InitSubSystem(flags)
+}
+
+// InitSubSystem initializes the requested subsystems along with the
+// subsystems they depend on. Every subsystem is reference counted, so each
+// successful call must be matched with a call to QuitSubSystem.
+func InitSubSystem(flags uint32) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	flags = impliedFlags(flags)
+	var initialized uint32
+	for _, s := range subsystems {
+		if flags&s.flag == 0 {
+			continue
+		}
+		if refCount[s.flag] == 0 && s.init != nil {
+			if err := s.init(); err != nil {
+				quitSubSystem(initialized)
+				return errors.Wrapf(err, "failed initializing subsystem 0x%x", s.flag)
+			}
 		}
+		refCount[s.flag]++
+		initialized |= s.flag
 	}
+	return nil
+}
 
-	ticker.Initialize()
+// QuitSubSystem releases one reference to each of the requested subsystems
+// and the subsystems they imply, shutting down any that are no longer used.
+func QuitSubSystem(flags uint32) {
+	mu.Lock()
+	defer mu.Unlock()
+	quitSubSystem(impliedFlags(flags))
+}
+
+func quitSubSystem(flags uint32) {
+	for i := len(subsystems) - 1; i >= 0; i-- {
+		s := subsystems[i]
+		if flags&s.flag == 0 || refCount[s.flag] == 0 {
+			continue
+		}
+		if refCount[s.flag] == 1 && s.quit != nil {
+			s.quit()
+		}
+		refCount[s.flag]--
+	}
+}
+
+// WasInit returns the subset of flags that are currently initialized. If
+// flags is 0 all initialized subsystems are returned.
+func WasInit(flags uint32) uint32 {
+	mu.Lock()
+	defer mu.Unlock()
 
-	if flags&InitEvents > 0 {
-		EventLoop.Start()
+	if flags == 0 {
+		flags = InitEverything
+	}
+	var initialized uint32
+	for _, s := range subsystems {
+		if flags&s.flag > 0 && refCount[s.flag] > 0 {
+			initialized |= s.flag
+		}
 	}
+	return initialized
+}
+
+// Quit shuts down every subsystem regardless of its reference count and
+// clears all hints.
+func Quit() {
+	defer hint.ClearHints()
 
+	mu.Lock()
+	defer mu.Unlock()
+
+	for i := len(subsystems) - 1; i >= 0; i-- {
+		s := subsystems[i]
+		if refCount[s.flag] > 0 && s.quit != nil {
+			s.quit()
+		}
+		refCount[s.flag] = 0
+	}
 }
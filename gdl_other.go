@@ -5,3 +5,5 @@ package gdl
 func helperWindowCreate() error {
 	return nil
 }
+
+func helperWindowDestroy() {}
@@ -0,0 +1,65 @@
+package gdl
+
+import (
+	"testing"
+
+	"github.com/elliotmr/gdl/hint"
+	"github.com/elliotmr/gdl/video"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitImpliesDependencies(t *testing.T) {
+	defer Quit()
+
+	require.NoError(t, Init(InitGameController))
+	assert.Equal(t, uint32(InitGameController|InitJoystick|InitEvents), WasInit(0))
+	assert.NotNil(t, EventLoop)
+
+	QuitSubSystem(InitGameController)
+	assert.Equal(t, uint32(0), WasInit(0))
+	assert.Nil(t, EventLoop)
+}
+
+func TestSubSystemRefCount(t *testing.T) {
+	defer Quit()
+
+	require.NoError(t, Init(InitVideo))
+	require.NoError(t, InitSubSystem(InitEvents))
+	assert.Equal(t, uint32(InitEvents), WasInit(InitEvents|InitAudio))
+
+	QuitSubSystem(InitVideo)
+	assert.Equal(t, uint32(InitEvents), WasInit(0))
+
+	QuitSubSystem(InitEvents)
+	assert.Equal(t, uint32(0), WasInit(0))
+
+	// unbalanced quits are ignored
+	QuitSubSystem(InitEvents)
+	assert.Equal(t, uint32(0), WasInit(0))
+}
+
+func TestInitVideo(t *testing.T) {
+	defer Quit()
+
+	require.NoError(t, Init(InitVideo))
+	assert.NotEmpty(t, video.GetCurrentVideoDriver())
+	QuitSubSystem(InitVideo)
+	assert.Empty(t, video.GetCurrentVideoDriver())
+
+	// hints set before Init are used by the video driver
+	hint.SetHint(hint.VideoDriver, "missing")
+	assert.Error(t, Init(InitVideo))
+	assert.Equal(t, uint32(0), WasInit(0))
+}
+
+func TestInitQuitRepeatedly(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		require.NoError(t, Init(InitEverything))
+		require.NoError(t, Init(InitVideo))
+		assert.Equal(t, uint32(InitEverything), WasInit(InitEverything))
+		Quit()
+		assert.Equal(t, uint32(0), WasInit(0))
+		assert.Nil(t, EventLoop)
+	}
+}
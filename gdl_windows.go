@@ -58,3 +58,15 @@ func helperWindowCreate() error {
 	}
 	return nil
 }
+
+func helperWindowDestroy() {
+	if helperWindow == NULL {
+		return
+	}
+	w32.DestroyWindow(helperWindow)
+	helperWindow = NULL
+	w32.UnregisterClass(
+		windows.StringToUTF16Ptr(helperWindowClassName),
+		w32.GetModuleHandle(""),
+	)
+}
@@ -0,0 +1,153 @@
+package hint
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// Hint names, each can also be set with an environment variable of the same name.
+const (
+	VideoDriver            = "GDL_VIDEODRIVER"
+	VideoHighDPIDisabled   = "GDL_VIDEO_HIGHDPI_DISABLED"
+	VideoOffscreenDisplays = "GDL_VIDEO_OFFSCREEN_DISPLAYS"
+	RenderDriver           = "GDL_RENDER_DRIVER"
+	RenderScaleQuality     = "GDL_RENDER_SCALE_QUALITY"
+	RenderVSync            = "GDL_RENDER_VSYNC"
+	RenderLogicalSizeMode  = "GDL_RENDER_LOGICAL_SIZE_MODE"
+)
+
+// Priority decides whether a hint may replace a previously set value.
+type Priority int
+
+const (
+	Default  Priority = iota // low priority, used for default values
+	Normal                   // medium priority
+	Override                 // high priority, overrides environment variables
+)
+
+// Callback is called with the old and new value whenever a hint changes.
+type Callback func(userdata interface{}, name, oldValue, newValue string)
+
+type Watcher struct {
+	Callback Callback
+	Userdata interface{}
+}
+
+type entry struct {
+	value    string
+	priority Priority
+	watchers []*Watcher
+}
+
+var (
+	mu    sync.Mutex
+	hints = make(map[string]*entry)
+)
+
+// SetHint sets a hint with normal priority.
+func SetHint(name, value string) bool {
+	return SetHintWithPriority(name, value, Normal)
+}
+
+// SetHintWithPriority sets a hint unless it has already been set with a
+// higher priority, or an environment variable is set and the priority is
+// below Override. It returns whether the hint was set.
+func SetHintWithPriority(name, value string, priority Priority) bool {
+	if os.Getenv(name) != "" && priority < Override {
+		return false
+	}
+
+	mu.Lock()
+	h, exists := hints[name]
+	if !exists {
+		hints[name] = &entry{value: value, priority: priority}
+		mu.Unlock()
+		return true
+	}
+	if priority < h.priority {
+		mu.Unlock()
+		return false
+	}
+	oldValue := h.value
+	h.value = value
+	h.priority = priority
+	watchers := append([]*Watcher(nil), h.watchers...)
+	mu.Unlock()
+
+	if oldValue != value {
+		for _, w := range watchers {
+			w.Callback(w.Userdata, name, oldValue, value)
+		}
+	}
+	return true
+}
+
+// GetHint returns the current value of a hint, or an empty string if it is not set.
+func GetHint(name string) string {
+	env := os.Getenv(name)
+
+	mu.Lock()
+	defer mu.Unlock()
+	h, exists := hints[name]
+	if exists && (env == "" || h.priority == Override) {
+		return h.value
+	}
+	return env
+}
+
+// GetHintBoolean returns the boolean value of a hint, "0" and "false" are
+// false and any other value is true. If the hint is not set defaultValue
+// is returned.
+func GetHintBoolean(name string, defaultValue bool) bool {
+	return valueBoolean(GetHint(name), defaultValue)
+}
+
+func valueBoolean(value string, defaultValue bool) bool {
+	if value == "" {
+		return defaultValue
+	}
+	return !(value == "0" || strings.EqualFold(value, "false"))
+}
+
+// AddHintCallback registers a watcher for a hint. The callback is called
+// immediately with the current value and again every time it changes.
+func AddHintCallback(name string, watcher *Watcher) {
+	DelHintCallback(name, watcher)
+
+	mu.Lock()
+	h, exists := hints[name]
+	if !exists {
+		h = &entry{priority: Default}
+		hints[name] = h
+	}
+	h.watchers = append(h.watchers, watcher)
+	mu.Unlock()
+
+	value := GetHint(name)
+	watcher.Callback(watcher.Userdata, name, value, value)
+}
+
+// DelHintCallback removes a watcher previously added with AddHintCallback.
+func DelHintCallback(name string, watcher *Watcher) {
+	mu.Lock()
+	defer mu.Unlock()
+	h, exists := hints[name]
+	if !exists {
+		return
+	}
+	updatedWatchers := h.watchers[:0]
+	for _, w := range h.watchers {
+		if w != watcher {
+			updatedWatchers = append(updatedWatchers, w)
+		}
+	}
+	h.watchers = updatedWatchers
+}
+
+// ClearHints removes all hints and their watchers.
+func ClearHints() {
+	mu.Lock()
+	defer mu.Unlock()
+	hints = make(map[string]*entry)
+}
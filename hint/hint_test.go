@@ -0,0 +1,69 @@
+package hint
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHintPriority(t *testing.T) {
+	defer ClearHints()
+
+	assert.Equal(t, "", GetHint(RenderDriver))
+	assert.True(t, SetHintWithPriority(RenderDriver, "software", Default))
+	assert.Equal(t, "software", GetHint(RenderDriver))
+	assert.True(t, SetHint(RenderDriver, "opengl"))
+	assert.False(t, SetHintWithPriority(RenderDriver, "direct3d", Default))
+	assert.Equal(t, "opengl", GetHint(RenderDriver))
+	assert.True(t, SetHintWithPriority(RenderDriver, "direct3d", Override))
+	assert.False(t, SetHint(RenderDriver, "opengl"))
+	assert.Equal(t, "direct3d", GetHint(RenderDriver))
+}
+
+func TestHintEnvironment(t *testing.T) {
+	defer ClearHints()
+	os.Setenv(VideoDriver, "offscreen")
+	defer os.Unsetenv(VideoDriver)
+
+	assert.Equal(t, "offscreen", GetHint(VideoDriver))
+	assert.False(t, SetHint(VideoDriver, "windows"))
+	assert.Equal(t, "offscreen", GetHint(VideoDriver))
+	assert.True(t, SetHintWithPriority(VideoDriver, "windows", Override))
+	assert.Equal(t, "windows", GetHint(VideoDriver))
+}
+
+func TestGetHintBoolean(t *testing.T) {
+	defer ClearHints()
+
+	assert.True(t, GetHintBoolean(RenderVSync, true))
+	assert.False(t, GetHintBoolean(RenderVSync, false))
+	for value, expected := range map[string]bool{"0": false, "false": false, "FALSE": false, "1": true, "yes": true} {
+		SetHint(RenderVSync, value)
+		assert.Equal(t, expected, GetHintBoolean(RenderVSync, !expected), value)
+	}
+}
+
+func TestHintCallback(t *testing.T) {
+	defer ClearHints()
+
+	var calls [][3]string
+	w := &Watcher{
+		Callback: func(userdata interface{}, name, oldValue, newValue string) {
+			assert.Equal(t, "data", userdata)
+			calls = append(calls, [3]string{name, oldValue, newValue})
+		},
+		Userdata: "data",
+	}
+	SetHint(RenderScaleQuality, "nearest")
+	AddHintCallback(RenderScaleQuality, w)
+	SetHint(RenderScaleQuality, "linear")
+	SetHint(RenderScaleQuality, "linear")
+	DelHintCallback(RenderScaleQuality, w)
+	SetHint(RenderScaleQuality, "best")
+
+	assert.Equal(t, [][3]string{
+		{RenderScaleQuality, "nearest", "nearest"},
+		{RenderScaleQuality, "nearest", "linear"},
+	}, calls)
+}
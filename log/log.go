@@ -0,0 +1,208 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Log categories, applications can define their own starting at CategoryCustom.
+const (
+	CategoryApplication = iota
+	CategoryError
+	CategoryAssert
+	CategorySystem
+	CategoryAudio
+	CategoryVideo
+	CategoryRender
+	CategoryInput
+	CategoryTest
+	CategoryWayland
+
+	CategoryCustom = 32
+)
+
+var categoryNames = map[int]string{
+	CategoryApplication: "application",
+	CategoryError:       "error",
+	CategoryAssert:      "assert",
+	CategorySystem:      "system",
+	CategoryAudio:       "audio",
+	CategoryVideo:       "video",
+	CategoryRender:      "render",
+	CategoryInput:       "input",
+	CategoryTest:        "test",
+	CategoryWayland:     "wayland",
+}
+
+// CategoryName returns a printable name for a category.
+func CategoryName(category int) string {
+	if name, ok := categoryNames[category]; ok {
+		return name
+	}
+	return fmt.Sprintf("custom%d", category-CategoryCustom)
+}
+
+type Priority int
+
+const (
+	PriorityVerbose Priority = 1 + iota
+	PriorityDebug
+	PriorityInfo
+	PriorityWarn
+	PriorityError
+	PriorityCritical
+)
+
+var priorityPrefixes = map[Priority]string{
+	PriorityVerbose:  "VERBOSE",
+	PriorityDebug:    "DEBUG",
+	PriorityInfo:     "INFO",
+	PriorityWarn:     "WARN",
+	PriorityError:    "ERROR",
+	PriorityCritical: "CRITICAL",
+}
+
+func (p Priority) String() string {
+	if prefix, ok := priorityPrefixes[p]; ok {
+		return prefix
+	}
+	return fmt.Sprintf("PRIORITY(%d)", int(p))
+}
+
+// OutputFunc receives every message that passes the priority filter.
+type OutputFunc func(userdata interface{}, category int, priority Priority, message string)
+
+const (
+	defaultPriority            = PriorityCritical
+	defaultAssertPriority      = PriorityWarn
+	defaultApplicationPriority = PriorityInfo
+	defaultTestPriority        = PriorityVerbose
+)
+
+var (
+	mu          sync.RWMutex
+	priorities  = make(map[int]Priority)
+	allPriority Priority // when non zero it applies to categories without their own priority
+
+	output     OutputFunc = defaultOutput
+	outputData interface{}
+)
+
+func defaultOutput(userdata interface{}, category int, priority Priority, message string) {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", priority, message)
+}
+
+// SetAllPriority sets the priority of every category.
+func SetAllPriority(priority Priority) {
+	mu.Lock()
+	defer mu.Unlock()
+	priorities = make(map[int]Priority)
+	allPriority = priority
+}
+
+// SetPriority sets the minimum priority that is output for a category.
+func SetPriority(category int, priority Priority) {
+	mu.Lock()
+	defer mu.Unlock()
+	priorities[category] = priority
+}
+
+// GetPriority returns the minimum priority that is output for a category.
+func GetPriority(category int) Priority {
+	mu.RLock()
+	defer mu.RUnlock()
+	return getPriority(category)
+}
+
+func getPriority(category int) Priority {
+	if p, ok := priorities[category]; ok {
+		return p
+	}
+	if allPriority != 0 {
+		return allPriority
+	}
+	switch category {
+	case CategoryApplication:
+		return defaultApplicationPriority
+	case CategoryAssert:
+		return defaultAssertPriority
+	case CategoryTest:
+		return defaultTestPriority
+	}
+	return defaultPriority
+}
+
+// ResetPriorities restores the default priority of every category.
+func ResetPriorities() {
+	mu.Lock()
+	defer mu.Unlock()
+	priorities = make(map[int]Priority)
+	allPriority = 0
+}
+
+// SetOutputFunction replaces the function that messages are written to, a
+// nil function restores the default output to stderr.
+func SetOutputFunction(f OutputFunc, userdata interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	if f == nil {
+		f = defaultOutput
+	}
+	output = f
+	outputData = userdata
+}
+
+// GetOutputFunction returns the current output function and its userdata.
+func GetOutputFunction() (OutputFunc, interface{}) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return output, outputData
+}
+
+// Enabled returns whether a message with the given category and priority
+// would be output. It can be used to skip building expensive messages.
+func Enabled(category int, priority Priority) bool {
+	return priority >= GetPriority(category)
+}
+
+// Log logs a message with CategoryApplication and PriorityInfo.
+func Log(format string, args ...interface{}) {
+	Message(CategoryApplication, PriorityInfo, format, args...)
+}
+
+func Verbose(category int, format string, args ...interface{}) {
+	Message(category, PriorityVerbose, format, args...)
+}
+
+func Debug(category int, format string, args ...interface{}) {
+	Message(category, PriorityDebug, format, args...)
+}
+
+func Info(category int, format string, args ...interface{}) {
+	Message(category, PriorityInfo, format, args...)
+}
+
+func Warn(category int, format string, args ...interface{}) {
+	Message(category, PriorityWarn, format, args...)
+}
+
+func Error(category int, format string, args ...interface{}) {
+	Message(category, PriorityError, format, args...)
+}
+
+func Critical(category int, format string, args ...interface{}) {
+	Message(category, PriorityCritical, format, args...)
+}
+
+// Message logs a message with the given category and priority.
+func Message(category int, priority Priority, format string, args ...interface{}) {
+	mu.RLock()
+	enabled := priority >= getPriority(category)
+	f, userdata := output, outputData
+	mu.RUnlock()
+	if !enabled {
+		return
+	}
+	f(userdata, category, priority, fmt.Sprintf(format, args...))
+}
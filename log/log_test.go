@@ -0,0 +1,89 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type record struct {
+	category int
+	priority Priority
+	message  string
+}
+
+func capture(t *testing.T) *[]record {
+	var records []record
+	SetOutputFunction(func(userdata interface{}, category int, priority Priority, message string) {
+		assert.Equal(t, "userdata", userdata)
+		records = append(records, record{category, priority, message})
+	}, "userdata")
+	return &records
+}
+
+func TestDefaultPriorities(t *testing.T) {
+	defer SetOutputFunction(nil, nil)
+	records := capture(t)
+
+	Log("hello %s", "world")
+	Debug(CategoryApplication, "hidden")
+	Warn(CategoryVideo, "hidden")
+	Critical(CategoryVideo, "shown")
+	Warn(CategoryAssert, "assert")
+	Verbose(CategoryTest, "test")
+
+	assert.Equal(t, []record{
+		{CategoryApplication, PriorityInfo, "hello world"},
+		{CategoryVideo, PriorityCritical, "shown"},
+		{CategoryAssert, PriorityWarn, "assert"},
+		{CategoryTest, PriorityVerbose, "test"},
+	}, *records)
+}
+
+func TestSetPriority(t *testing.T) {
+	defer SetOutputFunction(nil, nil)
+	defer ResetPriorities()
+	records := capture(t)
+
+	SetAllPriority(PriorityWarn)
+	SetPriority(CategoryWayland, PriorityVerbose)
+	assert.True(t, Enabled(CategoryWayland, PriorityVerbose))
+	assert.False(t, Enabled(CategoryApplication, PriorityInfo))
+
+	Verbose(CategoryWayland, "wayland")
+	Info(CategoryVideo, "hidden")
+	Error(CategoryVideo, "video")
+	Message(CategoryCustom+1, PriorityWarn, "custom")
+	assert.Equal(t, []record{
+		{CategoryWayland, PriorityVerbose, "wayland"},
+		{CategoryVideo, PriorityError, "video"},
+		{CategoryCustom + 1, PriorityWarn, "custom"},
+	}, *records)
+
+	ResetPriorities()
+	assert.Equal(t, PriorityInfo, GetPriority(CategoryApplication))
+	assert.Equal(t, PriorityCritical, GetPriority(CategoryWayland))
+}
+
+func TestSlogOutput(t *testing.T) {
+	defer SetOutputFunction(nil, nil)
+	defer ResetPriorities()
+
+	buf := &bytes.Buffer{}
+	h := slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: LevelVerbose,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	SetOutputFunction(SlogOutput(slog.New(h)), nil)
+	SetPriority(CategoryVideo, PriorityDebug)
+
+	Debug(CategoryVideo, "window %d shown", 3)
+	assert.Equal(t, "level=DEBUG msg=\"window 3 shown\" category=video\n", buf.String())
+}
@@ -0,0 +1,34 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LevelVerbose and LevelCritical extend the slog levels for the priorities
+// that have no slog equivalent.
+const (
+	LevelVerbose  = slog.LevelDebug - 4
+	LevelCritical = slog.LevelError + 4
+)
+
+var slogLevels = map[Priority]slog.Level{
+	PriorityVerbose:  LevelVerbose,
+	PriorityDebug:    slog.LevelDebug,
+	PriorityInfo:     slog.LevelInfo,
+	PriorityWarn:     slog.LevelWarn,
+	PriorityError:    slog.LevelError,
+	PriorityCritical: LevelCritical,
+}
+
+// SlogOutput returns an OutputFunc that forwards messages to logger, the
+// category is added as the "category" attribute.
+func SlogOutput(logger *slog.Logger) OutputFunc {
+	return func(userdata interface{}, category int, priority Priority, message string) {
+		level, ok := slogLevels[priority]
+		if !ok {
+			level = slog.LevelInfo
+		}
+		logger.LogAttrs(context.Background(), level, message, slog.String("category", CategoryName(category)))
+	}
+}
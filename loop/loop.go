@@ -0,0 +1,169 @@
+package loop
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/elliotmr/gdl/event"
+	"github.com/elliotmr/gdl/ticker"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultUpdateRate = time.Second / 60
+	DefaultMaxUpdates = 5
+)
+
+// Game is driven by a Runner. Update is called with a fixed step, Render is
+// called once per frame with alpha in [0, 1) describing how far the current
+// time is between the last and the next update.
+type Game interface {
+	Update(step time.Duration) error
+	Render(alpha float64) error
+}
+
+// EventHandler can be implemented by a Game to receive every polled event,
+// including the Quit event that ends the loop.
+type EventHandler interface {
+	HandleEvent(ev event.Event)
+}
+
+// Stats are the timing statistics collected by a Runner.
+type Stats struct {
+	Frames         uint64
+	Updates        uint64
+	SkippedUpdates uint64 // updates dropped because the catch-up limit was hit
+
+	FrameTime    time.Duration // duration of the last frame
+	MinFrameTime time.Duration
+	MaxFrameTime time.Duration
+	TotalTime    time.Duration
+}
+
+// AvgFrameTime returns the mean frame duration.
+func (s Stats) AvgFrameTime() time.Duration {
+	if s.Frames == 0 {
+		return 0
+	}
+	return s.TotalTime / time.Duration(s.Frames)
+}
+
+// FPS returns the average number of frames per second.
+func (s Stats) FPS() float64 {
+	if s.TotalTime == 0 {
+		return 0
+	}
+	return float64(s.Frames) / s.TotalTime.Seconds()
+}
+
+func (s *Stats) record(frame time.Duration) {
+	if s.Frames == 0 || frame < s.MinFrameTime {
+		s.MinFrameTime = frame
+	}
+	if frame > s.MaxFrameTime {
+		s.MaxFrameTime = frame
+	}
+	s.Frames++
+	s.FrameTime = frame
+	s.TotalTime += frame
+}
+
+// Runner pumps the event queue and runs a Game with a fixed simulation rate.
+// The zero value uses event.Q, DefaultUpdateRate, DefaultMaxUpdates and no
+// frame cap.
+type Runner struct {
+	Queue      *event.Queue
+	UpdateRate time.Duration // fixed simulation step
+	MaxUpdates int           // maximum updates per frame before time is dropped
+	FrameCap   time.Duration // minimum frame duration, 0 for uncapped
+
+	Stats Stats
+
+	stopped int32
+}
+
+// Stop asks the loop to return after the current frame. A Stop before Run
+// makes the next Run return without running a frame.
+func (r *Runner) Stop() {
+	atomic.StoreInt32(&r.stopped, 1)
+}
+
+// Run drives g until a Quit event is received, Stop is called, or one of the
+// game callbacks returns an error.
+func (r *Runner) Run(g Game) error {
+	q := r.Queue
+	if q == nil {
+		q = event.Q
+	}
+	step := r.UpdateRate
+	if step <= 0 {
+		step = DefaultUpdateRate
+	}
+	maxUpdates := r.MaxUpdates
+	if maxUpdates <= 0 {
+		maxUpdates = DefaultMaxUpdates
+	}
+	handler, _ := g.(EventHandler)
+
+	defer atomic.StoreInt32(&r.stopped, 0)
+	var acc time.Duration
+	prev := ticker.Get()
+	for atomic.LoadInt32(&r.stopped) == 0 {
+		frameStart := ticker.Get()
+		acc += frameStart - prev
+		prev = frameStart
+
+		quit, err := r.pump(q, handler)
+		if err != nil {
+			return err
+		}
+		if quit {
+			return nil
+		}
+
+		updates := 0
+		for acc >= step && updates < maxUpdates {
+			if err := g.Update(step); err != nil {
+				return errors.Wrap(err, "update failed")
+			}
+			acc -= step
+			updates++
+		}
+		r.Stats.Updates += uint64(updates)
+		if acc >= step {
+			r.Stats.SkippedUpdates += uint64(acc / step)
+			acc %= step
+		}
+
+		if err := g.Render(float64(acc) / float64(step)); err != nil {
+			return errors.Wrap(err, "render failed")
+		}
+
+		if r.FrameCap > 0 {
+			if spent := ticker.Get() - frameStart; spent < r.FrameCap {
+				ticker.Delay(r.FrameCap - spent)
+			}
+		}
+		r.Stats.record(ticker.Get() - frameStart)
+	}
+	return nil
+}
+
+// pump drains the event queue, reporting whether a Quit event was seen.
+func (r *Runner) pump(q *event.Queue, handler EventHandler) (bool, error) {
+	for {
+		ev, err := q.Poll()
+		switch {
+		case err == event.WaitTimeoutExceeded:
+			return false, nil
+		case err != nil:
+			return false, errors.Wrap(err, "unable to poll events")
+		}
+		if handler != nil {
+			handler.HandleEvent(ev)
+		}
+		if ev.Type() == event.Quit {
+			return true, nil
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package loop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elliotmr/gdl/event"
+	"github.com/elliotmr/gdl/ticker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testGame struct {
+	q         *event.Queue
+	clock     *ticker.FakeClock
+	frameTime time.Duration
+	quitAfter int
+
+	updates int
+	frames  int
+	alphas  []float64
+	events  []uint32
+}
+
+func (g *testGame) HandleEvent(ev event.Event) {
+	g.events = append(g.events, ev.Type())
+}
+
+func (g *testGame) Update(step time.Duration) error {
+	g.updates++
+	return nil
+}
+
+func (g *testGame) Render(alpha float64) error {
+	g.frames++
+	g.alphas = append(g.alphas, alpha)
+	g.clock.Advance(g.frameTime)
+	if g.frames == g.quitAfter {
+		g.q.Push(event.NewQuitEvent())
+	}
+	return nil
+}
+
+func newTestRunner(t *testing.T) (*Runner, *ticker.FakeClock, func()) {
+	fc := ticker.NewFakeClock(time.Unix(0, 0))
+	prev := ticker.SetClock(fc)
+	q := &event.Queue{}
+	require.NoError(t, q.Start())
+	return &Runner{Queue: q, UpdateRate: 10 * time.Millisecond}, fc, func() {
+		q.Stop()
+		ticker.SetClock(prev)
+	}
+}
+
+func TestRunnerFixedStep(t *testing.T) {
+	r, fc, done := newTestRunner(t)
+	defer done()
+
+	g := &testGame{q: r.Queue, clock: fc, frameTime: 25 * time.Millisecond, quitAfter: 4}
+	require.NoError(t, r.Run(g))
+
+	// the quit event is seen at the start of the fifth frame, 100ms in
+	assert.Equal(t, 4, g.frames)
+	assert.Equal(t, 7, g.updates)
+	assert.Equal(t, []uint32{event.Quit}, g.events)
+	assert.InDeltaSlice(t, []float64{0, 0.5, 0, 0.5}, g.alphas, 1e-9)
+	assert.Equal(t, uint64(4), r.Stats.Frames)
+	assert.Equal(t, uint64(7), r.Stats.Updates)
+	assert.Equal(t, 25*time.Millisecond, r.Stats.AvgFrameTime())
+	assert.InDelta(t, 40, r.Stats.FPS(), 1e-9)
+}
+
+func TestRunnerCatchUpLimit(t *testing.T) {
+	r, fc, done := newTestRunner(t)
+	defer done()
+	r.MaxUpdates = 3
+
+	g := &testGame{q: r.Queue, clock: fc, frameTime: 95 * time.Millisecond, quitAfter: 2}
+	require.NoError(t, r.Run(g))
+
+	assert.Equal(t, 3, g.updates)
+	assert.Equal(t, uint64(6), r.Stats.SkippedUpdates)
+	assert.InDelta(t, 0.5, g.alphas[1], 1e-9)
+}
+
+func TestRunnerFrameCap(t *testing.T) {
+	r, fc, done := newTestRunner(t)
+	defer done()
+	r.FrameCap = 20 * time.Millisecond
+
+	g := &testGame{q: r.Queue, clock: fc, frameTime: 5 * time.Millisecond, quitAfter: 3}
+	require.NoError(t, r.Run(g))
+
+	assert.Equal(t, 20*time.Millisecond, r.Stats.MinFrameTime)
+	assert.Equal(t, 20*time.Millisecond, r.Stats.MaxFrameTime)
+	assert.Equal(t, 4, g.updates)
+}
+
+func TestRunnerStopBeforeRun(t *testing.T) {
+	r, fc, done := newTestRunner(t)
+	defer done()
+
+	g := &testGame{q: r.Queue, clock: fc, frameTime: 5 * time.Millisecond, quitAfter: 2}
+	r.Stop()
+	require.NoError(t, r.Run(g))
+	assert.Equal(t, 0, g.frames)
+
+	// the stop is used up, the next run goes until the quit event
+	require.NoError(t, r.Run(g))
+	assert.Equal(t, 2, g.frames)
+}
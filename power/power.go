@@ -0,0 +1,46 @@
+package power
+
+// State is the power state of the system.
+type State int
+
+const (
+	StateUnknown   State = iota // cannot determine power status
+	StateOnBattery              // not plugged in, running on the battery
+	StateNoBattery              // plugged in, no battery available
+	StateCharging               // plugged in, charging battery
+	StateCharged                // plugged in, battery charged
+)
+
+var stateNames = [...]string{
+	StateUnknown:   "unknown",
+	StateOnBattery: "on battery",
+	StateNoBattery: "no battery",
+	StateCharging:  "charging",
+	StateCharged:   "charged",
+}
+
+func (s State) String() string {
+	if s < 0 || int(s) >= len(stateNames) {
+		return stateNames[StateUnknown]
+	}
+	return stateNames[s]
+}
+
+// Info is a snapshot of the power status, Seconds and Percent are -1 when
+// they cannot be determined.
+type Info struct {
+	State   State
+	Seconds int
+	Percent int
+}
+
+// GetPowerInfo returns the current power state, the seconds of battery life
+// left and the percentage of battery life left. Seconds and percent are -1
+// when they cannot be determined.
+func GetPowerInfo() (State, int, int) {
+	info, err := Load("/")
+	if err != nil {
+		return StateUnknown, -1, -1
+	}
+	return info.State, info.Seconds, info.Percent
+}
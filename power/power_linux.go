@@ -0,0 +1,119 @@
+// +build linux
+
+package power
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Load reads the power status from the power_supply class in the sys file
+// system mounted below root, root is "/" for the running system. When
+// there are several batteries the one reporting the most time left is used.
+func Load(root string) (*Info, error) {
+	base := filepath.Join(root, "sys/class/power_supply")
+	entries, err := ioutil.ReadDir(base)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read power supplies")
+	}
+
+	// assume no battery unless one is found
+	info := &Info{State: StateNoBattery, Seconds: -1, Percent: -1}
+	for _, entry := range entries {
+		dir := filepath.Join(base, entry.Name())
+		if supplyType, ok := readString(dir, "type"); !ok || supplyType != "Battery" {
+			continue
+		}
+		// batteries of devices like a mouse or headset don't power the system
+		if scope, _ := readString(dir, "scope"); scope == "Device" {
+			continue
+		}
+		battery := readBattery(dir)
+
+		// pick the battery with the most time left, failing that the highest percentage
+		choose := false
+		if battery.Seconds < 0 && info.Seconds < 0 {
+			choose = (battery.Percent < 0 && info.Percent < 0) || battery.Percent > info.Percent
+		} else {
+			choose = battery.Seconds > info.Seconds
+		}
+		if choose {
+			*info = battery
+		}
+	}
+	return info, nil
+}
+
+func readBattery(dir string) Info {
+	battery := Info{State: StateUnknown, Seconds: -1, Percent: -1}
+
+	// some drivers don't report present, so assume it is unless told otherwise
+	if present, ok := readString(dir, "present"); ok && present == "0" {
+		battery.State = StateNoBattery
+	} else if status, ok := readString(dir, "status"); ok {
+		switch status {
+		case "Charging":
+			battery.State = StateCharging
+		case "Discharging":
+			battery.State = StateOnBattery
+		case "Full", "Not charging":
+			battery.State = StateCharged
+		}
+	}
+
+	if capacity, ok := readInt(dir, "capacity"); ok {
+		switch {
+		case capacity < 0:
+			capacity = 0
+		case capacity > 100:
+			capacity = 100
+		}
+		battery.Percent = capacity
+	}
+
+	if seconds, ok := readInt(dir, "time_to_empty_now"); ok && seconds > 0 {
+		battery.Seconds = seconds
+	} else if battery.State == StateOnBattery {
+		battery.Seconds = estimateSeconds(dir)
+	}
+	return battery
+}
+
+// estimateSeconds calculates the time left from the remaining energy (µWh)
+// and power draw (µW), or the remaining charge (µAh) and current (µA).
+func estimateSeconds(dir string) int {
+	pairs := [][2]string{{"energy_now", "power_now"}, {"charge_now", "current_now"}}
+	for _, pair := range pairs {
+		remaining, ok := readInt(dir, pair[0])
+		if !ok {
+			continue
+		}
+		rate, ok := readInt(dir, pair[1])
+		if !ok || rate <= 0 {
+			continue
+		}
+		return int(int64(remaining) * 3600 / int64(rate))
+	}
+	return -1
+}
+
+func readString(dir, name string) (string, bool) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
+
+func readInt(dir, name string) (int, bool) {
+	s, ok := readString(dir, name)
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.Atoi(s)
+	return i, err == nil
+}
@@ -0,0 +1,127 @@
+// +build linux
+
+package power
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTree(t *testing.T, supplies map[string]map[string]string) string {
+	root, err := ioutil.TempDir("", "power")
+	require.NoError(t, err)
+	base := filepath.Join(root, "sys/class/power_supply")
+	require.NoError(t, os.MkdirAll(base, 0755))
+	for supply, files := range supplies {
+		dir := filepath.Join(base, supply)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		for name, data := range files {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(data+"\n"), 0644))
+		}
+	}
+	return root
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		supplies map[string]map[string]string
+		expected Info
+	}{
+		{
+			name:     "desktop",
+			supplies: map[string]map[string]string{"AC": {"type": "Mains", "online": "1"}},
+			expected: Info{State: StateNoBattery, Seconds: -1, Percent: -1},
+		},
+		{
+			name: "discharging",
+			supplies: map[string]map[string]string{
+				"AC":   {"type": "Mains", "online": "0"},
+				"BAT0": {"type": "Battery", "status": "Discharging", "capacity": "42", "time_to_empty_now": "5400"},
+			},
+			expected: Info{State: StateOnBattery, Seconds: 5400, Percent: 42},
+		},
+		{
+			name: "estimated from energy",
+			supplies: map[string]map[string]string{
+				"BAT0": {"type": "Battery", "status": "Discharging", "capacity": "50", "energy_now": "20000000", "power_now": "10000000"},
+			},
+			expected: Info{State: StateOnBattery, Seconds: 7200, Percent: 50},
+		},
+		{
+			name: "charging",
+			supplies: map[string]map[string]string{
+				"BAT0": {"type": "Battery", "status": "Charging", "capacity": "105"},
+			},
+			expected: Info{State: StateCharging, Seconds: -1, Percent: 100},
+		},
+		{
+			name: "charged",
+			supplies: map[string]map[string]string{
+				"BAT0": {"type": "Battery", "status": "Not charging", "capacity": "100"},
+			},
+			expected: Info{State: StateCharged, Seconds: -1, Percent: 100},
+		},
+		{
+			name: "removed",
+			supplies: map[string]map[string]string{
+				"BAT0": {"type": "Battery", "present": "0", "status": "Unknown"},
+			},
+			expected: Info{State: StateNoBattery, Seconds: -1, Percent: -1},
+		},
+		{
+			name: "unknown status",
+			supplies: map[string]map[string]string{
+				"BAT0": {"type": "Battery", "capacity": "12"},
+			},
+			expected: Info{State: StateUnknown, Seconds: -1, Percent: 12},
+		},
+		{
+			name: "most time left",
+			supplies: map[string]map[string]string{
+				"BAT0": {"type": "Battery", "status": "Discharging", "capacity": "90", "time_to_empty_now": "600"},
+				"BAT1": {"type": "Battery", "status": "Discharging", "capacity": "20", "time_to_empty_now": "1800"},
+			},
+			expected: Info{State: StateOnBattery, Seconds: 1800, Percent: 20},
+		},
+		{
+			name: "highest percentage",
+			supplies: map[string]map[string]string{
+				"BAT0": {"type": "Battery", "status": "Charging", "capacity": "30"},
+				"BAT1": {"type": "Battery", "status": "Full", "capacity": "100"},
+			},
+			expected: Info{State: StateCharged, Seconds: -1, Percent: 100},
+		},
+		{
+			name: "device battery",
+			supplies: map[string]map[string]string{
+				"BAT0":  {"type": "Battery", "status": "Charging", "capacity": "30"},
+				"hid-0": {"type": "Battery", "scope": "Device", "status": "Discharging", "capacity": "80", "time_to_empty_now": "36000"},
+			},
+			expected: Info{State: StateCharging, Seconds: -1, Percent: 30},
+		},
+	}
+
+	for _, test := range tests {
+		root := writeTree(t, test.supplies)
+		info, err := Load(root)
+		os.RemoveAll(root)
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, *info, test.name)
+	}
+}
+
+func TestLoadMissingRoot(t *testing.T) {
+	_, err := Load(filepath.Join(os.TempDir(), "power-does-not-exist"))
+	assert.Error(t, err)
+}
+
+func TestStateString(t *testing.T) {
+	assert.Equal(t, "on battery", StateOnBattery.String())
+	assert.Equal(t, "unknown", State(42).String())
+}
@@ -0,0 +1,8 @@
+// +build !linux
+
+package power
+
+// Load returns an unknown power state on platforms without support, root is ignored.
+func Load(root string) (*Info, error) {
+	return &Info{State: StateUnknown, Seconds: -1, Percent: -1}, nil
+}
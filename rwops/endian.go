@@ -0,0 +1,117 @@
+package rwops
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+func (rw *RWops) read(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(rw, buf); err != nil {
+		return nil, errors.Wrap(err, "unable to read value")
+	}
+	return buf, nil
+}
+
+func (rw *RWops) write(buf []byte) error {
+	_, err := rw.Write(buf)
+	return errors.Wrap(err, "unable to write value")
+}
+
+func (rw *RWops) ReadU8() (uint8, error) {
+	buf, err := rw.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+func (rw *RWops) ReadLE16() (uint16, error) {
+	buf, err := rw.read(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(buf), nil
+}
+
+func (rw *RWops) ReadBE16() (uint16, error) {
+	buf, err := rw.read(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(buf), nil
+}
+
+func (rw *RWops) ReadLE32() (uint32, error) {
+	buf, err := rw.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf), nil
+}
+
+func (rw *RWops) ReadBE32() (uint32, error) {
+	buf, err := rw.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(buf), nil
+}
+
+func (rw *RWops) ReadLE64() (uint64, error) {
+	buf, err := rw.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+func (rw *RWops) ReadBE64() (uint64, error) {
+	buf, err := rw.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf), nil
+}
+
+func (rw *RWops) WriteU8(v uint8) error {
+	return rw.write([]byte{v})
+}
+
+func (rw *RWops) WriteLE16(v uint16) error {
+	buf := make([]byte, 2)
+	binary.LittleEndian.PutUint16(buf, v)
+	return rw.write(buf)
+}
+
+func (rw *RWops) WriteBE16(v uint16) error {
+	buf := make([]byte, 2)
+	binary.BigEndian.PutUint16(buf, v)
+	return rw.write(buf)
+}
+
+func (rw *RWops) WriteLE32(v uint32) error {
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, v)
+	return rw.write(buf)
+}
+
+func (rw *RWops) WriteBE32(v uint32) error {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, v)
+	return rw.write(buf)
+}
+
+func (rw *RWops) WriteLE64(v uint64) error {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, v)
+	return rw.write(buf)
+}
+
+func (rw *RWops) WriteBE64(v uint64) error {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, v)
+	return rw.write(buf)
+}
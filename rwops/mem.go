@@ -0,0 +1,58 @@
+package rwops
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+type memStream struct {
+	mem []byte
+	off int64
+}
+
+func (m *memStream) Size() (int64, error) {
+	return int64(len(m.mem)), nil
+}
+
+func (m *memStream) Read(p []byte) (int, error) {
+	if m.off >= int64(len(m.mem)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.mem[m.off:])
+	m.off += int64(n)
+	return n, nil
+}
+
+func (m *memStream) Write(p []byte) (int, error) {
+	if m.off >= int64(len(m.mem)) {
+		return 0, io.ErrShortWrite
+	}
+	n := copy(m.mem[m.off:], p)
+	m.off += int64(n)
+	if n < len(p) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
+
+func (m *memStream) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += m.off
+	case io.SeekEnd:
+		offset += int64(len(m.mem))
+	default:
+		return -1, errors.New("invalid whence")
+	}
+	// clamp to the memory like SDL does
+	switch {
+	case offset < 0:
+		offset = 0
+	case offset > int64(len(m.mem)):
+		offset = int64(len(m.mem))
+	}
+	m.off = offset
+	return offset, nil
+}
@@ -0,0 +1,200 @@
+package rwops
+
+import (
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RWops is a seekable stream that reads and writes files, memory and other
+// Go readers through a single interface. It implements io.ReadWriteSeeker
+// and io.Closer.
+type RWops struct {
+	r io.Reader
+	w io.Writer
+	s io.Seeker
+	c io.Closer
+
+	size func() (int64, error)
+}
+
+// FromFile opens a file with a C stdio style mode: "r", "w", "a", "r+",
+// "w+" or "a+", optionally including "b" which is ignored.
+func FromFile(name, mode string) (*RWops, error) {
+	flag, err := modeFlag(mode)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(name, flag, 0666)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open file (%s)", name)
+	}
+	rw := &RWops{s: f, c: f}
+	if flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+		rw.w = f
+	}
+	if flag&os.O_WRONLY == 0 {
+		rw.r = f
+	}
+	rw.size = func() (int64, error) {
+		stat, err := f.Stat()
+		if err != nil {
+			return -1, errors.Wrap(err, "unable to stat file")
+		}
+		return stat.Size(), nil
+	}
+	return rw, nil
+}
+
+func modeFlag(mode string) (int, error) {
+	switch strings.Replace(mode, "b", "", -1) {
+	case "r":
+		return os.O_RDONLY, nil
+	case "w":
+		return os.O_WRONLY | os.O_CREATE | os.O_TRUNC, nil
+	case "a":
+		return os.O_WRONLY | os.O_CREATE | os.O_APPEND, nil
+	case "r+":
+		return os.O_RDWR, nil
+	case "w+":
+		return os.O_RDWR | os.O_CREATE | os.O_TRUNC, nil
+	case "a+":
+		return os.O_RDWR | os.O_CREATE | os.O_APPEND, nil
+	}
+	return 0, errors.Errorf("invalid file mode (%s)", mode)
+}
+
+// FromMem creates a stream that reads and writes the given memory. Writes
+// cannot grow the slice, they stop at its end.
+func FromMem(mem []byte) *RWops {
+	m := &memStream{mem: mem}
+	return &RWops{r: m, w: m, s: m, size: m.Size}
+}
+
+// FromConstMem creates a read-only stream over the given memory.
+func FromConstMem(mem []byte) *RWops {
+	m := &memStream{mem: mem}
+	return &RWops{r: m, s: m, size: m.Size}
+}
+
+// FromReaderAt creates a read-only stream over the first size bytes of r.
+func FromReaderAt(r io.ReaderAt, size int64) *RWops {
+	sr := io.NewSectionReader(r, 0, size)
+	rw := &RWops{r: sr, s: sr, size: func() (int64, error) { return sr.Size(), nil }}
+	if c, ok := r.(io.Closer); ok {
+		rw.c = c
+	}
+	return rw
+}
+
+// FromReadSeeker creates a read-only stream from rs, it is closed with the
+// stream if it implements io.Closer.
+func FromReadSeeker(rs io.ReadSeeker) *RWops {
+	rw := &RWops{r: rs, s: rs}
+	if c, ok := rs.(io.Closer); ok {
+		rw.c = c
+	}
+	return rw
+}
+
+// FromFS opens name in fsys, this works with embed.FS as well as os.DirFS.
+// Files that cannot seek are read into memory.
+func FromFS(fsys fs.FS, name string) (*RWops, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open file (%s)", name)
+	}
+	if rs, ok := f.(io.ReadSeeker); ok {
+		rw := &RWops{r: rs, s: rs, c: f}
+		if stat, err := f.Stat(); err == nil {
+			size := stat.Size()
+			rw.size = func() (int64, error) { return size, nil }
+		}
+		return rw, nil
+	}
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read file (%s)", name)
+	}
+	return FromConstMem(data), nil
+}
+
+// Size returns the size of the stream in bytes.
+func (rw *RWops) Size() (int64, error) {
+	if rw.size != nil {
+		return rw.size()
+	}
+	cur, err := rw.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1, err
+	}
+	end, err := rw.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1, err
+	}
+	if _, err := rw.Seek(cur, io.SeekStart); err != nil {
+		return -1, err
+	}
+	return end, nil
+}
+
+func (rw *RWops) Seek(offset int64, whence int) (int64, error) {
+	if rw.s == nil {
+		return -1, errors.New("stream does not support seeking")
+	}
+	return rw.s.Seek(offset, whence)
+}
+
+// Tell returns the current offset in the stream.
+func (rw *RWops) Tell() (int64, error) {
+	return rw.Seek(0, io.SeekCurrent)
+}
+
+func (rw *RWops) Read(p []byte) (int, error) {
+	if rw.r == nil {
+		return 0, errors.New("stream is write-only")
+	}
+	return rw.r.Read(p)
+}
+
+func (rw *RWops) Write(p []byte) (int, error) {
+	if rw.w == nil {
+		return 0, errors.New("stream is read-only")
+	}
+	return rw.w.Write(p)
+}
+
+// Close releases the underlying resource, if there is one.
+func (rw *RWops) Close() error {
+	if rw.c == nil {
+		return nil
+	}
+	return rw.c.Close()
+}
+
+// LoadFile reads the whole named file into memory.
+func LoadFile(name string) ([]byte, error) {
+	rw, err := FromFile(name, "rb")
+	if err != nil {
+		return nil, err
+	}
+	return LoadFileRW(rw, true)
+}
+
+// LoadFileRW reads the rest of the stream into memory, closing it when
+// closeSrc is set.
+func LoadFileRW(rw *RWops, closeSrc bool) ([]byte, error) {
+	if closeSrc {
+		defer rw.Close()
+	}
+	data, err := ioutil.ReadAll(rw)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read stream")
+	}
+	return data, nil
+}
@@ -0,0 +1,155 @@
+package rwops
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sample = []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09}
+
+func checkStream(t *testing.T, rw *RWops) {
+	size, err := rw.Size()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(sample)), size)
+
+	u8, err := rw.ReadU8()
+	require.NoError(t, err)
+	assert.Equal(t, uint8(0x01), u8)
+	le16, err := rw.ReadLE16()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0x0302), le16)
+	be16, err := rw.ReadBE16()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0x0405), be16)
+	be32, err := rw.ReadBE32()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0x06070809), be32)
+	_, err = rw.ReadU8()
+	assert.Error(t, err)
+
+	off, err := rw.Seek(-8, io.SeekEnd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), off)
+	le64, err := rw.ReadLE64()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0x0908070605040302), le64)
+
+	_, err = rw.Seek(1, io.SeekStart)
+	require.NoError(t, err)
+	be64, err := rw.ReadBE64()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0x0203040506070809), be64)
+
+	_, err = rw.Seek(5, io.SeekStart)
+	require.NoError(t, err)
+	le32, err := rw.ReadLE32()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0x09080706), le32)
+	tell, err := rw.Tell()
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), tell)
+	assert.NoError(t, rw.Close())
+}
+
+// noSeekFS hides the Seek method of the files it opens.
+type noSeekFS struct {
+	fs.FS
+}
+
+type noSeekFile struct {
+	fs.File
+}
+
+func (ns noSeekFS) Open(name string) (fs.File, error) {
+	f, err := ns.FS.Open(name)
+	return noSeekFile{f}, err
+}
+
+func TestSources(t *testing.T) {
+	checkStream(t, FromConstMem(sample))
+	checkStream(t, FromMem(append([]byte(nil), sample...)))
+	checkStream(t, FromReaderAt(bytes.NewReader(sample), int64(len(sample))))
+	checkStream(t, FromReadSeeker(bytes.NewReader(sample)))
+
+	fsys := fstest.MapFS{"assets/data.bin": {Data: sample}}
+	rw, err := FromFS(fsys, "assets/data.bin")
+	require.NoError(t, err)
+	checkStream(t, rw)
+	rw, err = FromFS(noSeekFS{fsys}, "assets/data.bin")
+	require.NoError(t, err)
+	checkStream(t, rw)
+	_, err = FromFS(fsys, "missing.bin")
+	assert.Error(t, err)
+
+	dir, err := ioutil.TempDir("", "rwops")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "data.bin")
+	require.NoError(t, ioutil.WriteFile(name, sample, 0644))
+	rw, err = FromFile(name, "rb")
+	require.NoError(t, err)
+	checkStream(t, rw)
+}
+
+func TestWrite(t *testing.T) {
+	mem := make([]byte, 17)
+	rw := FromMem(mem)
+	require.NoError(t, rw.WriteU8(0x01))
+	require.NoError(t, rw.WriteLE16(0x0302))
+	require.NoError(t, rw.WriteBE16(0x0405))
+	require.NoError(t, rw.WriteLE32(0x09080706))
+	require.NoError(t, rw.WriteBE64(0x0a0b0c0d0e0f1011))
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17}, mem)
+	assert.Error(t, rw.WriteLE16(0))
+
+	rw = FromMem(make([]byte, 1))
+	assert.Error(t, rw.WriteLE16(0))
+	assert.Error(t, FromConstMem(mem).WriteU8(0))
+
+	dir, err := ioutil.TempDir("", "rwops")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "out.bin")
+	rw, err = FromFile(name, "wb")
+	require.NoError(t, err)
+	require.NoError(t, rw.WriteBE32(0x01020304))
+	require.NoError(t, rw.WriteLE32(0x08070605))
+	_, err = rw.ReadU8()
+	assert.Error(t, err)
+	require.NoError(t, rw.Close())
+
+	rw, err = FromFile(name, "a+")
+	require.NoError(t, err)
+	require.NoError(t, rw.WriteU8(0x09))
+	require.NoError(t, rw.Close())
+
+	data, err := LoadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, sample, data)
+
+	_, err = FromFile(name, "x")
+	assert.Error(t, err)
+}
+
+func TestMemSeekClamps(t *testing.T) {
+	rw := FromConstMem(sample)
+	off, err := rw.Seek(-4, io.SeekStart)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), off)
+	off, err = rw.Seek(100, io.SeekCurrent)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(sample)), off)
+
+	data, err := LoadFileRW(rw, false)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
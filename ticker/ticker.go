@@ -2,18 +2,49 @@ package ticker
 
 import "time"
 
-
 // TODO: make a windows version that uses GetTick
 var Start time.Time
 
+func init() {
+	Initialize()
+}
+
 func Initialize() {
-	Start = time.Now()
+	Start = clock.Now()
 }
 
 func Get() time.Duration {
-	return time.Now().Sub(Start)
+	return clock.Now().Sub(Start)
 }
 
+// GetAsMS returns the milliseconds since initialization. The value wraps after
+// roughly 49 days, use GetTicks64 where that matters.
 func GetAsMS() uint32 {
 	return uint32(Get() / time.Millisecond)
 }
+
+// GetTicks64 returns the milliseconds since initialization as a 64-bit value.
+func GetTicks64() uint64 {
+	return uint64(Get() / time.Millisecond)
+}
+
+// GetTicksNS returns the nanoseconds since initialization.
+func GetTicksNS() uint64 {
+	return uint64(Get())
+}
+
+// PerformanceCounter returns the current value of the high resolution counter,
+// it is only meaningful relative to other values returned by this function.
+func PerformanceCounter() uint64 {
+	return uint64(Get())
+}
+
+// PerformanceFrequency returns the number of PerformanceCounter counts per second.
+func PerformanceFrequency() uint64 {
+	return uint64(time.Second)
+}
+
+// Delay sleeps the calling go-routine for at least d using the current clock.
+func Delay(d time.Duration) {
+	clock.Sleep(d)
+}
@@ -0,0 +1,45 @@
+package ticker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClockTicks(t *testing.T) {
+	fc := NewFakeClock(time.Unix(1000, 0))
+	prev := SetClock(fc)
+	defer SetClock(prev)
+
+	assert.Equal(t, uint64(0), GetTicks64())
+	fc.Advance(1500 * time.Millisecond)
+	assert.Equal(t, uint64(1500), GetTicks64())
+	assert.Equal(t, uint32(1500), GetAsMS())
+	assert.Equal(t, uint64(1500*time.Millisecond), GetTicksNS())
+
+	Delay(250 * time.Millisecond)
+	assert.Equal(t, uint64(1750), GetTicks64())
+}
+
+func TestGetTicks64DoesNotWrap(t *testing.T) {
+	fc := NewFakeClock(time.Unix(0, 0))
+	prev := SetClock(fc)
+	defer SetClock(prev)
+
+	fc.Advance(50 * 24 * time.Hour)
+	ms := uint64(50 * 24 * time.Hour / time.Millisecond)
+	assert.Equal(t, ms, GetTicks64())
+	assert.Equal(t, uint32(ms), GetAsMS())
+}
+
+func TestPerformanceCounter(t *testing.T) {
+	fc := NewFakeClock(time.Unix(0, 0))
+	prev := SetClock(fc)
+	defer SetClock(prev)
+
+	start := PerformanceCounter()
+	fc.Advance(2 * time.Second)
+	elapsed := PerformanceCounter() - start
+	assert.Equal(t, uint64(2), elapsed/PerformanceFrequency())
+}
@@ -0,0 +1,10 @@
+package video
+
+// Blend modes, used for blits and rendering.
+const (
+	BlendModeNone  = 0x00000000 // dstRGBA = srcRGBA
+	BlendModeBlend = 0x00000001 // dstRGB = srcRGB * srcA + dstRGB * (1-srcA), dstA = srcA + dstA * (1-srcA)
+	BlendModeAdd   = 0x00000002 // dstRGB = srcRGB * srcA + dstRGB, dstA = dstA
+	BlendModeMod   = 0x00000004 // dstRGB = srcRGB * dstRGB, dstA = dstA
+	BlendModeMul   = 0x00000008 // dstRGB = srcRGB * dstRGB + dstRGB * (1-srcA), dstA = srcA * dstA + dstA * (1-srcA)
+)
@@ -1,3 +1,647 @@
 package video
 
-type BlitMap struct {}
\ No newline at end of file
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// Copy flags describe what a blit has to do on top of converting pixels.
+const (
+	copyModulateColor = 0x00000001
+	copyModulateAlpha = 0x00000002
+	copyBlend         = 0x00000010
+	copyAdd           = 0x00000020
+	copyMod           = 0x00000040
+	copyMul           = 0x00000080
+	copyColorKey      = 0x00000100
+
+	copyBlendMask = copyBlend | copyAdd | copyMod | copyMul
+)
+
+// blitInfo holds everything a blit function needs. The src and dst slices
+// start at the first row of the blit, srcX and dstX are the first column.
+type blitInfo struct {
+	src              []byte
+	srcX, srcW, srcH int
+	srcPitch         int
+	dst              []byte
+	dstX, dstW, dstH int
+	dstPitch         int
+
+	srcFormat, dstFormat *PixelFormat
+
+	flags      uint32
+	colorKey   uint32
+	r, g, b, a uint8
+
+	// table maps each index of a paletted source to a destination pixel
+	table []uint32
+
+	// scaled blits look up the source position of each destination column
+	// and row in xs and ys, in 16.16 fixed point. The src slice starts at
+	// the first row of the surface, and srcClip is the readable area.
+	xs, ys  []int
+	srcClip Rect
+}
+
+type blitFunc func(info *blitInfo)
+
+// BlitMap caches how a surface is blitted to its last destination. It is
+// rebuilt when the destination, either format or either palette changes.
+type BlitMap struct {
+	dst        *Surface
+	dstFormat  *PixelFormat
+	srcFormat  *PixelFormat
+	srcPalette *Palette
+	dstPalette *Palette
+
+	srcPaletteVersion, dstPaletteVersion uint32
+
+	info  blitInfo
+	flags uint32 // the flags the blit function was picked for
+	blit  blitFunc
+}
+
+func newBlitMap() *BlitMap {
+	return &BlitMap{info: blitInfo{r: 0xFF, g: 0xFF, b: 0xFF, a: 0xFF}}
+}
+
+func (m *BlitMap) invalidate() {
+	m.dst = nil
+	m.blit = nil
+	m.info.table = nil
+}
+
+func paletteVersion(p *Palette) uint32 {
+	if p == nil {
+		return 0
+	}
+	return p.version
+}
+
+// valid returns whether the cached blit can still be used from src to dst.
+func (m *BlitMap) valid(src, dst *Surface) bool {
+	return m.blit != nil && m.dst == dst &&
+		m.srcFormat == src.format && m.dstFormat == dst.format &&
+		m.srcPalette == src.format.palette && m.dstPalette == dst.format.palette &&
+		m.srcPaletteVersion == paletteVersion(src.format.palette) &&
+		m.dstPaletteVersion == paletteVersion(dst.format.palette)
+}
+
+// mapSurface picks the blit function from src to dst.
+func (m *BlitMap) mapSurface(src, dst *Surface) error {
+	m.invalidate()
+	sf, df := src.format, dst.format
+	if IsPixelFormatFourCC(df.format) {
+		return errors.Errorf("blit to %s is not supported", GetPixelFormatName(df.format))
+	}
+
+	flags := m.info.flags
+	if flags&copyBlend > 0 && sf.aMask == 0 && flags&copyModulateAlpha == 0 && sf.palette == nil {
+		// blending an opaque source is a copy
+		flags &^= copyBlend
+	}
+
+	info := &m.info
+	info.srcFormat, info.dstFormat = sf, df
+	switch {
+	case sf.bitsPerPixel >= 8 && canCopy(sf, df, flags):
+		m.blit = blitCopy
+	case sf.palette != nil && flags&^copyColorKey == 0:
+		info.table = make([]uint32, len(sf.palette.colors))
+		for i, c := range sf.palette.colors {
+			info.table[i] = MapRGBA(df, c.R, c.G, c.B, c.A)
+		}
+		m.blit = blitIndexed
+	case is8888(sf) && is8888(df):
+		switch flags {
+		case 0:
+			m.blit = blit8888Swizzle
+		case copyBlend:
+			m.blit = blit8888Blend
+		default:
+			m.blit = blit8888
+		}
+	default:
+		m.blit = blitGeneric
+	}
+	m.flags = flags
+
+	m.dst = dst
+	m.srcFormat, m.dstFormat = sf, df
+	m.srcPalette, m.dstPalette = sf.palette, df.palette
+	m.srcPaletteVersion = paletteVersion(sf.palette)
+	m.dstPaletteVersion = paletteVersion(df.palette)
+	return nil
+}
+
+// canCopy returns whether pixels can be copied without conversion.
+func canCopy(sf, df *PixelFormat, flags uint32) bool {
+	return sf.format == df.format && flags == 0 && samePalette(sf.palette, df.palette)
+}
+
+// samePalette returns whether two palettes have the same colors.
+func samePalette(a, b *Palette) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil || len(a.colors) != len(b.colors) {
+		return false
+	}
+	for i := range a.colors {
+		if a.colors[i] != b.colors[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// is8888 returns whether a format is 32 bits with 8 bit color channels, and
+// either an 8 bit alpha channel or none.
+func is8888(f *PixelFormat) bool {
+	return f.bytesPerPixel == 4 && f.rBits == 8 && f.gBits == 8 && f.bBits == 8 &&
+		(f.aBits == 8 || f.aBits == 0)
+}
+
+// BlitSurface copies srcRect from src to dstRect in dst, performing any
+// format conversion, color keying, modulation and blending set up on src.
+// A nil srcRect copies the whole surface, only the position of dstRect is
+// used, and it is updated with the area that was actually drawn after
+// clipping.
+func BlitSurface(src *Surface, srcRect *Rect, dst *Surface, dstRect *Rect) error {
+	if err := checkBlit(src, dst); err != nil {
+		return err
+	}
+
+	sr := Rect{W: src.w, H: src.h}
+	if srcRect != nil {
+		sr = *srcRect
+	}
+	var d Point
+	if dstRect != nil {
+		d = Point{X: dstRect.X, Y: dstRect.Y}
+	}
+
+	// clip the source to the surface, moving the destination with it
+	if sr.X < 0 {
+		sr.W += sr.X
+		d.X -= sr.X
+		sr.X = 0
+	}
+	if sr.Y < 0 {
+		sr.H += sr.Y
+		d.Y -= sr.Y
+		sr.Y = 0
+	}
+	sr.W = min(sr.W, src.w-sr.X)
+	sr.H = min(sr.H, src.h-sr.Y)
+
+	// clip the destination to the clip rect, moving the source with it
+	clip := dst.clipRect
+	if dx := clip.X - d.X; dx > 0 {
+		sr.W -= dx
+		sr.X += dx
+		d.X += dx
+	}
+	if dx := d.X + sr.W - clip.X - clip.W; dx > 0 {
+		sr.W -= dx
+	}
+	if dy := clip.Y - d.Y; dy > 0 {
+		sr.H -= dy
+		sr.Y += dy
+		d.Y += dy
+	}
+	if dy := d.Y + sr.H - clip.Y - clip.H; dy > 0 {
+		sr.H -= dy
+	}
+
+	if sr.Empty() {
+		if dstRect != nil {
+			dstRect.W, dstRect.H = 0, 0
+		}
+		return nil
+	}
+	dr := Rect{X: d.X, Y: d.Y, W: sr.W, H: sr.H}
+	if dstRect != nil {
+		*dstRect = dr
+	}
+	return lowerBlit(src, sr, dst, dr)
+}
+
+func checkBlit(src, dst *Surface) error {
+	if src == nil || dst == nil || src.format == nil || dst.format == nil {
+		return errors.New("invalid surface")
+	}
+	if src.locked > 0 || dst.locked > 0 {
+		return errors.New("surfaces must not be locked during blit")
+	}
+	return nil
+}
+
+// lowerBlit blits between rects that are already clipped.
+func lowerBlit(src *Surface, srcRect Rect, dst *Surface, dstRect Rect) error {
+	m := src.blitMap
+	if !m.valid(src, dst) {
+		if err := m.mapSurface(src, dst); err != nil {
+			return err
+		}
+	}
+
+	info := m.info
+	info.flags = m.flags
+	info.src = src.pixels[srcRect.Y*src.pitch:]
+	info.srcX, info.srcW, info.srcH = srcRect.X, srcRect.W, srcRect.H
+	info.srcPitch = src.pitch
+	info.dst = dst.pixels[dstRect.Y*dst.pitch:]
+	info.dstX, info.dstW, info.dstH = dstRect.X, dstRect.W, dstRect.H
+	info.dstPitch = dst.pitch
+	m.blit(&info)
+	return nil
+}
+
+// SetColorKey sets the pixel value that is transparent when blitting, for
+// indexed formats this is the palette index.
+func (s *Surface) SetColorKey(enable bool, key uint32) error {
+	if s.format == nil {
+		return errors.New("surface has been freed")
+	}
+	if IsPixelFormatIndexed(s.format.format) && key >= 1<<s.format.bitsPerPixel {
+		return errors.Errorf("color key %d out of range", key)
+	}
+	m := s.blitMap
+	if enable {
+		m.info.flags |= copyColorKey
+		m.info.colorKey = key
+	} else {
+		m.info.flags &^= copyColorKey
+	}
+	m.invalidate()
+	return nil
+}
+
+// HasColorKey returns whether the surface has a color key.
+func (s *Surface) HasColorKey() bool {
+	return s.blitMap.info.flags&copyColorKey > 0
+}
+
+// GetColorKey returns the color key, or an error if there is none.
+func (s *Surface) GetColorKey() (uint32, error) {
+	if !s.HasColorKey() {
+		return 0, errors.New("surface doesn't have a colorkey")
+	}
+	return s.blitMap.info.colorKey, nil
+}
+
+// SetColorMod sets the color that source pixels are multiplied with when
+// blitting.
+func (s *Surface) SetColorMod(r, g, b uint8) {
+	m := s.blitMap
+	m.info.r, m.info.g, m.info.b = r, g, b
+	if r&g&b != 0xFF {
+		m.info.flags |= copyModulateColor
+	} else {
+		m.info.flags &^= copyModulateColor
+	}
+	m.invalidate()
+}
+
+func (s *Surface) GetColorMod() (r, g, b uint8) {
+	return s.blitMap.info.r, s.blitMap.info.g, s.blitMap.info.b
+}
+
+// SetAlphaMod sets the value that source alpha is multiplied with when
+// blitting.
+func (s *Surface) SetAlphaMod(a uint8) {
+	m := s.blitMap
+	m.info.a = a
+	if a != 0xFF {
+		m.info.flags |= copyModulateAlpha
+	} else {
+		m.info.flags &^= copyModulateAlpha
+	}
+	m.invalidate()
+}
+
+func (s *Surface) GetAlphaMod() uint8 {
+	return s.blitMap.info.a
+}
+
+var blendModeFlags = map[uint32]uint32{
+	BlendModeNone:  0,
+	BlendModeBlend: copyBlend,
+	BlendModeAdd:   copyAdd,
+	BlendModeMod:   copyMod,
+	BlendModeMul:   copyMul,
+}
+
+// SetBlendMode sets how the surface is combined with the destination when
+// blitting.
+func (s *Surface) SetBlendMode(blendMode uint32) error {
+	flags, ok := blendModeFlags[blendMode]
+	if !ok {
+		return errors.Errorf("unsupported blend mode 0x%x", blendMode)
+	}
+	m := s.blitMap
+	m.info.flags = m.info.flags&^copyBlendMask | flags
+	m.invalidate()
+	return nil
+}
+
+func (s *Surface) GetBlendMode() uint32 {
+	flags := s.blitMap.info.flags & copyBlendMask
+	for mode, f := range blendModeFlags {
+		if f == flags {
+			return mode
+		}
+	}
+	return BlendModeNone
+}
+
+// modulate applies the color and alpha modulation of the blit.
+func (info *blitInfo) modulate(r, g, b, a uint8) (uint8, uint8, uint8, uint8) {
+	if info.flags&copyModulateColor > 0 {
+		r = uint8(uint32(r) * uint32(info.r) / 255)
+		g = uint8(uint32(g) * uint32(info.g) / 255)
+		b = uint8(uint32(b) * uint32(info.b) / 255)
+	}
+	if info.flags&copyModulateAlpha > 0 {
+		a = uint8(uint32(a) * uint32(info.a) / 255)
+	}
+	return r, g, b, a
+}
+
+// blend combines a source and destination color with the blend mode in flags.
+func blend(flags uint32, sr, sg, sb, sa, dr, dg, db, da uint32) (uint32, uint32, uint32, uint32) {
+	if flags&(copyBlend|copyAdd) > 0 && sa < 255 {
+		sr = sr * sa / 255
+		sg = sg * sa / 255
+		sb = sb * sa / 255
+	}
+	switch flags & copyBlendMask {
+	case copyBlend:
+		dr = sr + (255-sa)*dr/255
+		dg = sg + (255-sa)*dg/255
+		db = sb + (255-sa)*db/255
+		da = sa + (255-sa)*da/255
+	case copyAdd:
+		dr = min(sr+dr, 255)
+		dg = min(sg+dg, 255)
+		db = min(sb+db, 255)
+	case copyMod:
+		dr = sr * dr / 255
+		dg = sg * dg / 255
+		db = sb * db / 255
+	case copyMul:
+		dr = min((sr*dr+dr*(255-sa))/255, 255)
+		dg = min((sg*dg+dg*(255-sa))/255, 255)
+		db = min((sb*db+db*(255-sa))/255, 255)
+		da = min((sa*da+da*(255-sa))/255, 255)
+	default:
+		return sr, sg, sb, sa
+	}
+	return dr, dg, db, da
+}
+
+// readPixel returns the raw value of pixel x in a row.
+func readPixel(row []byte, x int, f *PixelFormat) uint32 {
+	if f.bitsPerPixel < 8 {
+		return uint32(getIndex(row, x, f.format))
+	}
+	bpp := int(f.bytesPerPixel)
+	return pixelAt(row[x*bpp:], bpp)
+}
+
+// writePixel stores the raw value of pixel x in a row.
+func writePixel(row []byte, x int, f *PixelFormat, pixel uint32) {
+	if f.bitsPerPixel < 8 {
+		setIndex(row, x, f.format, uint8(pixel))
+		return
+	}
+	bpp := int(f.bytesPerPixel)
+	putPixel(row[x*bpp:], bpp, pixel)
+}
+
+// keyMask returns the mask and value that source pixels are compared with
+// for the color key.
+func (info *blitInfo) keyMask() (rgbMask, key uint32) {
+	rgbMask = ^info.srcFormat.aMask
+	if info.srcFormat.palette != nil {
+		rgbMask = ^uint32(0)
+	}
+	return rgbMask, info.colorKey & rgbMask
+}
+
+// put runs a source color through modulation and blending, and stores it
+// as pixel x of a destination row.
+func (info *blitInfo) put(dst []byte, x int, r, g, b, a uint8) {
+	df := info.dstFormat
+	r, g, b, a = info.modulate(r, g, b, a)
+	var dr, dg, db, da uint8
+	if info.flags&copyBlendMask > 0 {
+		dr, dg, db, da = GetRGBA(readPixel(dst, x, df), df)
+	}
+	br, bg, bb, ba := blend(info.flags, uint32(r), uint32(g), uint32(b), uint32(a),
+		uint32(dr), uint32(dg), uint32(db), uint32(da))
+	writePixel(dst, x, df, MapRGBA(df, uint8(br), uint8(bg), uint8(bb), uint8(ba)))
+}
+
+// blitGeneric handles every combination of formats and flags, one pixel at
+// a time.
+func blitGeneric(info *blitInfo) {
+	sf := info.srcFormat
+	rgbMask, key := info.keyMask()
+	colorKey := info.flags&copyColorKey > 0
+	for y := 0; y < info.dstH; y++ {
+		src := info.src[y*info.srcPitch:]
+		dst := info.dst[y*info.dstPitch:]
+		for x := 0; x < info.dstW; x++ {
+			sp := readPixel(src, info.srcX+x, sf)
+			if colorKey && sp&rgbMask == key {
+				continue
+			}
+			r, g, b, a := GetRGBA(sp, sf)
+			info.put(dst, info.dstX+x, r, g, b, a)
+		}
+	}
+}
+
+// blitCopy copies rows between surfaces of the same format. Within one
+// surface rows are copied bottom up when the destination starts after the
+// source, so no row is overwritten before it is read.
+func blitCopy(info *blitInfo) {
+	bpp := int(info.srcFormat.bytesPerPixel)
+	n := info.dstW * bpp
+	start, end, step := 0, info.dstH, 1
+	if samePixels(info.src, info.dst) && info.dstX*bpp-cap(info.dst) > info.srcX*bpp-cap(info.src) {
+		start, end, step = info.dstH-1, -1, -1
+	}
+	for y := start; y != end; y += step {
+		src := info.src[y*info.srcPitch+info.srcX*bpp:]
+		dst := info.dst[y*info.dstPitch+info.dstX*bpp:]
+		copy(dst[:n], src[:n])
+	}
+}
+
+// samePixels reports whether two slices of surface pixels end at the same
+// byte, which is when they are from the same surface. The difference of
+// their capacities is then the distance between their starts.
+func samePixels(a, b []byte) bool {
+	return cap(a) > 0 && cap(b) > 0 && &a[:cap(a)][cap(a)-1] == &b[:cap(b)][cap(b)-1]
+}
+
+// blitIndexed looks up each source index in the prebuilt table.
+func blitIndexed(info *blitInfo) {
+	sf, df := info.srcFormat, info.dstFormat
+	colorKey := info.flags&copyColorKey > 0
+	if sf.bitsPerPixel == 8 && df.bitsPerPixel >= 8 {
+		bpp := int(df.bytesPerPixel)
+		for y := 0; y < info.dstH; y++ {
+			src := info.src[y*info.srcPitch+info.srcX:]
+			dst := info.dst[y*info.dstPitch+info.dstX*bpp:]
+			for x, index := range src[:info.dstW] {
+				if colorKey && uint32(index) == info.colorKey {
+					continue
+				}
+				putPixel(dst[x*bpp:], bpp, info.table[index])
+			}
+		}
+		return
+	}
+	for y := 0; y < info.dstH; y++ {
+		src := info.src[y*info.srcPitch:]
+		dst := info.dst[y*info.dstPitch:]
+		for x := 0; x < info.dstW; x++ {
+			index := readPixel(src, info.srcX+x, sf)
+			if colorKey && index == info.colorKey {
+				continue
+			}
+			writePixel(dst, info.dstX+x, df, info.table[index])
+		}
+	}
+}
+
+func load32(b []byte) uint32 {
+	if bigEndian {
+		return binary.BigEndian.Uint32(b)
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func store32(b []byte, v uint32) {
+	if bigEndian {
+		binary.BigEndian.PutUint32(b, v)
+		return
+	}
+	binary.LittleEndian.PutUint32(b, v)
+}
+
+// blit8888Swizzle reorders the channels between two 8888 formats.
+func blit8888Swizzle(info *blitInfo) {
+	sf, df := info.srcFormat, info.dstFormat
+	srShift, sgShift, sbShift, saShift := sf.rShift, sf.gShift, sf.bShift, sf.aShift
+	drShift, dgShift, dbShift, daShift := df.rShift, df.gShift, df.bShift, df.aShift
+	srcAlpha, dstAlpha := sf.aMask != 0, df.aMask != 0
+	for y := 0; y < info.dstH; y++ {
+		src := info.src[y*info.srcPitch+info.srcX*4 : y*info.srcPitch+(info.srcX+info.dstW)*4]
+		dst := info.dst[y*info.dstPitch+info.dstX*4:]
+		for x := 0; x < len(src); x += 4 {
+			sp := load32(src[x:])
+			dp := (sp>>srShift&0xFF)<<drShift | (sp>>sgShift&0xFF)<<dgShift | (sp>>sbShift&0xFF)<<dbShift
+			if dstAlpha {
+				a := uint32(0xFF)
+				if srcAlpha {
+					a = sp >> saShift & 0xFF
+				}
+				dp |= a << daShift
+			}
+			store32(dst[x:], dp)
+		}
+	}
+}
+
+// blit8888Blend alpha blends between two 8888 formats.
+func blit8888Blend(info *blitInfo) {
+	sc, dc := newChannels8888(info.srcFormat), newChannels8888(info.dstFormat)
+	for y := 0; y < info.dstH; y++ {
+		src := info.src[y*info.srcPitch+info.srcX*4 : y*info.srcPitch+(info.srcX+info.dstW)*4]
+		dst := info.dst[y*info.dstPitch+info.dstX*4:]
+		for x := 0; x < len(src); x += 4 {
+			blendOver8888(dst[x:], load32(src[x:]), sc, dc)
+		}
+	}
+}
+
+// blendOver8888 alpha blends a source pixel over the destination pixel.
+func blendOver8888(dst []byte, sp uint32, sc, dc channels8888) {
+	sa := sp >> sc.aShift & 0xFF
+	switch sa {
+	case 0:
+		return
+	case 0xFF:
+		store32(dst, dc.pack(sp>>sc.rShift&0xFF, sp>>sc.gShift&0xFF, sp>>sc.bShift&0xFF, 0xFF))
+		return
+	}
+	dr, dg, db, da := dc.unpack(load32(dst))
+	inv := 255 - sa
+	store32(dst, dc.pack(
+		(sp>>sc.rShift&0xFF)*sa/255+inv*dr/255,
+		(sp>>sc.gShift&0xFF)*sa/255+inv*dg/255,
+		(sp>>sc.bShift&0xFF)*sa/255+inv*db/255,
+		sa+inv*da/255))
+}
+
+// channels8888 holds the channel positions of an 8888 format.
+type channels8888 struct {
+	rShift, gShift, bShift, aShift uint8
+	alpha                          bool
+}
+
+func newChannels8888(f *PixelFormat) channels8888 {
+	return channels8888{f.rShift, f.gShift, f.bShift, f.aShift, f.aMask != 0}
+}
+
+func (c channels8888) unpack(p uint32) (r, g, b, a uint32) {
+	r, g, b, a = p>>c.rShift&0xFF, p>>c.gShift&0xFF, p>>c.bShift&0xFF, 0xFF
+	if c.alpha {
+		a = p >> c.aShift & 0xFF
+	}
+	return r, g, b, a
+}
+
+func (c channels8888) pack(r, g, b, a uint32) uint32 {
+	p := r<<c.rShift | g<<c.gShift | b<<c.bShift
+	if c.alpha {
+		p |= a << c.aShift
+	}
+	return p
+}
+
+// put8888 is put for 8888 destinations.
+func (info *blitInfo) put8888(dst []byte, dc channels8888, r, g, b, a uint32) {
+	sr, sg, sb, sa := info.modulate(uint8(r), uint8(g), uint8(b), uint8(a))
+	var dr, dg, db, da uint32
+	if info.flags&copyBlendMask > 0 {
+		dr, dg, db, da = dc.unpack(load32(dst))
+	}
+	store32(dst, dc.pack(blend(info.flags, uint32(sr), uint32(sg), uint32(sb), uint32(sa), dr, dg, db, da)))
+}
+
+// blit8888 handles every flag between two 8888 formats.
+func blit8888(info *blitInfo) {
+	sc, dc := newChannels8888(info.srcFormat), newChannels8888(info.dstFormat)
+	rgbMask, key := info.keyMask()
+	colorKey := info.flags&copyColorKey > 0
+	for y := 0; y < info.dstH; y++ {
+		src := info.src[y*info.srcPitch+info.srcX*4 : y*info.srcPitch+(info.srcX+info.dstW)*4]
+		dst := info.dst[y*info.dstPitch+info.dstX*4:]
+		for x := 0; x < len(src); x += 4 {
+			sp := load32(src[x:])
+			if colorKey && sp&rgbMask == key {
+				continue
+			}
+			r, g, b, a := sc.unpack(sp)
+			info.put8888(dst[x:], dc, r, g, b, a)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package video
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRandomSurface(t testing.TB, rng *rand.Rand, w, h int, format uint32) *Surface {
+	s, err := CreateRGBSurfaceWithFormat(0, w, h, 0, format)
+	require.NoError(t, err)
+	rng.Read(s.pixels)
+	if p := s.format.palette; p != nil {
+		for i := range p.colors {
+			p.colors[i] = Color{R: uint8(rng.Intn(256)), G: uint8(rng.Intn(256)), B: uint8(rng.Intn(256)), A: uint8(rng.Intn(256))}
+		}
+		p.bump()
+	}
+	return s
+}
+
+// assertSamePixels compares the channels of every pixel, ignoring padding.
+func assertSamePixels(t *testing.T, want, got *Surface) {
+	mask := want.format.rMask | want.format.gMask | want.format.bMask | want.format.aMask
+	if want.format.palette != nil {
+		mask = ^uint32(0)
+	}
+	for y := 0; y < want.h; y++ {
+		for x := 0; x < want.w; x++ {
+			if want.getPixel(x, y)&mask != got.getPixel(x, y)&mask {
+				assert.Failf(t, "pixels differ", "pixel (%d, %d): want 0x%08x, got 0x%08x", x, y, want.getPixel(x, y), got.getPixel(x, y))
+				return
+			}
+		}
+	}
+}
+
+func TestBlitSurfaceClipping(t *testing.T) {
+	src, err := CreateRGBSurfaceWithFormat(0, 4, 4, 0, PixelFormatIndex8)
+	require.NoError(t, err)
+	for i := range src.pixels[:16] {
+		src.pixels[(i/4)*src.pitch+i%4] = uint8(i + 1)
+	}
+	dst, err := CreateRGBSurfaceWithFormat(0, 6, 6, 0, PixelFormatIndex8)
+	require.NoError(t, err)
+	dst.SetClipRect(&Rect{X: 1, Y: 1, W: 4, H: 4})
+
+	dr := Rect{X: -1, Y: 3, W: 100, H: 100}
+	require.NoError(t, BlitSurface(src, &Rect{X: -1, Y: 0, W: 3, H: 3}, dst, &dr))
+	// the source starts one column left of the surface, so the destination
+	// moves one right, then the clip rect removes another column
+	assert.Equal(t, Rect{X: 1, Y: 3, W: 1, H: 2}, dr)
+	assert.Equal(t, uint8(2), dst.pixels[3*dst.pitch+1])
+	assert.Equal(t, uint8(6), dst.pixels[4*dst.pitch+1])
+	assert.Equal(t, uint8(0), dst.pixels[5*dst.pitch+1])
+	assert.Equal(t, uint8(0), dst.pixels[3*dst.pitch+2])
+
+	dr = Rect{X: 5, Y: 5}
+	require.NoError(t, BlitSurface(src, nil, dst, &dr))
+	assert.Equal(t, 0, dr.W)
+	assert.Equal(t, 0, dr.H)
+
+	require.NoError(t, src.Lock())
+	assert.Error(t, BlitSurface(src, nil, dst, nil))
+	src.Unlock()
+	assert.Error(t, BlitSurface(nil, nil, dst, nil))
+}
+
+func TestBlitScroll(t *testing.T) {
+	s, err := CreateRGBSurfaceWithFormat(0, 3, 4, 0, PixelFormatXRGB8888)
+	require.NoError(t, err)
+	column := func(x int) []uint32 {
+		var out []uint32
+		for y := 0; y < s.h; y++ {
+			out = append(out, s.getPixel(x, y))
+		}
+		return out
+	}
+	reset := func() {
+		for y := 0; y < s.h; y++ {
+			for x := 0; x < s.w; x++ {
+				s.setPixel(x, y, uint32(10*x+y+1))
+			}
+		}
+	}
+
+	reset()
+	require.NoError(t, BlitSurface(s, &Rect{W: 1, H: 3}, s, &Rect{Y: 1}))
+	assert.Equal(t, []uint32{1, 1, 2, 3}, column(0))
+
+	reset()
+	require.NoError(t, BlitSurface(s, &Rect{Y: 1, W: 1, H: 3}, s, &Rect{}))
+	assert.Equal(t, []uint32{2, 3, 4, 4}, column(0))
+
+	// same rows, the destination to the right
+	reset()
+	require.NoError(t, BlitSurface(s, &Rect{W: 2, H: 4}, s, &Rect{X: 1}))
+	assert.Equal(t, []uint32{1, 2, 3, 4}, column(1))
+	assert.Equal(t, []uint32{11, 12, 13, 14}, column(2))
+
+	// left of and below the source still starts after it
+	reset()
+	require.NoError(t, BlitSurface(s, &Rect{X: 1, W: 2, H: 2}, s, &Rect{Y: 1}))
+	assert.Equal(t, []uint32{1, 11, 12, 4}, column(0))
+	assert.Equal(t, []uint32{11, 21, 22, 14}, column(1))
+}
+
+func TestBlendModes(t *testing.T) {
+	tests := []struct {
+		mode uint32
+		want Color
+	}{
+		{BlendModeNone, Color{R: 200, G: 100, B: 0, A: 128}},
+		{BlendModeBlend, Color{R: 100 + 127*40/255, G: 50 + 127*80/255, B: 127 * 120 / 255, A: 128 + 127*200/255}},
+		{BlendModeAdd, Color{R: 140, G: 130, B: 120, A: 200}},
+		{BlendModeMod, Color{R: 200 * 40 / 255, G: 100 * 80 / 255, B: 0, A: 200}},
+		{BlendModeMul, Color{R: (200*40 + 40*127) / 255, G: (100*80 + 80*127) / 255, B: 120 * 127 / 255, A: (128*200 + 200*127) / 255}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.mode), func(t *testing.T) {
+			src, err := CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatABGR8888)
+			require.NoError(t, err)
+			require.NoError(t, src.SetBlendMode(tt.mode))
+			assert.Equal(t, tt.mode, src.GetBlendMode())
+			require.NoError(t, src.FillRect(nil, MapRGBA(src.format, 200, 100, 0, 128)))
+
+			dst, err := CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatARGB8888)
+			require.NoError(t, err)
+			require.NoError(t, dst.FillRect(nil, MapRGBA(dst.format, 40, 80, 120, 200)))
+			require.NoError(t, BlitSurface(src, nil, dst, nil))
+			r, g, b, a := GetRGBA(dst.getPixel(0, 0), dst.format)
+			assert.Equal(t, tt.want, Color{r, g, b, a})
+		})
+	}
+
+	s, err := CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatRGB24)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(BlendModeNone), s.GetBlendMode())
+	assert.Error(t, s.SetBlendMode(3))
+	s, err = CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatARGB8888)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(BlendModeBlend), s.GetBlendMode())
+}
+
+func TestColorKeyAndMods(t *testing.T) {
+	src, err := CreateRGBSurfaceWithFormat(0, 2, 1, 0, PixelFormatXRGB8888)
+	require.NoError(t, err)
+	key := MapRGB(src.format, 0xFF, 0, 0xFF)
+	src.setPixel(0, 0, key)
+	src.setPixel(1, 0, MapRGB(src.format, 0xFF, 0xFF, 0xFF))
+
+	_, err = src.GetColorKey()
+	assert.Error(t, err)
+	require.NoError(t, src.SetColorKey(true, key))
+	k, err := src.GetColorKey()
+	require.NoError(t, err)
+	assert.Equal(t, key, k)
+	src.SetColorMod(0x80, 0xFF, 0x40)
+	r, g, b := src.GetColorMod()
+	assert.Equal(t, []uint8{0x80, 0xFF, 0x40}, []uint8{r, g, b})
+
+	dst, err := CreateRGBSurfaceWithFormat(0, 2, 1, 0, PixelFormatRGB565)
+	require.NoError(t, err)
+	require.NoError(t, BlitSurface(src, nil, dst, nil))
+	assert.Equal(t, uint32(0), dst.getPixel(0, 0))
+	assert.Equal(t, MapRGB(dst.format, 0x80, 0xFF, 0x40), dst.getPixel(1, 0))
+
+	require.NoError(t, src.SetColorKey(false, 0))
+	assert.False(t, src.HasColorKey())
+	src.SetColorMod(0xFF, 0xFF, 0xFF)
+	require.NoError(t, BlitSurface(src, nil, dst, nil))
+	assert.Equal(t, MapRGB(dst.format, 0xFF, 0, 0xFF), dst.getPixel(0, 0))
+
+	indexed, err := CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatIndex4LSB)
+	require.NoError(t, err)
+	assert.Error(t, indexed.SetColorKey(true, 16))
+
+	src, err = CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatARGB8888)
+	require.NoError(t, err)
+	src.SetAlphaMod(0x80)
+	assert.Equal(t, uint8(0x80), src.GetAlphaMod())
+	require.NoError(t, src.FillRect(nil, 0xFFFFFFFF))
+	dst, err = CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatXRGB8888)
+	require.NoError(t, err)
+	require.NoError(t, BlitSurface(src, nil, dst, nil))
+	r, g, b = GetRGB(dst.getPixel(0, 0), dst.format)
+	assert.Equal(t, []uint8{0x80, 0x80, 0x80}, []uint8{r, g, b})
+}
+
+func TestBlitMapInvalidation(t *testing.T) {
+	src, err := CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatIndex8)
+	require.NoError(t, err)
+	dst, err := CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatXRGB8888)
+	require.NoError(t, err)
+
+	require.NoError(t, BlitSurface(src, nil, dst, nil))
+	assert.Equal(t, MapRGB(dst.format, 0xFF, 0xFF, 0xFF), dst.getPixel(0, 0))
+	m := src.blitMap
+	assert.True(t, m.valid(src, dst))
+
+	require.NoError(t, SetPaletteColors(src.format.palette, []Color{{R: 0x10, G: 0x20, B: 0x30, A: 0xFF}}, 0))
+	assert.False(t, m.valid(src, dst))
+	require.NoError(t, BlitSurface(src, nil, dst, nil))
+	assert.Equal(t, MapRGB(dst.format, 0x10, 0x20, 0x30), dst.getPixel(0, 0))
+
+	p, err := AllocPalette(256)
+	require.NoError(t, err)
+	require.NoError(t, SetPixelFormatPalette(src.format, p))
+	assert.False(t, m.valid(src, dst))
+	require.NoError(t, BlitSurface(src, nil, dst, nil))
+	assert.Equal(t, MapRGB(dst.format, 0xFF, 0xFF, 0xFF), dst.getPixel(0, 0))
+
+	other, err := CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatRGB565)
+	require.NoError(t, err)
+	require.NoError(t, BlitSurface(src, nil, other, nil))
+	assert.False(t, m.valid(src, dst))
+	assert.True(t, m.valid(src, other))
+	assert.Equal(t, uint32(0xFFFF), other.getPixel(0, 0))
+}
+
+// blitPairs are the format pairs with fast paths, along with some that use
+// the generic path.
+var blitPairs = [][2]uint32{
+	{PixelFormatARGB8888, PixelFormatARGB8888},
+	{PixelFormatARGB8888, PixelFormatXRGB8888},
+	{PixelFormatABGR8888, PixelFormatARGB8888},
+	{PixelFormatRGBA8888, PixelFormatBGRX8888},
+	{PixelFormatXRGB8888, PixelFormatABGR8888},
+	{PixelFormatIndex8, PixelFormatXRGB8888},
+	{PixelFormatIndex8, PixelFormatRGB565},
+	{PixelFormatIndex4MSB, PixelFormatARGB8888},
+	{PixelFormatRGB565, PixelFormatRGB565},
+	{PixelFormatRGB565, PixelFormatXRGB8888},
+	{PixelFormatRGB24, PixelFormatARGB4444},
+}
+
+type blitSetup struct {
+	name string
+	set  func(s *Surface)
+}
+
+var blitSetups = []blitSetup{
+	{"none", func(s *Surface) { s.SetBlendMode(BlendModeNone) }},
+	{"blend", func(s *Surface) { s.SetBlendMode(BlendModeBlend) }},
+	{"add", func(s *Surface) { s.SetBlendMode(BlendModeAdd) }},
+	{"mod", func(s *Surface) { s.SetBlendMode(BlendModeMod) }},
+	{"mul", func(s *Surface) { s.SetBlendMode(BlendModeMul) }},
+	{"colorkey", func(s *Surface) { s.SetColorKey(true, s.getPixel(1, 1)) }},
+	{"colormod", func(s *Surface) { s.SetColorMod(0x20, 0x80, 0xF0) }},
+	{"alphamod", func(s *Surface) {
+		s.SetAlphaMod(0x70)
+		s.SetBlendMode(BlendModeBlend)
+	}},
+}
+
+// TestBlitFastPaths checks every blit function against the generic one.
+func TestBlitFastPaths(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, pair := range blitPairs {
+		for _, setup := range blitSetups {
+			name := fmt.Sprintf("%s->%s/%s", GetPixelFormatName(pair[0]), GetPixelFormatName(pair[1]), setup.name)
+			t.Run(name, func(t *testing.T) {
+				src := newRandomSurface(t, rng, 13, 7, pair[0])
+				setup.set(src)
+				dst := newRandomSurface(t, rng, 17, 9, pair[1])
+				want := append([]byte(nil), dst.pixels...)
+
+				srcRect := Rect{X: 1, Y: 1, W: 11, H: 5}
+				require.NoError(t, BlitSurface(src, &srcRect, dst, &Rect{X: 3, Y: 2}))
+
+				generic, err := CreateRGBSurfaceWithFormatFrom(want, dst.w, dst.h, 0, dst.pitch, pair[1])
+				require.NoError(t, err)
+				generic.format.palette = dst.format.palette
+				require.NoError(t, src.blitMap.mapSurface(src, generic))
+				src.blitMap.blit = blitGeneric
+				require.NoError(t, BlitSurface(src, &srcRect, generic, &Rect{X: 3, Y: 2}))
+				assertSamePixels(t, generic, dst)
+			})
+		}
+	}
+}
+
+func BenchmarkBlit(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	for _, pair := range blitPairs {
+		for _, setup := range blitSetups[:2] {
+			for _, generic := range []bool{false, true} {
+				path := "fast"
+				if generic {
+					path = "generic"
+				}
+				name := fmt.Sprintf("%s->%s/%s/%s", GetPixelFormatName(pair[0]), GetPixelFormatName(pair[1]), setup.name, path)
+				b.Run(name, func(b *testing.B) {
+					src := newRandomSurface(b, rng, 256, 256, pair[0])
+					setup.set(src)
+					dst := newRandomSurface(b, rng, 256, 256, pair[1])
+					require.NoError(b, BlitSurface(src, nil, dst, nil))
+					if generic {
+						src.blitMap.blit = blitGeneric
+					}
+					b.SetBytes(256 * 256 * 4)
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						BlitSurface(src, nil, dst, nil)
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package video
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/elliotmr/gdl/rwops"
+	"github.com/pkg/errors"
+)
+
+// BMP compression types
+const (
+	biRGB            = 0
+	biRLE8           = 1
+	biRLE4           = 2
+	biBitfields      = 3
+	biAlphaBitfields = 6
+)
+
+const (
+	bmpFileHeaderSize = 14
+	bmpInfoHeaderSize = 40
+	bmpV4HeaderSize   = 108
+	bmpCoreHeaderSize = 12
+
+	// bmpMaxSize is the largest width or height loaded, so a corrupt header
+	// can't ask for a huge surface
+	bmpMaxSize = 1 << 16
+
+	lcsWindowsColorSpace = 0x57696E20 // 'Win '
+)
+
+// LoadBMP loads a surface from a Windows BMP file.
+func LoadBMP(file string) (*Surface, error) {
+	src, err := rwops.FromFile(file, "rb")
+	if err != nil {
+		return nil, err
+	}
+	return LoadBMPRW(src, true)
+}
+
+// LoadBMPRW loads a surface from a BMP image in the stream, closing it when
+// freeSrc is set. 1, 4 and 8 bit images load into indexed surfaces with
+// their palette, 32 bit images keep their alpha channel.
+func LoadBMPRW(src *rwops.RWops, freeSrc bool) (*Surface, error) {
+	if src == nil {
+		return nil, errors.New("invalid stream")
+	}
+	if freeSrc {
+		defer src.Close()
+	}
+	start, err := src.Tell()
+	if err != nil {
+		return nil, err
+	}
+
+	fileHeader := make([]byte, bmpFileHeaderSize+4)
+	if _, err := io.ReadFull(src, fileHeader); err != nil {
+		return nil, errors.Wrap(err, "unable to read BMP header")
+	}
+	if fileHeader[0] != 'B' || fileHeader[1] != 'M' {
+		return nil, errors.New("file is not a Windows BMP file")
+	}
+	offBits := binary.LittleEndian.Uint32(fileHeader[10:])
+	headerSize := binary.LittleEndian.Uint32(fileHeader[14:])
+	if headerSize != bmpCoreHeaderSize && (headerSize < bmpInfoHeaderSize || headerSize > 1024) {
+		return nil, errors.Errorf("invalid BMP header size (%d)", headerSize)
+	}
+	h := make([]byte, headerSize)
+	if _, err := io.ReadFull(src, h[4:]); err != nil {
+		return nil, errors.Wrap(err, "unable to read BMP header")
+	}
+
+	var (
+		width, height, bpp int
+		compression, used  uint32
+		rMask, gMask       uint32
+		bMask, aMask       uint32
+		entrySize          = 4
+	)
+	le := binary.LittleEndian
+	if headerSize == bmpCoreHeaderSize {
+		width, height = int(le.Uint16(h[4:])), int(le.Uint16(h[6:]))
+		bpp = int(le.Uint16(h[10:]))
+		entrySize = 3
+	} else {
+		width, height = int(int32(le.Uint32(h[4:]))), int(int32(le.Uint32(h[8:])))
+		bpp = int(le.Uint16(h[14:]))
+		compression = le.Uint32(h[16:])
+		used = le.Uint32(h[32:])
+		if headerSize >= 52 {
+			rMask, gMask, bMask = le.Uint32(h[40:]), le.Uint32(h[44:]), le.Uint32(h[48:])
+		}
+		if headerSize >= 56 {
+			aMask = le.Uint32(h[52:])
+		}
+	}
+
+	switch compression {
+	case biRGB:
+		rMask, gMask, bMask, aMask = 0, 0, 0, 0
+	case biBitfields, biAlphaBitfields:
+		if bpp != 16 && bpp != 32 {
+			return nil, errors.Errorf("invalid BMP bit fields for %d bits per pixel", bpp)
+		}
+		if headerSize < 52 {
+			// the masks follow a short header
+			n := 12
+			if compression == biAlphaBitfields {
+				n = 16
+			}
+			masks := make([]byte, 16)
+			if _, err := io.ReadFull(src, masks[:n]); err != nil {
+				return nil, errors.Wrap(err, "unable to read BMP bit fields")
+			}
+			rMask, gMask, bMask, aMask = le.Uint32(masks), le.Uint32(masks[4:]), le.Uint32(masks[8:]), le.Uint32(masks[12:])
+		}
+		if compression == biBitfields && headerSize < 56 {
+			aMask = 0
+		}
+	case biRLE8, biRLE4:
+		return nil, errors.New("compressed BMP files not supported")
+	default:
+		return nil, errors.Errorf("unknown BMP compression (%d)", compression)
+	}
+
+	topDown := height < 0
+	if topDown {
+		height = -height
+	}
+	if width <= 0 || height == 0 {
+		return nil, errors.Errorf("invalid BMP size %dx%d", width, height)
+	}
+	if width > bmpMaxSize || height > bmpMaxSize {
+		return nil, errors.Errorf("BMP size %dx%d is too large", width, height)
+	}
+
+	var format uint32
+	switch bpp {
+	case 1, 4, 8:
+		format = MasksToPixelFormatEnum(bpp, 0, 0, 0, 0)
+	case 16:
+		if compression == biRGB {
+			rMask, gMask, bMask = 0x7C00, 0x03E0, 0x001F
+		}
+		format = MasksToPixelFormatEnum(bpp, rMask, gMask, bMask, aMask)
+	case 24:
+		format = MasksToPixelFormatEnum(bpp, 0, 0, 0, 0)
+	case 32:
+		if compression == biRGB {
+			rMask, gMask, bMask, aMask = 0x00FF0000, 0x0000FF00, 0x000000FF, 0xFF000000
+		}
+		format = MasksToPixelFormatEnum(bpp, rMask, gMask, bMask, aMask)
+	default:
+		return nil, errors.Errorf("unsupported BMP depth (%d)", bpp)
+	}
+	if format == PixelFormatUnknown {
+		return nil, errors.Errorf("unsupported BMP bit fields (%08x %08x %08x %08x)", rMask, gMask, bMask, aMask)
+	}
+
+	s, err := CreateRGBSurfaceWithFormat(0, width, height, bpp, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if p := s.format.palette; p != nil {
+		max := uint32(1) << uint(bpp)
+		if used == 0 {
+			used = max
+		}
+		if used > max {
+			FreeSurface(s)
+			return nil, errors.Errorf("invalid BMP palette size (%d)", used)
+		}
+		entries := make([]byte, int(used)*entrySize)
+		if _, err := io.ReadFull(src, entries); err != nil {
+			FreeSurface(s)
+			return nil, errors.Wrap(err, "unable to read BMP palette")
+		}
+		colors := make([]Color, used)
+		for i := range colors {
+			e := entries[i*entrySize:]
+			colors[i] = Color{R: e[2], G: e[1], B: e[0], A: 0xFF}
+		}
+		if err := SetPaletteColors(p, colors, 0); err != nil {
+			FreeSurface(s)
+			return nil, err
+		}
+	}
+
+	if _, err := src.Seek(start+int64(offBits), io.SeekStart); err != nil {
+		FreeSurface(s)
+		return nil, errors.Wrap(err, "unable to seek to BMP pixels")
+	}
+	rowSize := ((width*bpp + 31) / 32) * 4
+	row := make([]byte, rowSize)
+	n := minPitch(format, width)
+	for i := 0; i < height; i++ {
+		if _, err := io.ReadFull(src, row); err != nil {
+			FreeSurface(s)
+			return nil, errors.Wrap(err, "unable to read BMP pixels")
+		}
+		y := height - 1 - i
+		if topDown {
+			y = i
+		}
+		copyBMPRow(s.pixels[y*s.pitch:y*s.pitch+n], row, s.format.bytesPerPixel)
+	}
+
+	if compression == biRGB && bpp == 32 {
+		fixBMPAlpha(s)
+	}
+	return s, nil
+}
+
+// copyBMPRow copies a row of pixels between the little endian order of a
+// BMP file and the host order of a surface, the swap works both ways.
+func copyBMPRow(dst, src []byte, bytesPerPixel uint8) {
+	if !bigEndian || bytesPerPixel < 2 {
+		copy(dst, src)
+		return
+	}
+	bpp := int(bytesPerPixel)
+	for x := 0; x+bpp <= len(dst); x += bpp {
+		var v uint32
+		for i := bpp - 1; i >= 0; i-- {
+			v = v<<8 | uint32(src[x+i])
+		}
+		putPixel(dst[x:], bpp, v)
+	}
+}
+
+// fixBMPAlpha makes a 32 bit image opaque if the unused byte is zero for
+// every pixel, as many writers leave it.
+func fixBMPAlpha(s *Surface) {
+	for y := 0; y < s.h; y++ {
+		for x := 0; x < s.w; x++ {
+			if s.getPixel(x, y)&s.format.aMask != 0 {
+				return
+			}
+		}
+	}
+	for y := 0; y < s.h; y++ {
+		for x := 0; x < s.w; x++ {
+			s.setPixel(x, y, s.getPixel(x, y)|s.format.aMask)
+		}
+	}
+}
+
+// SaveBMP saves a surface to a Windows BMP file.
+func SaveBMP(surface *Surface, file string) error {
+	dst, err := rwops.FromFile(file, "wb")
+	if err != nil {
+		return err
+	}
+	return SaveBMPRW(surface, dst, true)
+}
+
+// SaveBMPRW writes a surface to the stream as a BMP image, closing it when
+// freeDst is set. Indexed surfaces are saved with their palette, surfaces
+// with alpha as 32 bit and everything else as 24 bit.
+func SaveBMPRW(surface *Surface, dst *rwops.RWops, freeDst bool) error {
+	if dst == nil {
+		return errors.New("invalid stream")
+	}
+	if freeDst {
+		defer dst.Close()
+	}
+	if surface == nil || surface.format == nil {
+		return errors.New("invalid surface")
+	}
+
+	// pick the format to save and convert to it if needed
+	f := surface.format
+	var target uint32
+	switch {
+	case f.format == PixelFormatIndex1LSB:
+		target = PixelFormatIndex1MSB
+	case f.format == PixelFormatIndex4LSB:
+		target = PixelFormatIndex4MSB
+	case IsPixelFormatIndexed(f.format):
+		target = f.format
+	case f.aMask != 0:
+		target = PixelFormatARGB8888
+	default:
+		target = MasksToPixelFormatEnum(24, 0, 0, 0, 0)
+	}
+	s := surface
+	if target != f.format {
+		var err error
+		s, err = ConvertSurfaceFormat(surface, target, 0)
+		if err != nil {
+			return errors.Wrap(err, "unable to convert surface")
+		}
+		defer FreeSurface(s)
+	}
+	if err := s.Lock(); err != nil {
+		return err
+	}
+	defer s.Unlock()
+
+	bpp := int(s.format.bitsPerPixel)
+	if bpp > 8 {
+		bpp = int(s.format.bytesPerPixel) * 8
+	}
+	var colors []Color
+	if p := s.format.palette; p != nil {
+		colors = p.colors
+		if len(colors) > 1<<uint(bpp) {
+			colors = colors[:1<<uint(bpp)]
+		}
+	}
+	infoSize := bmpInfoHeaderSize
+	compression := uint32(biRGB)
+	if bpp == 32 {
+		infoSize = bmpV4HeaderSize
+		compression = biBitfields
+	}
+	rowSize := ((s.w*bpp + 31) / 32) * 4
+	offBits := bmpFileHeaderSize + infoSize + len(colors)*4
+	imageSize := rowSize * s.h
+
+	le := binary.LittleEndian
+	h := make([]byte, offBits)
+	h[0], h[1] = 'B', 'M'
+	le.PutUint32(h[2:], uint32(offBits+imageSize))
+	le.PutUint32(h[10:], uint32(offBits))
+	info := h[bmpFileHeaderSize:]
+	le.PutUint32(info, uint32(infoSize))
+	le.PutUint32(info[4:], uint32(s.w))
+	le.PutUint32(info[8:], uint32(s.h))
+	le.PutUint16(info[12:], 1)
+	le.PutUint16(info[14:], uint16(bpp))
+	le.PutUint32(info[16:], compression)
+	le.PutUint32(info[20:], uint32(imageSize))
+	le.PutUint32(info[32:], uint32(len(colors)))
+	if infoSize == bmpV4HeaderSize {
+		le.PutUint32(info[40:], s.format.rMask)
+		le.PutUint32(info[44:], s.format.gMask)
+		le.PutUint32(info[48:], s.format.bMask)
+		le.PutUint32(info[52:], s.format.aMask)
+		le.PutUint32(info[56:], lcsWindowsColorSpace)
+	}
+	for i, c := range colors {
+		e := info[infoSize+i*4:]
+		e[0], e[1], e[2] = c.B, c.G, c.R
+	}
+	if _, err := dst.Write(h); err != nil {
+		return errors.Wrap(err, "unable to write BMP header")
+	}
+
+	row := make([]byte, rowSize)
+	n := minPitch(s.format.format, s.w)
+	for y := s.h - 1; y >= 0; y-- {
+		copyBMPRow(row[:n], s.pixels[y*s.pitch:y*s.pitch+n], s.format.bytesPerPixel)
+		if _, err := dst.Write(row); err != nil {
+			return errors.Wrap(err, "unable to write BMP pixels")
+		}
+	}
+	return nil
+}
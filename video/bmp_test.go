@@ -0,0 +1,255 @@
+package video
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/elliotmr/gdl/rwops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bmpImage describes a BMP file for buildBMP, the rows are top to bottom
+// and written in the order the sign of height asks for.
+type bmpImage struct {
+	headerSize    int
+	width, height int
+	bpp           int
+	compression   uint32
+	masks         []uint32
+	palette       []Color
+	rows          [][]byte
+}
+
+func buildBMP(img bmpImage) []byte {
+	le := binary.LittleEndian
+	header := make([]byte, img.headerSize)
+	le.PutUint32(header, uint32(img.headerSize))
+	entrySize := 4
+	if img.headerSize == bmpCoreHeaderSize {
+		le.PutUint16(header[4:], uint16(img.width))
+		le.PutUint16(header[6:], uint16(img.height))
+		le.PutUint16(header[8:], 1)
+		le.PutUint16(header[10:], uint16(img.bpp))
+		entrySize = 3
+	} else {
+		le.PutUint32(header[4:], uint32(int32(img.width)))
+		le.PutUint32(header[8:], uint32(int32(img.height)))
+		le.PutUint16(header[12:], 1)
+		le.PutUint16(header[14:], uint16(img.bpp))
+		le.PutUint32(header[16:], img.compression)
+		le.PutUint32(header[32:], uint32(len(img.palette)))
+	}
+	var extra []byte
+	for i, m := range img.masks {
+		if img.headerSize > 40 {
+			le.PutUint32(header[40+i*4:], m)
+		} else {
+			extra = le.AppendUint32(extra, m)
+		}
+	}
+	for _, c := range img.palette {
+		extra = append(extra, c.B, c.G, c.R)
+		if entrySize == 4 {
+			extra = append(extra, 0)
+		}
+	}
+
+	var pixels []byte
+	rowSize := ((img.width*img.bpp + 31) / 32) * 4
+	for i := range img.rows {
+		row := img.rows[len(img.rows)-1-i]
+		if img.height < 0 {
+			row = img.rows[i]
+		}
+		padded := make([]byte, rowSize)
+		copy(padded, row)
+		pixels = append(pixels, padded...)
+	}
+
+	offBits := bmpFileHeaderSize + len(header) + len(extra)
+	file := []byte{'B', 'M'}
+	file = le.AppendUint32(file, uint32(offBits+len(pixels)))
+	file = le.AppendUint32(file, 0)
+	file = le.AppendUint32(file, uint32(offBits))
+	file = append(file, header...)
+	file = append(file, extra...)
+	return append(file, pixels...)
+}
+
+func loadBMPBytes(t *testing.T, data []byte) *Surface {
+	s, err := LoadBMPRW(rwops.FromConstMem(data), true)
+	require.NoError(t, err)
+	return s
+}
+
+func surfaceRGBA(s *Surface) [][4]uint8 {
+	var out [][4]uint8
+	for y := 0; y < s.h; y++ {
+		for x := 0; x < s.w; x++ {
+			r, g, b, a := GetRGBA(s.getPixel(x, y), s.format)
+			out = append(out, [4]uint8{r, g, b, a})
+		}
+	}
+	return out
+}
+
+func TestLoadBMPIndexed(t *testing.T) {
+	palette := []Color{{R: 0x10, G: 0x20, B: 0x30}, {R: 0xF0, G: 0xE0, B: 0xD0}, {R: 0xFF}}
+
+	s := loadBMPBytes(t, buildBMP(bmpImage{
+		headerSize: 40, width: 10, height: 2, bpp: 1, palette: palette[:2],
+		rows: [][]byte{{0xA0, 0x40}, {0x01, 0xC0}},
+	}))
+	assert.Equal(t, uint32(PixelFormatIndex1MSB), s.format.format)
+	assert.Equal(t, Color{R: 0xF0, G: 0xE0, B: 0xD0, A: 0xFF}, s.format.palette.colors[1])
+	var got []uint32
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 10; x++ {
+			got = append(got, s.getPixel(x, y))
+		}
+	}
+	assert.Equal(t, []uint32{1, 0, 1, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1}, got)
+
+	s = loadBMPBytes(t, buildBMP(bmpImage{
+		headerSize: bmpCoreHeaderSize, width: 3, height: 1, bpp: 4, palette: make([]Color, 16),
+		rows: [][]byte{{0x12, 0xF0}},
+	}))
+	assert.Equal(t, uint32(PixelFormatIndex4MSB), s.format.format)
+	assert.Equal(t, []uint32{1, 2, 15}, []uint32{s.getPixel(0, 0), s.getPixel(1, 0), s.getPixel(2, 0)})
+
+	s = loadBMPBytes(t, buildBMP(bmpImage{
+		headerSize: 40, width: 3, height: -2, bpp: 8, palette: palette,
+		rows: [][]byte{{0, 1, 2}, {2, 1, 0}},
+	}))
+	assert.Equal(t, uint32(PixelFormatIndex8), s.format.format)
+	assert.Equal(t, []byte{0, 1, 2}, s.pixels[:3])
+	assert.Equal(t, []byte{2, 1, 0}, s.pixels[s.pitch:s.pitch+3])
+	assert.Equal(t, [4]uint8{0xFF, 0, 0, 0xFF}, surfaceRGBA(s)[2])
+}
+
+func TestLoadBMPTrueColor(t *testing.T) {
+	// 16 bit defaults to 555
+	s := loadBMPBytes(t, buildBMP(bmpImage{
+		headerSize: 40, width: 2, height: 1, bpp: 16,
+		rows: [][]byte{{0x00, 0x7C, 0x1F, 0x00}},
+	}))
+	assert.Equal(t, uint32(PixelFormatXRGB1555), s.format.format)
+	assert.Equal(t, [][4]uint8{{0xFF, 0, 0, 0xFF}, {0, 0, 0xFF, 0xFF}}, surfaceRGBA(s))
+
+	// 565 bit fields after a short header
+	s = loadBMPBytes(t, buildBMP(bmpImage{
+		headerSize: 40, width: 1, height: 1, bpp: 16, compression: biBitfields,
+		masks: []uint32{0xF800, 0x07E0, 0x001F},
+		rows:  [][]byte{{0xE0, 0x07}},
+	}))
+	assert.Equal(t, uint32(PixelFormatRGB565), s.format.format)
+	assert.Equal(t, [][4]uint8{{0, 0xFF, 0, 0xFF}}, surfaceRGBA(s))
+
+	// 24 bit is stored as BGR
+	s = loadBMPBytes(t, buildBMP(bmpImage{
+		headerSize: 40, width: 2, height: 2, bpp: 24,
+		rows: [][]byte{{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, {0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C}},
+	}))
+	assert.Equal(t, [][4]uint8{{3, 2, 1, 0xFF}, {6, 5, 4, 0xFF}, {9, 8, 7, 0xFF}, {12, 11, 10, 0xFF}}, surfaceRGBA(s))
+
+	// 32 bit keeps the alpha channel
+	s = loadBMPBytes(t, buildBMP(bmpImage{
+		headerSize: 40, width: 2, height: 1, bpp: 32,
+		rows: [][]byte{{0x01, 0x02, 0x03, 0x80, 0x04, 0x05, 0x06, 0x00}},
+	}))
+	assert.Equal(t, uint32(PixelFormatARGB8888), s.format.format)
+	assert.Equal(t, [][4]uint8{{3, 2, 1, 0x80}, {6, 5, 4, 0}}, surfaceRGBA(s))
+	assert.Equal(t, uint32(BlendModeBlend), s.GetBlendMode())
+
+	// unless it is unused everywhere
+	s = loadBMPBytes(t, buildBMP(bmpImage{
+		headerSize: 40, width: 2, height: 1, bpp: 32,
+		rows: [][]byte{{0x01, 0x02, 0x03, 0x00, 0x04, 0x05, 0x06, 0x00}},
+	}))
+	assert.Equal(t, [][4]uint8{{3, 2, 1, 0xFF}, {6, 5, 4, 0xFF}}, surfaceRGBA(s))
+
+	// masks in a V4 header, top down
+	s = loadBMPBytes(t, buildBMP(bmpImage{
+		headerSize: bmpV4HeaderSize, width: 1, height: -2, bpp: 32, compression: biBitfields,
+		masks: []uint32{0x000000FF, 0x0000FF00, 0x00FF0000, 0xFF000000},
+		rows:  [][]byte{{0x01, 0x02, 0x03, 0x04}, {0x05, 0x06, 0x07, 0x08}},
+	}))
+	assert.Equal(t, uint32(PixelFormatABGR8888), s.format.format)
+	assert.Equal(t, [][4]uint8{{1, 2, 3, 4}, {5, 6, 7, 8}}, surfaceRGBA(s))
+
+	// alpha bit fields after a short header
+	s = loadBMPBytes(t, buildBMP(bmpImage{
+		headerSize: 40, width: 1, height: 1, bpp: 16, compression: biAlphaBitfields,
+		masks: []uint32{0x0F00, 0x00F0, 0x000F, 0xF000},
+		rows:  [][]byte{{0x34, 0x12}},
+	}))
+	assert.Equal(t, uint32(PixelFormatARGB4444), s.format.format)
+	assert.Equal(t, [][4]uint8{{0x22, 0x33, 0x44, 0x11}}, surfaceRGBA(s))
+}
+
+func TestLoadBMPErrors(t *testing.T) {
+	valid := buildBMP(bmpImage{headerSize: 40, width: 2, height: 2, bpp: 24, rows: [][]byte{{}, {}}})
+	for name, data := range map[string][]byte{
+		"empty":       nil,
+		"magic":       append([]byte("XM"), valid[2:]...),
+		"truncated":   valid[:len(valid)-1],
+		"rle":         buildBMP(bmpImage{headerSize: 40, width: 1, height: 1, bpp: 8, compression: biRLE8}),
+		"depth":       buildBMP(bmpImage{headerSize: 40, width: 1, height: 1, bpp: 2, rows: [][]byte{{}}}),
+		"bit fields":  buildBMP(bmpImage{headerSize: 40, width: 1, height: 1, bpp: 32, compression: biBitfields, masks: []uint32{1, 2, 4}}),
+		"size":        buildBMP(bmpImage{headerSize: 40, width: 0, height: 1, bpp: 24}),
+		"palette":     buildBMP(bmpImage{headerSize: 40, width: 1, height: 1, bpp: 1, palette: make([]Color, 3)}),
+		"huge":        buildBMP(bmpImage{headerSize: 40, width: 0x7FFFFFFF, height: 0x7FFFFFFF, bpp: 32}),
+		"too wide":    buildBMP(bmpImage{headerSize: 40, width: bmpMaxSize + 1, height: -1, bpp: 8}),
+		"header size": append(append(append([]byte(nil), valid[:14]...), 20, 0, 0, 0), valid[18:]...),
+	} {
+		_, err := LoadBMPRW(rwops.FromConstMem(data), true)
+		assert.Error(t, err, name)
+	}
+	_, err := LoadBMPRW(nil, true)
+	assert.Error(t, err)
+}
+
+func TestSaveBMP(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	for _, format := range pixelFormats {
+		t.Run(GetPixelFormatName(format), func(t *testing.T) {
+			src := newRandomSurface(t, rng, 13, 5, format)
+			if p := src.format.palette; p != nil {
+				colors := make([]Color, p.NumColors())
+				for i := range colors {
+					colors[i] = Color{R: uint8(rng.Intn(256)), G: uint8(rng.Intn(256)), B: uint8(rng.Intn(256)), A: 0xFF}
+				}
+				require.NoError(t, SetPaletteColors(p, colors, 0))
+			}
+
+			file := filepath.Join(t.TempDir(), "image.bmp")
+			require.NoError(t, SaveBMP(src, file))
+			loaded, err := LoadBMP(file)
+			require.NoError(t, err)
+			defer FreeSurface(loaded)
+
+			assert.Equal(t, IsPixelFormatIndexed(format), IsPixelFormatIndexed(loaded.format.format))
+			assert.Equal(t, src.format.aMask != 0, loaded.format.aMask != 0)
+			want, err := ConvertSurface(src, loaded.format, 0)
+			require.NoError(t, err)
+			assert.Equal(t, surfaceRGBA(want), surfaceRGBA(loaded))
+		})
+	}
+
+	// locked surfaces are saved as they are
+	src, err := CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatBGR24)
+	require.NoError(t, err)
+	require.NoError(t, src.Lock())
+	mem := make([]byte, 58)
+	require.NoError(t, SaveBMPRW(src, rwops.FromMem(mem), true))
+	assert.Equal(t, []byte{'B', 'M', 58, 0, 0, 0}, mem[:6])
+	src.Unlock()
+
+	assert.Error(t, SaveBMPRW(src, rwops.FromMem(make([]byte, 10)), true))
+	assert.Error(t, SaveBMPRW(nil, rwops.FromMem(mem), true))
+	FreeSurface(src)
+	assert.Error(t, SaveBMPRW(src, rwops.FromMem(mem), true))
+}
@@ -0,0 +1,192 @@
+package video
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ConvertSurface copies src into a new surface with the given format, the
+// flags are unused and should be 0. The color key, modulation and blend
+// mode of src carry over to the new surface. When a surface without alpha
+// is converted to one with alpha, the color keyed pixels become transparent
+// instead.
+func ConvertSurface(src *Surface, format *PixelFormat, flags uint32) (*Surface, error) {
+	if src == nil || src.format == nil {
+		return nil, errors.New("invalid surface")
+	}
+	if format == nil {
+		return nil, errors.New("invalid pixel format")
+	}
+	dst, err := CreateRGBSurfaceWithFormat(flags, src.w, src.h, 0, format.format)
+	if err != nil {
+		return nil, err
+	}
+	if p := dst.format.palette; p != nil {
+		switch {
+		case format.palette != nil:
+			err = SetPaletteColors(p, format.palette.colors, 0)
+		case src.format.palette != nil:
+			err = SetPaletteColors(p, src.format.palette.colors, 0)
+		}
+		if err != nil {
+			FreeSurface(dst)
+			return nil, errors.Wrap(err, "unable to copy palette")
+		}
+	}
+
+	// copy the pixels as they are, without any of the blit settings
+	m := src.blitMap
+	saved := m.info
+	m.info.flags = 0
+	m.invalidate()
+	err = BlitSurface(src, nil, dst, nil)
+	m.info = saved
+	m.invalidate()
+	if err != nil {
+		FreeSurface(dst)
+		return nil, err
+	}
+
+	dst.SetColorMod(saved.r, saved.g, saved.b)
+	dst.SetAlphaMod(saved.a)
+	dst.SetBlendMode(src.GetBlendMode())
+	if saved.flags&copyColorKey > 0 {
+		convertColorKey(src, dst, saved.colorKey)
+	}
+	return dst, nil
+}
+
+// convertColorKey moves the color key of src to its converted copy dst.
+func convertColorKey(src, dst *Surface, key uint32) {
+	r, g, b, _ := GetRGBA(key, src.format)
+	if dst.format.aMask == 0 {
+		dst.SetColorKey(true, MapRGB(dst.format, r, g, b))
+		return
+	}
+
+	// the destination has an alpha channel, make the keyed pixels transparent,
+	// ignoring the source alpha like the blitter does
+	rgbMask := ^src.format.aMask
+	if src.format.palette != nil {
+		rgbMask = ^uint32(0)
+	}
+	key &= rgbMask
+	transparent := MapRGBA(dst.format, r, g, b, 0)
+	for y := 0; y < src.h; y++ {
+		srcRow := src.pixels[y*src.pitch:]
+		dstRow := dst.pixels[y*dst.pitch:]
+		for x := 0; x < src.w; x++ {
+			if readPixel(srcRow, x, src.format)&rgbMask == key {
+				writePixel(dstRow, x, dst.format, transparent)
+			}
+		}
+	}
+	dst.SetBlendMode(BlendModeBlend)
+}
+
+// ConvertSurfaceFormat is ConvertSurface with a pixel format enum.
+func ConvertSurfaceFormat(src *Surface, pixelFormat uint32, flags uint32) (*Surface, error) {
+	format, err := AllocFormat(pixelFormat)
+	if err != nil {
+		return nil, err
+	}
+	defer FreeFormat(format)
+	return ConvertSurface(src, format, flags)
+}
+
+// wrapPixels creates a surface around a block of pixels for the conversion
+// functions, which don't support indexed formats.
+func wrapPixels(width, height int, format uint32, pixels []byte, pitch int) (*Surface, error) {
+	if IsPixelFormatIndexed(format) {
+		return nil, errors.New("indexed pixel formats not supported")
+	}
+	return CreateRGBSurfaceWithFormatFrom(pixels, width, height, 0, pitch, format)
+}
+
+// ConvertPixels copies a block of pixels from one format to another. YUV
+// pixels can be converted to RGB, with the YUV conversion mode for the size,
+// or copied to the same YUV format.
+func ConvertPixels(width, height int, srcFormat uint32, src []byte, srcPitch int, dstFormat uint32, dst []byte, dstPitch int) error {
+	if IsPixelFormatFourCC(srcFormat) || IsPixelFormatFourCC(dstFormat) {
+		return convertYUV(width, height, srcFormat, src, srcPitch, dstFormat, dst, dstPitch)
+	}
+	s, err := wrapPixels(width, height, srcFormat, src, srcPitch)
+	if err != nil {
+		return errors.Wrap(err, "invalid source")
+	}
+	defer FreeSurface(s)
+	d, err := wrapPixels(width, height, dstFormat, dst, dstPitch)
+	if err != nil {
+		return errors.Wrap(err, "invalid destination")
+	}
+	defer FreeSurface(d)
+
+	if err := s.SetBlendMode(BlendModeNone); err != nil {
+		return err
+	}
+	return BlitSurface(s, nil, d, nil)
+}
+
+// PremultiplyAlpha copies a block of pixels from one format to another,
+// multiplying the color channels by alpha. The source and destination can
+// be the same block of pixels.
+func PremultiplyAlpha(width, height int, srcFormat uint32, src []byte, srcPitch int, dstFormat uint32, dst []byte, dstPitch int) error {
+	return convertAlpha(width, height, srcFormat, src, srcPitch, dstFormat, dst, dstPitch, premultiply)
+}
+
+// UnpremultiplyAlpha is the inverse of PremultiplyAlpha, dividing the color
+// channels by alpha.
+func UnpremultiplyAlpha(width, height int, srcFormat uint32, src []byte, srcPitch int, dstFormat uint32, dst []byte, dstPitch int) error {
+	return convertAlpha(width, height, srcFormat, src, srcPitch, dstFormat, dst, dstPitch, unpremultiply)
+}
+
+// premultiply returns round(c * a / 255).
+func premultiply(c, a uint32) uint32 {
+	v := c*a + 128
+	return (v + v>>8) >> 8
+}
+
+// unpremultiply returns round(c * 255 / a).
+func unpremultiply(c, a uint32) uint32 {
+	if a == 0 {
+		return 0
+	}
+	return min((c*255+a/2)/a, 255)
+}
+
+func convertAlpha(width, height int, srcFormat uint32, src []byte, srcPitch int, dstFormat uint32, dst []byte, dstPitch int, f func(c, a uint32) uint32) error {
+	s, err := wrapPixels(width, height, srcFormat, src, srcPitch)
+	if err != nil {
+		return errors.Wrap(err, "invalid source")
+	}
+	defer FreeSurface(s)
+	d, err := wrapPixels(width, height, dstFormat, dst, dstPitch)
+	if err != nil {
+		return errors.Wrap(err, "invalid destination")
+	}
+	defer FreeSurface(d)
+
+	sf, df := s.format, d.format
+	if is8888(sf) && is8888(df) {
+		sc, dc := newChannels8888(sf), newChannels8888(df)
+		for y := 0; y < height; y++ {
+			srcRow := src[y*srcPitch : y*srcPitch+width*4]
+			dstRow := dst[y*dstPitch:]
+			for x := 0; x < len(srcRow); x += 4 {
+				r, g, b, a := sc.unpack(load32(srcRow[x:]))
+				store32(dstRow[x:], dc.pack(f(r, a), f(g, a), f(b, a), a))
+			}
+		}
+		return nil
+	}
+
+	for y := 0; y < height; y++ {
+		srcRow := src[y*srcPitch:]
+		dstRow := dst[y*dstPitch:]
+		for x := 0; x < width; x++ {
+			r, g, b, a := GetRGBA(readPixel(srcRow, x, sf), sf)
+			c := MapRGBA(df, uint8(f(uint32(r), uint32(a))), uint8(f(uint32(g), uint32(a))), uint8(f(uint32(b), uint32(a))), a)
+			writePixel(dstRow, x, df, c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package video
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// referenceConvert converts one pixel at a time through 8 bit RGBA, pixels
+// of the same format are copied as they are.
+func referenceConvert(width, height int, srcFormat uint32, src []byte, srcPitch int, dstFormat uint32, dst []byte, dstPitch int) {
+	sf, _ := AllocFormat(srcFormat)
+	df, _ := AllocFormat(dstFormat)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := readPixel(src[y*srcPitch:], x, sf)
+			if srcFormat != dstFormat {
+				r, g, b, a := GetRGBA(p, sf)
+				p = MapRGBA(df, r, g, b, a)
+			}
+			writePixel(dst[y*dstPitch:], x, df, p)
+		}
+	}
+}
+
+func TestConvertPixels(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	const width, height = 7, 3
+	for _, sf := range pixelFormats {
+		if IsPixelFormatIndexed(sf) {
+			continue
+		}
+		srcPitch := minPitch(sf, width) + 3
+		src := make([]byte, srcPitch*height)
+		rng.Read(src)
+		for _, df := range pixelFormats {
+			if IsPixelFormatIndexed(df) {
+				continue
+			}
+			dstPitch := minPitch(df, width) + 1
+			got := make([]byte, dstPitch*height)
+			want := make([]byte, dstPitch*height)
+			require.NoError(t, ConvertPixels(width, height, sf, src, srcPitch, df, got, dstPitch))
+			referenceConvert(width, height, sf, src, srcPitch, df, want, dstPitch)
+
+			wantSurface, err := CreateRGBSurfaceWithFormatFrom(want, width, height, 0, dstPitch, df)
+			require.NoError(t, err)
+			gotSurface, err := CreateRGBSurfaceWithFormatFrom(got, width, height, 0, dstPitch, df)
+			require.NoError(t, err)
+			t.Run(GetPixelFormatName(sf)+"->"+GetPixelFormatName(df), func(t *testing.T) {
+				assertSamePixels(t, wantSurface, gotSurface)
+			})
+		}
+	}
+
+	buf := make([]byte, 16)
+	assert.Error(t, ConvertPixels(2, 2, PixelFormatIndex8, buf, 4, PixelFormatRGB565, buf, 4))
+	assert.Error(t, ConvertPixels(2, 2, PixelFormatRGB565, buf, 4, PixelFormatIndex8, buf, 4))
+	assert.Error(t, ConvertPixels(4, 2, PixelFormatARGB8888, buf, 16, PixelFormatRGB565, buf, 8))
+}
+
+func TestConvertSurface(t *testing.T) {
+	src, err := CreateRGBSurfaceWithFormat(0, 3, 1, 0, PixelFormatIndex8)
+	require.NoError(t, err)
+	require.NoError(t, SetPaletteColors(src.format.palette, []Color{{R: 0xFF, A: 0xFF}, {G: 0xFF, A: 0xFF}, {B: 0xFF, A: 0xFF}}, 0))
+	copy(src.pixels, []byte{0, 1, 2})
+	require.NoError(t, src.SetColorKey(true, 1))
+	src.SetColorMod(1, 2, 3)
+	src.SetAlphaMod(4)
+
+	// the color key becomes transparency
+	argb, err := ConvertSurfaceFormat(src, PixelFormatARGB8888, 0)
+	require.NoError(t, err)
+	defer FreeSurface(argb)
+	assert.Equal(t, []uint32{0xFFFF0000, 0x0000FF00, 0xFF0000FF}, []uint32{argb.getPixel(0, 0), argb.getPixel(1, 0), argb.getPixel(2, 0)})
+	assert.False(t, argb.HasColorKey())
+	assert.Equal(t, uint32(BlendModeBlend), argb.GetBlendMode())
+	r, g, b := argb.GetColorMod()
+	assert.Equal(t, []uint8{1, 2, 3, 4}, []uint8{r, g, b, argb.GetAlphaMod()})
+
+	// without alpha the color key is kept
+	rgb, err := ConvertSurfaceFormat(src, PixelFormatRGB565, 0)
+	require.NoError(t, err)
+	defer FreeSurface(rgb)
+	key, err := rgb.GetColorKey()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0x07E0), key)
+	assert.Equal(t, uint32(BlendModeNone), rgb.GetBlendMode())
+
+	// the settings of the source are untouched
+	key, err = src.GetColorKey()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), key)
+	assert.Equal(t, uint8(4), src.GetAlphaMod())
+
+	// indexed destinations take the palette from the format, or the source
+	format, err := AllocFormat(PixelFormatIndex8)
+	require.NoError(t, err)
+	defer FreeFormat(format)
+	p, err := AllocPalette(2)
+	require.NoError(t, err)
+	require.NoError(t, SetPaletteColors(p, []Color{{B: 0xFF, A: 0xFF}, {R: 0xFF, A: 0xFF}}, 0))
+	require.NoError(t, SetPixelFormatPalette(format, p))
+	indexed, err := ConvertSurface(src, format, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 0, 0}, indexed.pixels[:3])
+	indexed, err = ConvertSurface(src, src.format, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 2}, indexed.pixels[:3])
+
+	_, err = ConvertSurfaceFormat(src, PixelFormatUnknown, 0)
+	assert.Error(t, err)
+	require.NoError(t, src.Lock())
+	_, err = ConvertSurfaceFormat(src, PixelFormatRGB565, 0)
+	assert.Error(t, err)
+}
+
+func TestPremultiplyAlpha(t *testing.T) {
+	src := []byte{0, 0, 0, 0, 0, 0, 0, 0}
+	store32(src, 0x80FF4001)
+	store32(src[4:], 0x00FFFFFF)
+	dst := make([]byte, 8)
+	require.NoError(t, PremultiplyAlpha(2, 1, PixelFormatARGB8888, src, 8, PixelFormatARGB8888, dst, 8))
+	assert.Equal(t, uint32(0x80802001), load32(dst))
+	assert.Equal(t, uint32(0), load32(dst[4:]))
+
+	require.NoError(t, UnpremultiplyAlpha(2, 1, PixelFormatARGB8888, dst, 8, PixelFormatARGB8888, dst, 8))
+	assert.Equal(t, uint32(0x80FF4002), load32(dst))
+
+	// every alpha and color value survives a round trip within one step
+	for a := uint32(1); a < 256; a++ {
+		for c := uint32(0); c < 256; c++ {
+			back := unpremultiply(premultiply(c, a), a)
+			require.InDelta(t, float64(c), float64(back), float64(255/a/2+1), "c %d a %d", c, a)
+		}
+	}
+
+	// the generic path matches the 8888 one
+	rng := rand.New(rand.NewSource(5))
+	pixels := make([]byte, 4*16)
+	rng.Read(pixels)
+	fast := make([]byte, len(pixels))
+	require.NoError(t, PremultiplyAlpha(16, 1, PixelFormatABGR8888, pixels, 64, PixelFormatARGB8888, fast, 64))
+	slow := make([]byte, 16*2)
+	require.NoError(t, PremultiplyAlpha(16, 1, PixelFormatABGR8888, pixels, 64, PixelFormatARGB4444, slow, 32))
+	want := make([]byte, 16*2)
+	referenceConvert(16, 1, PixelFormatARGB8888, fast, 64, PixelFormatARGB4444, want, 32)
+	assert.Equal(t, want, slow)
+
+	assert.Error(t, PremultiplyAlpha(1, 1, PixelFormatIndex8, pixels, 4, PixelFormatARGB8888, fast, 4))
+}
@@ -0,0 +1,169 @@
+package video
+
+import (
+	"github.com/pkg/errors"
+)
+
+// DrawPoint sets the pixel at x, y to a value in the surface format.
+func (s *Surface) DrawPoint(x, y int, pixel uint32) error {
+	return s.DrawPoints([]Point{{X: x, Y: y}}, pixel)
+}
+
+// DrawPoints sets each point to a value in the surface format, points
+// outside the clip rect are skipped.
+func (s *Surface) DrawPoints(points []Point, pixel uint32) error {
+	if s.format == nil {
+		return errors.New("surface has been freed")
+	}
+	for _, p := range points {
+		if s.clipRect.Contains(p) {
+			s.setPixel(p.X, p.Y, pixel)
+		}
+	}
+	return nil
+}
+
+// DrawLine draws a line including both end points.
+func (s *Surface) DrawLine(x1, y1, x2, y2 int, pixel uint32) error {
+	return s.DrawLines([]Point{{X: x1, Y: y1}, {X: x2, Y: y2}}, pixel)
+}
+
+// DrawLines draws a line through each of the points, clipped to the clip
+// rect. Every pixel is drawn once, so a closed path doesn't draw its first
+// point twice.
+func (s *Surface) DrawLines(points []Point, pixel uint32) error {
+	if s.format == nil {
+		return errors.New("surface has been freed")
+	}
+	plotLines(s.clipRect, points, func(x, y int) { s.setPixel(x, y, pixel) })
+	return nil
+}
+
+// blender returns a function that blends a color into the pixel at x, y.
+func (s *Surface) blender(blendMode uint32, r, g, b, a uint8) (func(x, y int), error) {
+	if s.format == nil {
+		return nil, errors.New("surface has been freed")
+	}
+	flags, ok := blendModeFlags[blendMode]
+	if !ok {
+		return nil, errors.Errorf("unsupported blend mode 0x%x", blendMode)
+	}
+	info := blitInfo{dstFormat: s.format, flags: flags}
+	return func(x, y int) {
+		info.put(s.pixels[y*s.pitch:], x, r, g, b, a)
+	}, nil
+}
+
+// BlendPoint blends a color into the pixel at x, y.
+func (s *Surface) BlendPoint(x, y int, blendMode uint32, r, g, b, a uint8) error {
+	return s.BlendPoints([]Point{{X: x, Y: y}}, blendMode, r, g, b, a)
+}
+
+// BlendPoints blends a color into each point with the blend mode.
+func (s *Surface) BlendPoints(points []Point, blendMode uint32, r, g, b, a uint8) error {
+	plot, err := s.blender(blendMode, r, g, b, a)
+	if err != nil {
+		return err
+	}
+	for _, p := range points {
+		if s.clipRect.Contains(p) {
+			plot(p.X, p.Y)
+		}
+	}
+	return nil
+}
+
+// BlendLine blends a line including both end points.
+func (s *Surface) BlendLine(x1, y1, x2, y2 int, blendMode uint32, r, g, b, a uint8) error {
+	return s.BlendLines([]Point{{X: x1, Y: y1}, {X: x2, Y: y2}}, blendMode, r, g, b, a)
+}
+
+// BlendLines blends a line through each of the points like DrawLines.
+func (s *Surface) BlendLines(points []Point, blendMode uint32, r, g, b, a uint8) error {
+	plot, err := s.blender(blendMode, r, g, b, a)
+	if err != nil {
+		return err
+	}
+	plotLines(s.clipRect, points, plot)
+	return nil
+}
+
+// BlendFillRect blends a color into a rectangle, a nil rect is the whole
+// clip rect.
+func (s *Surface) BlendFillRect(rect *Rect, blendMode uint32, r, g, b, a uint8) error {
+	if rect == nil {
+		return s.BlendFillRects([]Rect{s.clipRect}, blendMode, r, g, b, a)
+	}
+	return s.BlendFillRects([]Rect{*rect}, blendMode, r, g, b, a)
+}
+
+// BlendFillRects blends a color into each rectangle.
+func (s *Surface) BlendFillRects(rects []Rect, blendMode uint32, r, g, b, a uint8) error {
+	plot, err := s.blender(blendMode, r, g, b, a)
+	if err != nil {
+		return err
+	}
+	for _, rect := range rects {
+		clipped, ok := IntersectRect(rect, s.clipRect)
+		if !ok {
+			continue
+		}
+		for y := clipped.Y; y < clipped.Y+clipped.H; y++ {
+			for x := clipped.X; x < clipped.X+clipped.W; x++ {
+				plot(x, y)
+			}
+		}
+	}
+	return nil
+}
+
+// plotLines calls plot for each pixel of the path through points that is
+// inside the clip rect. Segments leave out their last pixel, which is the
+// first of the next one, and the end of the path is only drawn if it isn't
+// the start.
+func plotLines(clip Rect, points []Point, plot func(x, y int)) {
+	if len(points) == 1 {
+		if clip.Contains(points[0]) {
+			plot(points[0].X, points[0].Y)
+		}
+		return
+	}
+	for i := 1; i < len(points); i++ {
+		p0, p1 := points[i-1], points[i]
+		x0, y0, x1, y1, ok := IntersectRectAndLine(clip, p0.X, p0.Y, p1.X, p1.Y)
+		if !ok {
+			continue
+		}
+		last := i == len(points)-1 && (len(points) == 2 || points[0] != p1)
+		clipped := x1 != p1.X || y1 != p1.Y
+		bresenham(x0, y0, x1, y1, last || clipped, plot)
+	}
+}
+
+// bresenham calls plot for each pixel of the line from x0, y0 to x1, y1.
+func bresenham(x0, y0, x1, y1 int, drawEnd bool, plot func(x, y int)) {
+	dx, sx := x1-x0, 1
+	if dx < 0 {
+		dx, sx = -dx, -1
+	}
+	dy, sy := y0-y1, 1
+	if dy > 0 {
+		dy, sy = -dy, -1
+	}
+	e := dx + dy
+	for x0 != x1 || y0 != y1 {
+		plot(x0, y0)
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+	if drawEnd {
+		plot(x0, y0)
+	}
+}
@@ -0,0 +1,112 @@
+package video
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntersectRectAndLine(t *testing.T) {
+	r := Rect{X: 0, Y: 0, W: 10, H: 10}
+	for _, tc := range []struct {
+		in, want [4]int
+		ok       bool
+	}{
+		{[4]int{1, 1, 8, 8}, [4]int{1, 1, 8, 8}, true},
+		{[4]int{-5, 3, 20, 3}, [4]int{0, 3, 9, 3}, true},
+		{[4]int{4, -5, 4, 20}, [4]int{4, 0, 4, 9}, true},
+		{[4]int{-5, -5, 20, 20}, [4]int{0, 0, 9, 9}, true},
+		{[4]int{-5, 3, -1, 8}, [4]int{-5, 3, -1, 8}, false},
+		{[4]int{12, 0, 20, 30}, [4]int{12, 0, 20, 30}, false},
+		{[4]int{-5, 4, 4, -5}, [4]int{-5, 4, 4, -5}, false},
+		{[4]int{-2, 5, 5, -2}, [4]int{0, 3, 3, 0}, true},
+	} {
+		x1, y1, x2, y2, ok := IntersectRectAndLine(r, tc.in[0], tc.in[1], tc.in[2], tc.in[3])
+		assert.Equal(t, tc.ok, ok, "%v", tc.in)
+		if ok {
+			assert.Equal(t, tc.want, [4]int{x1, y1, x2, y2}, "%v", tc.in)
+		}
+	}
+	_, _, _, _, ok := IntersectRectAndLine(Rect{}, 0, 0, 1, 1)
+	assert.False(t, ok)
+}
+
+// drawnPixels returns the pixels of an Index8 surface that are set.
+func drawnPixels(s *Surface) []Point {
+	var points []Point
+	for y := 0; y < s.h; y++ {
+		for x := 0; x < s.w; x++ {
+			if s.getPixel(x, y) != 0 {
+				points = append(points, Point{X: x, Y: y})
+			}
+		}
+	}
+	return points
+}
+
+func TestDrawLines(t *testing.T) {
+	s, err := CreateRGBSurfaceWithFormat(0, 8, 8, 0, PixelFormatIndex8)
+	require.NoError(t, err)
+
+	require.NoError(t, s.DrawLine(1, 1, 4, 3, 1))
+	assert.Equal(t, []Point{{1, 1}, {2, 2}, {3, 2}, {4, 3}}, drawnPixels(s))
+
+	// a single point line is drawn
+	require.NoError(t, s.FillRect(nil, 0))
+	require.NoError(t, s.DrawLine(5, 5, 5, 5, 1))
+	assert.Equal(t, []Point{{5, 5}}, drawnPixels(s))
+
+	// lines are clipped to the clip rect
+	require.NoError(t, s.FillRect(nil, 0))
+	s.SetClipRect(&Rect{X: 2, Y: 0, W: 3, H: 8})
+	require.NoError(t, s.DrawLine(-100, 4, 100, 4, 1))
+	require.NoError(t, s.DrawPoints([]Point{{0, 0}, {3, 0}}, 1))
+	assert.Equal(t, []Point{{3, 0}, {2, 4}, {3, 4}, {4, 4}}, drawnPixels(s))
+	s.SetClipRect(nil)
+
+	// a closed path blends every pixel once
+	require.NoError(t, s.FillRect(nil, 0))
+	argb, err := CreateRGBSurfaceWithFormat(0, 8, 8, 0, PixelFormatARGB8888)
+	require.NoError(t, err)
+	path := []Point{{1, 1}, {6, 1}, {6, 5}, {1, 5}, {1, 1}}
+	require.NoError(t, argb.BlendLines(path, BlendModeAdd, 10, 0, 0, 0xFF))
+	require.NoError(t, s.DrawLines(path, 1))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			r, _, _ := GetRGB(argb.getPixel(x, y), argb.format)
+			want := uint8(0)
+			if s.getPixel(x, y) != 0 {
+				want = 10
+			}
+			require.Equal(t, want, r, "pixel (%d, %d)", x, y)
+		}
+	}
+	assert.Len(t, drawnPixels(s), 18)
+
+	// an open path draws its end point
+	require.NoError(t, s.FillRect(nil, 0))
+	require.NoError(t, s.DrawLines([]Point{{0, 0}, {2, 0}, {2, 2}}, 1))
+	assert.Equal(t, []Point{{0, 0}, {1, 0}, {2, 0}, {2, 1}, {2, 2}}, drawnPixels(s))
+}
+
+func TestBlendDraw(t *testing.T) {
+	s, err := CreateRGBSurfaceWithFormat(0, 4, 4, 0, PixelFormatARGB8888)
+	require.NoError(t, err)
+	require.NoError(t, s.FillRect(nil, MapRGBA(s.format, 0, 0, 200, 0xFF)))
+
+	require.NoError(t, s.BlendFillRect(&Rect{X: 1, Y: 1, W: 2, H: 2}, BlendModeBlend, 255, 0, 0, 0x80))
+	assert.Equal(t, MapRGBA(s.format, 0x80, 0, 99, 0xFF), s.getPixel(1, 1))
+	assert.Equal(t, MapRGBA(s.format, 0, 0, 200, 0xFF), s.getPixel(0, 0))
+
+	require.NoError(t, s.BlendPoint(0, 0, BlendModeMod, 0, 0, 0x80, 0xFF))
+	assert.Equal(t, MapRGBA(s.format, 0, 0, 100, 0xFF), s.getPixel(0, 0))
+
+	require.NoError(t, s.BlendLine(0, 3, 3, 3, BlendModeNone, 1, 2, 3, 4))
+	assert.Equal(t, MapRGBA(s.format, 1, 2, 3, 4), s.getPixel(3, 3))
+
+	assert.Error(t, s.BlendPoint(0, 0, 3, 0, 0, 0, 0))
+	FreeSurface(s)
+	assert.Error(t, s.DrawPoint(0, 0, 0))
+	assert.Error(t, s.BlendFillRect(nil, BlendModeNone, 0, 0, 0, 0))
+}
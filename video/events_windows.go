@@ -6,7 +6,7 @@ import (
 	"github.com/elliotmr/gdl/w32"
 	"github.com/elliotmr/gdl/w32/types/cs"
 	"github.com/elliotmr/gdl/w32/types/wm"
-	"fmt"
+	"github.com/elliotmr/gdl/log"
 )
 
 var GDLAppClass *w32.WindowClass
@@ -41,7 +41,7 @@ func (eh *eventHandler) OnMessage(uMsg uint32, wParam, lParam uintptr) (bool, ui
 func registerApp(name string, style cs.ClassStyle) error {
 	var once sync.Once
 	once.Do(func() {
-		fmt.Println("registering app")
+		log.Debug(log.CategoryVideo, "registering app")
 		if name == "" {
 			name = "GDL_app"
 		}
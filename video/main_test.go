@@ -0,0 +1,18 @@
+package video
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain initializes video once, the window and renderer tests need it.
+func TestMain(m *testing.M) {
+	if err := VideoInit(""); err != nil {
+		fmt.Fprintln(os.Stderr, "video init:", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	VideoQuit()
+	os.Exit(code)
+}
@@ -0,0 +1,195 @@
+package video
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Palette is a shared table of colors for indexed pixel formats. The version
+// changes every time the colors do, so anything caching a mapping to the
+// palette can tell when it is stale.
+type Palette struct {
+	colors   []Color
+	version  uint32
+	refcount int
+}
+
+// AllocPalette creates a palette with ncolors entries, all initialized to
+// opaque white. Release it with FreePalette.
+func AllocPalette(ncolors int) (*Palette, error) {
+	if ncolors < 1 {
+		return nil, errors.New("palette must have at least one color")
+	}
+	p := &Palette{
+		colors:   make([]Color, ncolors),
+		version:  1,
+		refcount: 1,
+	}
+	for i := range p.colors {
+		p.colors[i] = Color{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	}
+	return p, nil
+}
+
+// FreePalette releases a reference to a palette.
+func FreePalette(p *Palette) {
+	if p == nil {
+		return
+	}
+	p.refcount--
+	if p.refcount > 0 {
+		return
+	}
+	p.colors = nil
+}
+
+// SetPaletteColors copies colors into the palette starting at firstColor.
+// Colors that don't fit are ignored, and an error is returned.
+func SetPaletteColors(p *Palette, colors []Color, firstColor int) error {
+	if p == nil {
+		return errors.New("invalid palette")
+	}
+	if firstColor < 0 || firstColor >= len(p.colors) {
+		return errors.Errorf("first color %d out of range", firstColor)
+	}
+	n := copy(p.colors[firstColor:], colors)
+	p.bump()
+	if n < len(colors) {
+		return errors.Errorf("only %d of %d colors fit in the palette", n, len(colors))
+	}
+	return nil
+}
+
+func (p *Palette) bump() {
+	p.version++
+	if p.version == 0 {
+		p.version = 1
+	}
+}
+
+// SetPixelFormatPalette attaches a palette to an indexed pixel format. A nil
+// palette detaches the current one.
+func SetPixelFormatPalette(f *PixelFormat, p *Palette) error {
+	if f == nil {
+		return errors.New("invalid pixel format")
+	}
+	if p != nil && len(p.colors) > 1<<f.bitsPerPixel {
+		return errors.New("palette doesn't match the pixel format")
+	}
+	if f.palette == p {
+		return nil
+	}
+	if p != nil {
+		p.refcount++
+	}
+	FreePalette(f.palette)
+	f.palette = p
+	return nil
+}
+
+func (p *Palette) NumColors() int  { return len(p.colors) }
+func (p *Palette) Version() uint32 { return p.version }
+
+// Colors returns a copy of the palette colors.
+func (p *Palette) Colors() []Color {
+	return append([]Color(nil), p.colors...)
+}
+
+// DitherColors fills colors with a palette that evenly covers the RGB cube
+// for the given depth, for 8 bits this is the RGB332 palette.
+func DitherColors(colors []Color, bpp int) {
+	if bpp != 8 {
+		// only 8bpp has a dither palette, the rest is greyscale
+		for i := range colors {
+			v := uint8(0)
+			if len(colors) > 1 {
+				v = uint8(i * 255 / (len(colors) - 1))
+			}
+			colors[i] = Color{R: v, G: v, B: v, A: 0xFF}
+		}
+		return
+	}
+	for i := range colors {
+		r := i & 0xE0
+		r |= r>>3 | r>>6
+		g := (i << 3) & 0xE0
+		g |= g>>3 | g>>6
+		b := i & 0x3
+		b |= b << 2
+		b |= b << 4
+		colors[i] = Color{R: uint8(r), G: uint8(g), B: uint8(b), A: 0xFF}
+	}
+}
+
+// findColor returns the index of the closest palette entry.
+func (p *Palette) findColor(r, g, b, a uint8) uint8 {
+	best := 0
+	bestDistance := -1
+	for i, c := range p.colors {
+		rd := int(c.R) - int(r)
+		gd := int(c.G) - int(g)
+		bd := int(c.B) - int(b)
+		ad := int(c.A) - int(a)
+		distance := rd*rd + gd*gd + bd*bd + ad*ad
+		if bestDistance < 0 || distance < bestDistance {
+			if distance == 0 {
+				return uint8(i)
+			}
+			best = i
+			bestDistance = distance
+		}
+	}
+	return uint8(best)
+}
+
+// newFormatPalette creates the default palette of an indexed format, black
+// and white for 1 bit formats and opaque white for the rest.
+func newFormatPalette(f *PixelFormat) (*Palette, error) {
+	p, err := AllocPalette(1 << f.bitsPerPixel)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.colors) == 2 {
+		p.colors[0] = Color{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+		p.colors[1] = Color{A: 0xFF}
+	}
+	return p, nil
+}
+
+// getIndex reads the palette index of pixel x from a row of an indexed format.
+func getIndex(row []byte, x int, format uint32) uint8 {
+	switch BitsPerPixel(format) {
+	case 1:
+		bit := uint(x & 7)
+		if PixelOrder(format) == BitmapOrder1234 {
+			bit = 7 - bit
+		}
+		return (row[x>>3] >> bit) & 0x1
+	case 4:
+		shift := uint(x&1) * 4
+		if PixelOrder(format) == BitmapOrder1234 {
+			shift = 4 - shift
+		}
+		return (row[x>>1] >> shift) & 0xF
+	}
+	return row[x]
+}
+
+// setIndex writes the palette index of pixel x to a row of an indexed format.
+func setIndex(row []byte, x int, format uint32, index uint8) {
+	switch BitsPerPixel(format) {
+	case 1:
+		bit := uint(x & 7)
+		if PixelOrder(format) == BitmapOrder1234 {
+			bit = 7 - bit
+		}
+		row[x>>3] = row[x>>3]&^(1<<bit) | (index&0x1)<<bit
+	case 4:
+		shift := uint(x&1) * 4
+		if PixelOrder(format) == BitmapOrder1234 {
+			shift = 4 - shift
+		}
+		row[x>>1] = row[x>>1]&^(0xF<<shift) | (index&0xF)<<shift
+	default:
+		row[x] = index
+	}
+}
@@ -0,0 +1,138 @@
+package video
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaletteColors(t *testing.T) {
+	p, err := AllocPalette(4)
+	require.NoError(t, err)
+	assert.Equal(t, 4, p.NumColors())
+	assert.Equal(t, Color{0xFF, 0xFF, 0xFF, 0xFF}, p.Colors()[3])
+
+	version := p.Version()
+	colors := []Color{{1, 2, 3, 4}, {5, 6, 7, 8}}
+	require.NoError(t, SetPaletteColors(p, colors, 1))
+	assert.Equal(t, []Color{{0xFF, 0xFF, 0xFF, 0xFF}, {1, 2, 3, 4}, {5, 6, 7, 8}, {0xFF, 0xFF, 0xFF, 0xFF}}, p.Colors())
+	assert.NotEqual(t, version, p.Version())
+
+	version = p.Version()
+	assert.Error(t, SetPaletteColors(p, colors, 3))
+	assert.Equal(t, Color{1, 2, 3, 4}, p.Colors()[3])
+	assert.NotEqual(t, version, p.Version())
+	assert.Error(t, SetPaletteColors(p, colors, 4))
+
+	_, err = AllocPalette(0)
+	assert.Error(t, err)
+}
+
+func TestPaletteVersionWraps(t *testing.T) {
+	p, err := AllocPalette(1)
+	require.NoError(t, err)
+	p.version = ^uint32(0)
+	require.NoError(t, SetPaletteColors(p, []Color{{}}, 0))
+	assert.Equal(t, uint32(1), p.Version())
+}
+
+func TestSetPixelFormatPalette(t *testing.T) {
+	f, err := AllocFormat(PixelFormatIndex4LSB)
+	require.NoError(t, err)
+	p, err := AllocPalette(16)
+	require.NoError(t, err)
+	require.NoError(t, SetPixelFormatPalette(f, p))
+	assert.Equal(t, 2, p.refcount)
+	assert.True(t, f.Palette() == p)
+
+	large, err := AllocPalette(17)
+	require.NoError(t, err)
+	assert.Error(t, SetPixelFormatPalette(f, large))
+
+	FreePalette(p)
+	assert.Equal(t, 16, p.NumColors(), "still referenced by the format")
+	FreeFormat(f)
+	assert.Equal(t, 0, p.NumColors())
+}
+
+func TestMapIndexed(t *testing.T) {
+	f, err := AllocFormat(PixelFormatIndex8)
+	require.NoError(t, err)
+	p, err := AllocPalette(256)
+	require.NoError(t, err)
+	DitherColors(p.colors, 8)
+	require.NoError(t, SetPixelFormatPalette(f, p))
+	FreePalette(p)
+
+	assert.Equal(t, uint32(0xE3), MapRGB(f, 0xFF, 0x00, 0xFF))
+	assert.Equal(t, uint32(0x00), MapRGB(f, 0x10, 0x10, 0x10))
+	r, g, b, a := GetRGBA(0x1C, f)
+	assert.Equal(t, [4]uint8{0x00, 0xFF, 0x00, 0xFF}, [4]uint8{r, g, b, a})
+	r, g, b, a = GetRGBA(0xE0, f)
+	assert.Equal(t, [4]uint8{0xFF, 0x00, 0x00, 0xFF}, [4]uint8{r, g, b, a})
+	FreeFormat(f)
+}
+
+func TestDefaultFormatPalette(t *testing.T) {
+	for _, format := range []uint32{PixelFormatIndex1MSB, PixelFormatIndex4MSB, PixelFormatIndex8} {
+		f, err := AllocFormat(format)
+		require.NoError(t, err)
+		p, err := newFormatPalette(f)
+		require.NoError(t, err)
+		assert.Equal(t, 1<<BitsPerPixel(format), p.NumColors())
+		require.NoError(t, SetPixelFormatPalette(f, p))
+		FreeFormat(f)
+	}
+
+	f, err := AllocFormat(PixelFormatIndex1LSB)
+	require.NoError(t, err)
+	p, err := newFormatPalette(f)
+	require.NoError(t, err)
+	assert.Equal(t, []Color{{0xFF, 0xFF, 0xFF, 0xFF}, {0, 0, 0, 0xFF}}, p.Colors())
+}
+
+func TestPackedIndices(t *testing.T) {
+	cases := []struct {
+		format uint32
+		x      int
+		index  uint8
+		row    []byte
+	}{
+		{PixelFormatIndex1MSB, 0, 1, []byte{0x80, 0x00}},
+		{PixelFormatIndex1MSB, 9, 1, []byte{0x00, 0x40}},
+		{PixelFormatIndex1LSB, 0, 1, []byte{0x01, 0x00}},
+		{PixelFormatIndex1LSB, 9, 1, []byte{0x00, 0x02}},
+		{PixelFormatIndex4MSB, 0, 0xA, []byte{0xA0, 0x00}},
+		{PixelFormatIndex4MSB, 3, 0xA, []byte{0x00, 0x0A}},
+		{PixelFormatIndex4LSB, 0, 0xA, []byte{0x0A, 0x00}},
+		{PixelFormatIndex4LSB, 3, 0xA, []byte{0x00, 0xA0}},
+		{PixelFormatIndex8, 1, 0xAB, []byte{0x00, 0xAB}},
+	}
+	for _, c := range cases {
+		row := make([]byte, 2)
+		setIndex(row, c.x, c.format, c.index)
+		assert.Equal(t, c.row, row, GetPixelFormatName(c.format))
+		assert.Equal(t, c.index, getIndex(row, c.x, c.format), GetPixelFormatName(c.format))
+
+		// clearing a pixel leaves its neighbours alone
+		for i := range row {
+			row[i] = 0xFF
+		}
+		setIndex(row, c.x, c.format, 0)
+		assert.Equal(t, uint8(0), getIndex(row, c.x, c.format))
+		assert.Equal(t, uint8(0xFF)>>(8-BitsPerPixel(c.format)), getIndex(row, c.x^1, c.format))
+	}
+}
+
+func TestDitherColors(t *testing.T) {
+	colors := make([]Color, 256)
+	DitherColors(colors, 8)
+	assert.Equal(t, Color{0, 0, 0, 0xFF}, colors[0])
+	assert.Equal(t, Color{0xFF, 0xFF, 0xFF, 0xFF}, colors[0xFF])
+	assert.Equal(t, Color{0x92, 0x49, 0xAA, 0xFF}, colors[0x8A])
+
+	colors = make([]Color, 4)
+	DitherColors(colors, 2)
+	assert.Equal(t, []Color{{0, 0, 0, 0xFF}, {0x55, 0x55, 0x55, 0xFF}, {0xAA, 0xAA, 0xAA, 0xFF}, {0xFF, 0xFF, 0xFF, 0xFF}}, colors)
+}
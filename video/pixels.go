@@ -1,20 +1,588 @@
 package video
 
-type Color struct {
-	r,g,b,a uint8
-}
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"sync"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
 
-type Palette struct {
-	colors []Color
-	version uint32
+type Color struct {
+	R, G, B, A uint8
 }
 
 type PixelFormat struct {
-	format uint32
-	palette *Palette
-	bitsPerPixel, bytesPerPixel uint8
-	rMask, gMask, bMask, aMask uint32
-	rLoss, gLoss, bLoss, aLoss uint8
+	format                         uint32
+	palette                        *Palette
+	bitsPerPixel, bytesPerPixel    uint8
+	rMask, gMask, bMask, aMask     uint32
+	rLoss, gLoss, bLoss, aLoss     uint8
 	rShift, gShift, bShift, aShift uint8
-	next *PixelFormat
+	rBits, gBits, bBits, aBits     uint8
+	refcount                       int
+	next                           *PixelFormat
+}
+
+// Pixel types
+const (
+	PixelTypeUnknown = iota
+	PixelTypeIndex1
+	PixelTypeIndex4
+	PixelTypeIndex8
+	PixelTypePacked8
+	PixelTypePacked16
+	PixelTypePacked32
+	PixelTypeArrayU8
+	PixelTypeArrayU16
+	PixelTypeArrayU32
+	PixelTypeArrayF16
+	PixelTypeArrayF32
+)
+
+// Bitmap pixel order, high bit -> low bit
+const (
+	BitmapOrderNone = iota
+	BitmapOrder4321
+	BitmapOrder1234
+)
+
+// Packed component order, high bit -> low bit
+const (
+	PackedOrderNone = iota
+	PackedOrderXRGB
+	PackedOrderRGBX
+	PackedOrderARGB
+	PackedOrderRGBA
+	PackedOrderXBGR
+	PackedOrderBGRX
+	PackedOrderABGR
+	PackedOrderBGRA
+)
+
+// Array component order, low byte -> high byte
+const (
+	ArrayOrderNone = iota
+	ArrayOrderRGB
+	ArrayOrderRGBA
+	ArrayOrderARGB
+	ArrayOrderBGR
+	ArrayOrderBGRA
+	ArrayOrderABGR
+)
+
+// Packed component layout
+const (
+	PackedLayoutNone = iota
+	PackedLayout332
+	PackedLayout4444
+	PackedLayout1555
+	PackedLayout5551
+	PackedLayout565
+	PackedLayout8888
+	PackedLayout2101010
+	PackedLayout1010102
+)
+
+// Pixel format enums, these use the same values as SDL.
+const (
+	PixelFormatUnknown     = 0
+	PixelFormatIndex1LSB   = 1<<28 | PixelTypeIndex1<<24 | BitmapOrder4321<<20 | 1<<8
+	PixelFormatIndex1MSB   = 1<<28 | PixelTypeIndex1<<24 | BitmapOrder1234<<20 | 1<<8
+	PixelFormatIndex4LSB   = 1<<28 | PixelTypeIndex4<<24 | BitmapOrder4321<<20 | 4<<8
+	PixelFormatIndex4MSB   = 1<<28 | PixelTypeIndex4<<24 | BitmapOrder1234<<20 | 4<<8
+	PixelFormatIndex8      = 1<<28 | PixelTypeIndex8<<24 | 8<<8 | 1
+	PixelFormatRGB332      = 1<<28 | PixelTypePacked8<<24 | PackedOrderXRGB<<20 | PackedLayout332<<16 | 8<<8 | 1
+	PixelFormatXRGB4444    = 1<<28 | PixelTypePacked16<<24 | PackedOrderXRGB<<20 | PackedLayout4444<<16 | 12<<8 | 2
+	PixelFormatXBGR4444    = 1<<28 | PixelTypePacked16<<24 | PackedOrderXBGR<<20 | PackedLayout4444<<16 | 12<<8 | 2
+	PixelFormatXRGB1555    = 1<<28 | PixelTypePacked16<<24 | PackedOrderXRGB<<20 | PackedLayout1555<<16 | 15<<8 | 2
+	PixelFormatXBGR1555    = 1<<28 | PixelTypePacked16<<24 | PackedOrderXBGR<<20 | PackedLayout1555<<16 | 15<<8 | 2
+	PixelFormatARGB4444    = 1<<28 | PixelTypePacked16<<24 | PackedOrderARGB<<20 | PackedLayout4444<<16 | 16<<8 | 2
+	PixelFormatRGBA4444    = 1<<28 | PixelTypePacked16<<24 | PackedOrderRGBA<<20 | PackedLayout4444<<16 | 16<<8 | 2
+	PixelFormatABGR4444    = 1<<28 | PixelTypePacked16<<24 | PackedOrderABGR<<20 | PackedLayout4444<<16 | 16<<8 | 2
+	PixelFormatBGRA4444    = 1<<28 | PixelTypePacked16<<24 | PackedOrderBGRA<<20 | PackedLayout4444<<16 | 16<<8 | 2
+	PixelFormatARGB1555    = 1<<28 | PixelTypePacked16<<24 | PackedOrderARGB<<20 | PackedLayout1555<<16 | 16<<8 | 2
+	PixelFormatRGBA5551    = 1<<28 | PixelTypePacked16<<24 | PackedOrderRGBA<<20 | PackedLayout5551<<16 | 16<<8 | 2
+	PixelFormatABGR1555    = 1<<28 | PixelTypePacked16<<24 | PackedOrderABGR<<20 | PackedLayout1555<<16 | 16<<8 | 2
+	PixelFormatBGRA5551    = 1<<28 | PixelTypePacked16<<24 | PackedOrderBGRA<<20 | PackedLayout5551<<16 | 16<<8 | 2
+	PixelFormatRGB565      = 1<<28 | PixelTypePacked16<<24 | PackedOrderXRGB<<20 | PackedLayout565<<16 | 16<<8 | 2
+	PixelFormatBGR565      = 1<<28 | PixelTypePacked16<<24 | PackedOrderXBGR<<20 | PackedLayout565<<16 | 16<<8 | 2
+	PixelFormatRGB24       = 1<<28 | PixelTypeArrayU8<<24 | ArrayOrderRGB<<20 | 24<<8 | 3
+	PixelFormatBGR24       = 1<<28 | PixelTypeArrayU8<<24 | ArrayOrderBGR<<20 | 24<<8 | 3
+	PixelFormatXRGB8888    = 1<<28 | PixelTypePacked32<<24 | PackedOrderXRGB<<20 | PackedLayout8888<<16 | 24<<8 | 4
+	PixelFormatRGBX8888    = 1<<28 | PixelTypePacked32<<24 | PackedOrderRGBX<<20 | PackedLayout8888<<16 | 24<<8 | 4
+	PixelFormatXBGR8888    = 1<<28 | PixelTypePacked32<<24 | PackedOrderXBGR<<20 | PackedLayout8888<<16 | 24<<8 | 4
+	PixelFormatBGRX8888    = 1<<28 | PixelTypePacked32<<24 | PackedOrderBGRX<<20 | PackedLayout8888<<16 | 24<<8 | 4
+	PixelFormatARGB8888    = 1<<28 | PixelTypePacked32<<24 | PackedOrderARGB<<20 | PackedLayout8888<<16 | 32<<8 | 4
+	PixelFormatRGBA8888    = 1<<28 | PixelTypePacked32<<24 | PackedOrderRGBA<<20 | PackedLayout8888<<16 | 32<<8 | 4
+	PixelFormatABGR8888    = 1<<28 | PixelTypePacked32<<24 | PackedOrderABGR<<20 | PackedLayout8888<<16 | 32<<8 | 4
+	PixelFormatBGRA8888    = 1<<28 | PixelTypePacked32<<24 | PackedOrderBGRA<<20 | PackedLayout8888<<16 | 32<<8 | 4
+	PixelFormatARGB2101010 = 1<<28 | PixelTypePacked32<<24 | PackedOrderARGB<<20 | PackedLayout2101010<<16 | 32<<8 | 4
+
+	// aliases with the SDL names
+	PixelFormatRGB444 = PixelFormatXRGB4444
+	PixelFormatBGR444 = PixelFormatXBGR4444
+	PixelFormatRGB555 = PixelFormatXRGB1555
+	PixelFormatBGR555 = PixelFormatXBGR1555
+	PixelFormatRGB888 = PixelFormatXRGB8888
+	PixelFormatBGR888 = PixelFormatXBGR8888
+)
+
+// YUV pixel format enums, these are four character codes.
+const (
+	PixelFormatYV12 = 'Y' | 'V'<<8 | '1'<<16 | '2'<<24 // Planar mode: Y + V + U (3 planes)
+	PixelFormatIYUV = 'I' | 'Y'<<8 | 'U'<<16 | 'V'<<24 // Planar mode: Y + U + V (3 planes)
+	PixelFormatYUY2 = 'Y' | 'U'<<8 | 'Y'<<16 | '2'<<24 // Packed mode: Y0+U0+Y1+V0 (1 plane)
+	PixelFormatUYVY = 'U' | 'Y'<<8 | 'V'<<16 | 'Y'<<24 // Packed mode: U0+Y0+V0+Y1 (1 plane)
+	PixelFormatYVYU = 'Y' | 'V'<<8 | 'Y'<<16 | 'U'<<24 // Packed mode: Y0+V0+Y1+U0 (1 plane)
+	PixelFormatNV12 = 'N' | 'V'<<8 | '1'<<16 | '2'<<24 // Planar mode: Y + U/V interleaved (2 planes)
+	PixelFormatNV21 = 'N' | 'V'<<8 | '2'<<16 | '1'<<24 // Planar mode: Y + V/U interleaved (2 planes)
+)
+
+// Byte order aliases, these name the order of the components in memory and
+// depend on the host byte order.
+var (
+	PixelFormatRGBA32 uint32
+	PixelFormatARGB32 uint32
+	PixelFormatBGRA32 uint32
+	PixelFormatABGR32 uint32
+)
+
+var hostByteOrder binary.ByteOrder
+var bigEndian bool
+
+func init() {
+	var endianCheck uint32 = 0x1
+	b := (*[4]byte)(unsafe.Pointer(&endianCheck))
+	if b[0] == 1 {
+		hostByteOrder = binary.LittleEndian
+		PixelFormatRGBA32 = PixelFormatABGR8888
+		PixelFormatARGB32 = PixelFormatBGRA8888
+		PixelFormatBGRA32 = PixelFormatARGB8888
+		PixelFormatABGR32 = PixelFormatRGBA8888
+	} else {
+		hostByteOrder = binary.BigEndian
+		bigEndian = true
+		PixelFormatRGBA32 = PixelFormatRGBA8888
+		PixelFormatARGB32 = PixelFormatARGB8888
+		PixelFormatBGRA32 = PixelFormatBGRA8888
+		PixelFormatABGR32 = PixelFormatABGR8888
+	}
+}
+
+// pixelFormats lists every format that AllocFormat and the name lookup know about.
+var pixelFormats = []uint32{
+	PixelFormatIndex1LSB, PixelFormatIndex1MSB, PixelFormatIndex4LSB, PixelFormatIndex4MSB, PixelFormatIndex8,
+	PixelFormatRGB332,
+	PixelFormatXRGB4444, PixelFormatXBGR4444, PixelFormatXRGB1555, PixelFormatXBGR1555,
+	PixelFormatARGB4444, PixelFormatRGBA4444, PixelFormatABGR4444, PixelFormatBGRA4444,
+	PixelFormatARGB1555, PixelFormatRGBA5551, PixelFormatABGR1555, PixelFormatBGRA5551,
+	PixelFormatRGB565, PixelFormatBGR565,
+	PixelFormatRGB24, PixelFormatBGR24,
+	PixelFormatXRGB8888, PixelFormatRGBX8888, PixelFormatXBGR8888, PixelFormatBGRX8888,
+	PixelFormatARGB8888, PixelFormatRGBA8888, PixelFormatABGR8888, PixelFormatBGRA8888,
+	PixelFormatARGB2101010,
+}
+
+var pixelFormatNames = map[uint32]string{
+	PixelFormatUnknown:     "GDL_PIXELFORMAT_UNKNOWN",
+	PixelFormatIndex1LSB:   "GDL_PIXELFORMAT_INDEX1LSB",
+	PixelFormatIndex1MSB:   "GDL_PIXELFORMAT_INDEX1MSB",
+	PixelFormatIndex4LSB:   "GDL_PIXELFORMAT_INDEX4LSB",
+	PixelFormatIndex4MSB:   "GDL_PIXELFORMAT_INDEX4MSB",
+	PixelFormatIndex8:      "GDL_PIXELFORMAT_INDEX8",
+	PixelFormatRGB332:      "GDL_PIXELFORMAT_RGB332",
+	PixelFormatXRGB4444:    "GDL_PIXELFORMAT_XRGB4444",
+	PixelFormatXBGR4444:    "GDL_PIXELFORMAT_XBGR4444",
+	PixelFormatXRGB1555:    "GDL_PIXELFORMAT_XRGB1555",
+	PixelFormatXBGR1555:    "GDL_PIXELFORMAT_XBGR1555",
+	PixelFormatARGB4444:    "GDL_PIXELFORMAT_ARGB4444",
+	PixelFormatRGBA4444:    "GDL_PIXELFORMAT_RGBA4444",
+	PixelFormatABGR4444:    "GDL_PIXELFORMAT_ABGR4444",
+	PixelFormatBGRA4444:    "GDL_PIXELFORMAT_BGRA4444",
+	PixelFormatARGB1555:    "GDL_PIXELFORMAT_ARGB1555",
+	PixelFormatRGBA5551:    "GDL_PIXELFORMAT_RGBA5551",
+	PixelFormatABGR1555:    "GDL_PIXELFORMAT_ABGR1555",
+	PixelFormatBGRA5551:    "GDL_PIXELFORMAT_BGRA5551",
+	PixelFormatRGB565:      "GDL_PIXELFORMAT_RGB565",
+	PixelFormatBGR565:      "GDL_PIXELFORMAT_BGR565",
+	PixelFormatRGB24:       "GDL_PIXELFORMAT_RGB24",
+	PixelFormatBGR24:       "GDL_PIXELFORMAT_BGR24",
+	PixelFormatXRGB8888:    "GDL_PIXELFORMAT_XRGB8888",
+	PixelFormatRGBX8888:    "GDL_PIXELFORMAT_RGBX8888",
+	PixelFormatXBGR8888:    "GDL_PIXELFORMAT_XBGR8888",
+	PixelFormatBGRX8888:    "GDL_PIXELFORMAT_BGRX8888",
+	PixelFormatARGB8888:    "GDL_PIXELFORMAT_ARGB8888",
+	PixelFormatRGBA8888:    "GDL_PIXELFORMAT_RGBA8888",
+	PixelFormatABGR8888:    "GDL_PIXELFORMAT_ABGR8888",
+	PixelFormatBGRA8888:    "GDL_PIXELFORMAT_BGRA8888",
+	PixelFormatARGB2101010: "GDL_PIXELFORMAT_ARGB2101010",
+	PixelFormatYV12:        "GDL_PIXELFORMAT_YV12",
+	PixelFormatIYUV:        "GDL_PIXELFORMAT_IYUV",
+	PixelFormatYUY2:        "GDL_PIXELFORMAT_YUY2",
+	PixelFormatUYVY:        "GDL_PIXELFORMAT_UYVY",
+	PixelFormatYVYU:        "GDL_PIXELFORMAT_YVYU",
+	PixelFormatNV12:        "GDL_PIXELFORMAT_NV12",
+	PixelFormatNV21:        "GDL_PIXELFORMAT_NV21",
+}
+
+func PixelFlag(format uint32) uint32    { return (format >> 28) & 0x0F }
+func PixelType(format uint32) uint32    { return (format >> 24) & 0x0F }
+func PixelOrder(format uint32) uint32   { return (format >> 20) & 0x0F }
+func PixelLayout(format uint32) uint32  { return (format >> 16) & 0x0F }
+func BitsPerPixel(format uint32) uint32 { return (format >> 8) & 0xFF }
+
+// BytesPerPixel returns the bytes per pixel of a format, for the YUV formats
+// this is the size of a luma sample and its share of the chroma in the
+// first plane.
+func BytesPerPixel(format uint32) uint32 {
+	switch format {
+	case PixelFormatYUY2, PixelFormatUYVY, PixelFormatYVYU:
+		return 2
+	}
+	if IsPixelFormatFourCC(format) {
+		return 1
+	}
+	return format & 0xFF
+}
+
+func IsPixelFormatIndexed(format uint32) bool {
+	t := PixelType(format)
+	return PixelFlag(format) == 1 && (t == PixelTypeIndex1 || t == PixelTypeIndex4 || t == PixelTypeIndex8)
+}
+
+func IsPixelFormatPacked(format uint32) bool {
+	t := PixelType(format)
+	return PixelFlag(format) == 1 && (t == PixelTypePacked8 || t == PixelTypePacked16 || t == PixelTypePacked32)
+}
+
+func IsPixelFormatArray(format uint32) bool {
+	t := PixelType(format)
+	return PixelFlag(format) == 1 && t >= PixelTypeArrayU8 && t <= PixelTypeArrayF32
+}
+
+func IsPixelFormatAlpha(format uint32) bool {
+	if IsPixelFormatPacked(format) {
+		o := PixelOrder(format)
+		return o == PackedOrderARGB || o == PackedOrderRGBA || o == PackedOrderABGR || o == PackedOrderBGRA
+	}
+	if IsPixelFormatArray(format) {
+		o := PixelOrder(format)
+		return o == ArrayOrderARGB || o == ArrayOrderRGBA || o == ArrayOrderABGR || o == ArrayOrderBGRA
+	}
+	return false
+}
+
+// IsPixelFormatFourCC returns whether the format is a four character code,
+// such as the YUV formats.
+func IsPixelFormatFourCC(format uint32) bool {
+	return format != 0 && PixelFlag(format) != 1
+}
+
+// GetPixelFormatName returns a readable name for a pixel format.
+func GetPixelFormatName(format uint32) string {
+	if name, ok := pixelFormatNames[format]; ok {
+		return name
+	}
+	return pixelFormatNames[PixelFormatUnknown]
+}
+
+// layoutMasks are the component masks of each packed layout from the high
+// bits to the low bits.
+var layoutMasks = map[uint32][4]uint32{
+	PackedLayout332:     {0x00000000, 0x000000E0, 0x0000001C, 0x00000003},
+	PackedLayout4444:    {0x0000F000, 0x00000F00, 0x000000F0, 0x0000000F},
+	PackedLayout1555:    {0x00008000, 0x00007C00, 0x000003E0, 0x0000001F},
+	PackedLayout5551:    {0x0000F800, 0x000007C0, 0x0000003E, 0x00000001},
+	PackedLayout565:     {0x00000000, 0x0000F800, 0x000007E0, 0x0000001F},
+	PackedLayout8888:    {0xFF000000, 0x00FF0000, 0x0000FF00, 0x000000FF},
+	PackedLayout2101010: {0xC0000000, 0x3FF00000, 0x000FFC00, 0x000003FF},
+	PackedLayout1010102: {0xFFC00000, 0x003FF000, 0x00000FFC, 0x00000003},
+}
+
+// PixelFormatEnumToMasks returns the bits per pixel and the component masks
+// of a format. Indexed formats have no masks.
+func PixelFormatEnumToMasks(format uint32) (bpp int, rMask, gMask, bMask, aMask uint32, err error) {
+	if IsPixelFormatFourCC(format) {
+		return 0, 0, 0, 0, 0, errors.Errorf("%s has no masks", GetPixelFormatName(format))
+	}
+	if BytesPerPixel(format) <= 2 {
+		bpp = int(BitsPerPixel(format))
+	} else {
+		bpp = int(BytesPerPixel(format)) * 8
+	}
+
+	switch format {
+	case PixelFormatRGB24:
+		if bigEndian {
+			return bpp, 0x00FF0000, 0x0000FF00, 0x000000FF, 0, nil
+		}
+		return bpp, 0x000000FF, 0x0000FF00, 0x00FF0000, 0, nil
+	case PixelFormatBGR24:
+		if bigEndian {
+			return bpp, 0x000000FF, 0x0000FF00, 0x00FF0000, 0, nil
+		}
+		return bpp, 0x00FF0000, 0x0000FF00, 0x000000FF, 0, nil
+	}
+
+	if !IsPixelFormatPacked(format) {
+		// not a format that uses masks
+		return bpp, 0, 0, 0, 0, nil
+	}
+	masks, ok := layoutMasks[PixelLayout(format)]
+	if !ok {
+		return 0, 0, 0, 0, 0, errors.Errorf("unknown pixel format layout (0x%08x)", format)
+	}
+
+	switch PixelOrder(format) {
+	case PackedOrderXRGB:
+		rMask, gMask, bMask = masks[1], masks[2], masks[3]
+	case PackedOrderRGBX:
+		rMask, gMask, bMask = masks[0], masks[1], masks[2]
+	case PackedOrderARGB:
+		aMask, rMask, gMask, bMask = masks[0], masks[1], masks[2], masks[3]
+	case PackedOrderRGBA:
+		rMask, gMask, bMask, aMask = masks[0], masks[1], masks[2], masks[3]
+	case PackedOrderXBGR:
+		bMask, gMask, rMask = masks[1], masks[2], masks[3]
+	case PackedOrderBGRX:
+		bMask, gMask, rMask = masks[0], masks[1], masks[2]
+	case PackedOrderBGRA:
+		bMask, gMask, rMask, aMask = masks[0], masks[1], masks[2], masks[3]
+	case PackedOrderABGR:
+		aMask, bMask, gMask, rMask = masks[0], masks[1], masks[2], masks[3]
+	default:
+		return 0, 0, 0, 0, 0, errors.Errorf("unknown pixel format order (0x%08x)", format)
+	}
+	return bpp, rMask, gMask, bMask, aMask, nil
+}
+
+// MasksToPixelFormatEnum returns the pixel format matching the bits per
+// pixel and component masks, or PixelFormatUnknown.
+func MasksToPixelFormatEnum(bpp int, rMask, gMask, bMask, aMask uint32) uint32 {
+	if rMask == 0 {
+		// no masks, pick the default format for the depth
+		switch bpp {
+		case 1:
+			return PixelFormatIndex1MSB
+		case 4:
+			return PixelFormatIndex4MSB
+		case 8:
+			return PixelFormatIndex8
+		case 12:
+			return PixelFormatXRGB4444
+		case 15:
+			return PixelFormatXRGB1555
+		case 16:
+			return PixelFormatRGB565
+		case 24:
+			if bigEndian {
+				return PixelFormatRGB24
+			}
+			return PixelFormatBGR24
+		case 32:
+			return PixelFormatXRGB8888
+		}
+		return PixelFormatUnknown
+	}
+
+	for _, f := range pixelFormats {
+		if IsPixelFormatIndexed(f) || !(int(BitsPerPixel(f)) == bpp || int(BytesPerPixel(f))*8 == bpp) {
+			continue
+		}
+		_, r, g, b, a, err := PixelFormatEnumToMasks(f)
+		if err == nil && r == rMask && g == gMask && b == bMask && a == aMask {
+			return f
+		}
+	}
+	return PixelFormatUnknown
+}
+
+var (
+	formatsMu sync.Mutex
+	formats   *PixelFormat
+)
+
+// AllocFormat returns a PixelFormat describing the format. Formats are
+// shared and reference counted, release them with FreeFormat.
+func AllocFormat(pixelFormat uint32) (*PixelFormat, error) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	for f := formats; f != nil; f = f.next {
+		if f.format == pixelFormat {
+			f.refcount++
+			return f, nil
+		}
+	}
+
+	f := &PixelFormat{}
+	if err := f.init(pixelFormat); err != nil {
+		return nil, err
+	}
+	if !IsPixelFormatIndexed(pixelFormat) {
+		// indexed formats have a palette so they can't be shared
+		f.next = formats
+		formats = f
+	}
+	f.refcount = 1
+	return f, nil
+}
+
+func (f *PixelFormat) init(pixelFormat uint32) error {
+	if _, ok := pixelFormatNames[pixelFormat]; !ok || pixelFormat == PixelFormatUnknown {
+		return errors.Errorf("unknown pixel format (0x%08x)", pixelFormat)
+	}
+	bpp, rMask, gMask, bMask, aMask, err := PixelFormatEnumToMasks(pixelFormat)
+	if err != nil {
+		return errors.Wrap(err, "unable to get masks")
+	}
+
+	*f = PixelFormat{
+		format:        pixelFormat,
+		bitsPerPixel:  uint8(bpp),
+		bytesPerPixel: uint8((bpp + 7) / 8),
+		rMask:         rMask,
+		gMask:         gMask,
+		bMask:         bMask,
+		aMask:         aMask,
+	}
+	f.rShift, f.rBits, f.rLoss = maskInfo(rMask)
+	f.gShift, f.gBits, f.gLoss = maskInfo(gMask)
+	f.bShift, f.bBits, f.bLoss = maskInfo(bMask)
+	f.aShift, f.aBits, f.aLoss = maskInfo(aMask)
+	return nil
+}
+
+// maskInfo returns the shift, width and loss of precision of a component mask.
+func maskInfo(mask uint32) (shift, width, loss uint8) {
+	if mask == 0 {
+		return 0, 0, 8
+	}
+	shift = uint8(bits.TrailingZeros32(mask))
+	width = uint8(bits.OnesCount32(mask))
+	if width < 8 {
+		loss = 8 - width
+	}
+	return shift, width, loss
+}
+
+// FreeFormat releases a reference to a format returned by AllocFormat.
+func FreeFormat(f *PixelFormat) {
+	if f == nil {
+		return
+	}
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	f.refcount--
+	if f.refcount > 0 {
+		return
+	}
+	for p := &formats; *p != nil; p = &(*p).next {
+		if *p == f {
+			*p = f.next
+			break
+		}
+	}
+	f.next = nil
+	FreePalette(f.palette)
+	f.palette = nil
+}
+
+func (f *PixelFormat) Format() uint32     { return f.format }
+func (f *PixelFormat) BitsPerPixel() int  { return int(f.bitsPerPixel) }
+func (f *PixelFormat) BytesPerPixel() int { return int(f.bytesPerPixel) }
+func (f *PixelFormat) Palette() *Palette  { return f.palette }
+func (f *PixelFormat) Masks() (r, g, b, a uint32) {
+	return f.rMask, f.gMask, f.bMask, f.aMask
+}
+
+func (f *PixelFormat) String() string {
+	return fmt.Sprintf("%s (%d bpp)", GetPixelFormatName(f.format), f.bitsPerPixel)
+}
+
+// expandTable maps a component with n bits of precision to 8 bits.
+var expandTable [9][256]uint8
+
+func init() {
+	for n := uint(1); n <= 8; n++ {
+		max := uint32(1)<<n - 1
+		for v := uint32(0); v <= max; v++ {
+			expandTable[n][v] = uint8((v*255 + max/2) / max)
+		}
+	}
+}
+
+// packComponent scales an 8 bit component to the mask.
+func packComponent(c uint8, width, shift uint8) uint32 {
+	switch {
+	case width == 0:
+		return 0
+	case width <= 8:
+		return uint32(c>>(8-width)) << shift
+	default:
+		// replicate the high bits into the extra precision
+		v := uint32(c)<<(width-8) | uint32(c)>>(16-width)
+		return v << shift
+	}
+}
+
+// unpackComponent scales a masked component to 8 bits.
+func unpackComponent(pixel, mask uint32, width, shift uint8) uint8 {
+	v := (pixel & mask) >> shift
+	if width > 8 {
+		return uint8(v >> (width - 8))
+	}
+	return expandTable[width][v]
+}
+
+// MapRGB maps a color to a pixel value in the format, the alpha channel is
+// fully opaque. For indexed formats the closest palette entry is used.
+func MapRGB(f *PixelFormat, r, g, b uint8) uint32 {
+	if f.palette != nil {
+		return uint32(f.palette.findColor(r, g, b, 0xFF))
+	}
+	return packComponent(r, f.rBits, f.rShift) |
+		packComponent(g, f.gBits, f.gShift) |
+		packComponent(b, f.bBits, f.bShift) |
+		f.aMask
+}
+
+// MapRGBA maps a color with alpha to a pixel value in the format.
+func MapRGBA(f *PixelFormat, r, g, b, a uint8) uint32 {
+	if f.palette != nil {
+		return uint32(f.palette.findColor(r, g, b, a))
+	}
+	return packComponent(r, f.rBits, f.rShift) |
+		packComponent(g, f.gBits, f.gShift) |
+		packComponent(b, f.bBits, f.bShift) |
+		packComponent(a, f.aBits, f.aShift)
+}
+
+// GetRGB returns the color of a pixel value in the format.
+func GetRGB(pixel uint32, f *PixelFormat) (r, g, b uint8) {
+	r, g, b, _ = GetRGBA(pixel, f)
+	return r, g, b
+}
+
+// GetRGBA returns the color and alpha of a pixel value in the format,
+// formats without alpha are fully opaque.
+func GetRGBA(pixel uint32, f *PixelFormat) (r, g, b, a uint8) {
+	if IsPixelFormatIndexed(f.format) {
+		if f.palette == nil || int(pixel) >= len(f.palette.colors) {
+			return 0, 0, 0, 0xFF
+		}
+		c := f.palette.colors[pixel]
+		return c.R, c.G, c.B, c.A
+	}
+	r = unpackComponent(pixel, f.rMask, f.rBits, f.rShift)
+	g = unpackComponent(pixel, f.gMask, f.gBits, f.gShift)
+	b = unpackComponent(pixel, f.bMask, f.bBits, f.bShift)
+	if f.aMask == 0 {
+		return r, g, b, 0xFF
+	}
+	return r, g, b, unpackComponent(pixel, f.aMask, f.aBits, f.aShift)
 }
@@ -0,0 +1,219 @@
+package video
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pixelFormatCases = []struct {
+	format                     uint32
+	name                       string
+	bpp, bytes                 int
+	rMask, gMask, bMask, aMask uint32
+}{
+	{PixelFormatIndex1LSB, "GDL_PIXELFORMAT_INDEX1LSB", 1, 1, 0, 0, 0, 0},
+	{PixelFormatIndex1MSB, "GDL_PIXELFORMAT_INDEX1MSB", 1, 1, 0, 0, 0, 0},
+	{PixelFormatIndex4LSB, "GDL_PIXELFORMAT_INDEX4LSB", 4, 1, 0, 0, 0, 0},
+	{PixelFormatIndex4MSB, "GDL_PIXELFORMAT_INDEX4MSB", 4, 1, 0, 0, 0, 0},
+	{PixelFormatIndex8, "GDL_PIXELFORMAT_INDEX8", 8, 1, 0, 0, 0, 0},
+	{PixelFormatRGB332, "GDL_PIXELFORMAT_RGB332", 8, 1, 0xE0, 0x1C, 0x03, 0},
+	{PixelFormatXRGB4444, "GDL_PIXELFORMAT_XRGB4444", 12, 2, 0x0F00, 0x00F0, 0x000F, 0},
+	{PixelFormatXBGR4444, "GDL_PIXELFORMAT_XBGR4444", 12, 2, 0x000F, 0x00F0, 0x0F00, 0},
+	{PixelFormatXRGB1555, "GDL_PIXELFORMAT_XRGB1555", 15, 2, 0x7C00, 0x03E0, 0x001F, 0},
+	{PixelFormatXBGR1555, "GDL_PIXELFORMAT_XBGR1555", 15, 2, 0x001F, 0x03E0, 0x7C00, 0},
+	{PixelFormatARGB4444, "GDL_PIXELFORMAT_ARGB4444", 16, 2, 0x0F00, 0x00F0, 0x000F, 0xF000},
+	{PixelFormatRGBA4444, "GDL_PIXELFORMAT_RGBA4444", 16, 2, 0xF000, 0x0F00, 0x00F0, 0x000F},
+	{PixelFormatABGR4444, "GDL_PIXELFORMAT_ABGR4444", 16, 2, 0x000F, 0x00F0, 0x0F00, 0xF000},
+	{PixelFormatBGRA4444, "GDL_PIXELFORMAT_BGRA4444", 16, 2, 0x00F0, 0x0F00, 0xF000, 0x000F},
+	{PixelFormatARGB1555, "GDL_PIXELFORMAT_ARGB1555", 16, 2, 0x7C00, 0x03E0, 0x001F, 0x8000},
+	{PixelFormatRGBA5551, "GDL_PIXELFORMAT_RGBA5551", 16, 2, 0xF800, 0x07C0, 0x003E, 0x0001},
+	{PixelFormatABGR1555, "GDL_PIXELFORMAT_ABGR1555", 16, 2, 0x001F, 0x03E0, 0x7C00, 0x8000},
+	{PixelFormatBGRA5551, "GDL_PIXELFORMAT_BGRA5551", 16, 2, 0x003E, 0x07C0, 0xF800, 0x0001},
+	{PixelFormatRGB565, "GDL_PIXELFORMAT_RGB565", 16, 2, 0xF800, 0x07E0, 0x001F, 0},
+	{PixelFormatBGR565, "GDL_PIXELFORMAT_BGR565", 16, 2, 0x001F, 0x07E0, 0xF800, 0},
+	{PixelFormatRGB24, "GDL_PIXELFORMAT_RGB24", 24, 3, 0x0000FF, 0x00FF00, 0xFF0000, 0},
+	{PixelFormatBGR24, "GDL_PIXELFORMAT_BGR24", 24, 3, 0xFF0000, 0x00FF00, 0x0000FF, 0},
+	{PixelFormatXRGB8888, "GDL_PIXELFORMAT_XRGB8888", 32, 4, 0x00FF0000, 0x0000FF00, 0x000000FF, 0},
+	{PixelFormatRGBX8888, "GDL_PIXELFORMAT_RGBX8888", 32, 4, 0xFF000000, 0x00FF0000, 0x0000FF00, 0},
+	{PixelFormatXBGR8888, "GDL_PIXELFORMAT_XBGR8888", 32, 4, 0x000000FF, 0x0000FF00, 0x00FF0000, 0},
+	{PixelFormatBGRX8888, "GDL_PIXELFORMAT_BGRX8888", 32, 4, 0x0000FF00, 0x00FF0000, 0xFF000000, 0},
+	{PixelFormatARGB8888, "GDL_PIXELFORMAT_ARGB8888", 32, 4, 0x00FF0000, 0x0000FF00, 0x000000FF, 0xFF000000},
+	{PixelFormatRGBA8888, "GDL_PIXELFORMAT_RGBA8888", 32, 4, 0xFF000000, 0x00FF0000, 0x0000FF00, 0x000000FF},
+	{PixelFormatABGR8888, "GDL_PIXELFORMAT_ABGR8888", 32, 4, 0x000000FF, 0x0000FF00, 0x00FF0000, 0xFF000000},
+	{PixelFormatBGRA8888, "GDL_PIXELFORMAT_BGRA8888", 32, 4, 0x0000FF00, 0x00FF0000, 0xFF000000, 0x000000FF},
+	{PixelFormatARGB2101010, "GDL_PIXELFORMAT_ARGB2101010", 32, 4, 0x3FF00000, 0x000FFC00, 0x000003FF, 0xC0000000},
+}
+
+func TestPixelFormatEnumToMasks(t *testing.T) {
+	require.Len(t, pixelFormatCases, len(pixelFormats))
+	for _, c := range pixelFormatCases {
+		if bigEndian && (c.format == PixelFormatRGB24 || c.format == PixelFormatBGR24) {
+			c.rMask, c.bMask = c.bMask, c.rMask
+		}
+		assert.Equal(t, c.name, GetPixelFormatName(c.format))
+		bpp, r, g, b, a, err := PixelFormatEnumToMasks(c.format)
+		require.NoError(t, err, c.name)
+		assert.Equal(t, c.bpp, bpp, c.name)
+		assert.Equal(t, [4]uint32{c.rMask, c.gMask, c.bMask, c.aMask}, [4]uint32{r, g, b, a}, c.name)
+		assert.Equal(t, c.aMask != 0, IsPixelFormatAlpha(c.format), c.name)
+		assert.Equal(t, c.rMask == 0, IsPixelFormatIndexed(c.format), c.name)
+		assert.False(t, IsPixelFormatFourCC(c.format), c.name)
+	}
+	assert.Equal(t, "GDL_PIXELFORMAT_UNKNOWN", GetPixelFormatName(0x12345678))
+}
+
+func TestMasksToPixelFormatEnum(t *testing.T) {
+	for _, f := range pixelFormats {
+		if IsPixelFormatIndexed(f) {
+			continue
+		}
+		bpp, r, g, b, a, err := PixelFormatEnumToMasks(f)
+		require.NoError(t, err)
+		assert.Equal(t, GetPixelFormatName(f), GetPixelFormatName(MasksToPixelFormatEnum(bpp, r, g, b, a)))
+	}
+
+	assert.Equal(t, uint32(PixelFormatIndex1MSB), MasksToPixelFormatEnum(1, 0, 0, 0, 0))
+	assert.Equal(t, uint32(PixelFormatIndex4MSB), MasksToPixelFormatEnum(4, 0, 0, 0, 0))
+	assert.Equal(t, uint32(PixelFormatIndex8), MasksToPixelFormatEnum(8, 0, 0, 0, 0))
+	assert.Equal(t, uint32(PixelFormatRGB565), MasksToPixelFormatEnum(16, 0, 0, 0, 0))
+	assert.Equal(t, uint32(PixelFormatXRGB8888), MasksToPixelFormatEnum(32, 0, 0, 0, 0))
+	assert.Equal(t, uint32(PixelFormatXRGB1555), MasksToPixelFormatEnum(16, 0x7C00, 0x03E0, 0x001F, 0))
+	assert.Equal(t, uint32(PixelFormatXRGB4444), MasksToPixelFormatEnum(16, 0x0F00, 0x00F0, 0x000F, 0))
+	assert.Equal(t, uint32(PixelFormatXBGR8888), MasksToPixelFormatEnum(32, 0x000000FF, 0x0000FF00, 0x00FF0000, 0))
+	assert.Equal(t, uint32(PixelFormatUnknown), MasksToPixelFormatEnum(32, 0x1, 0x2, 0x4, 0x8))
+	assert.Equal(t, uint32(PixelFormatUnknown), MasksToPixelFormatEnum(7, 0, 0, 0, 0))
+}
+
+func TestAllocFormat(t *testing.T) {
+	for _, c := range pixelFormatCases {
+		f, err := AllocFormat(c.format)
+		require.NoError(t, err, c.name)
+		assert.Equal(t, c.format, f.Format())
+		assert.Equal(t, c.bpp, f.BitsPerPixel(), c.name)
+		assert.Equal(t, c.bytes, f.BytesPerPixel(), c.name)
+		FreeFormat(f)
+	}
+
+	f, err := AllocFormat(PixelFormatRGB565)
+	require.NoError(t, err)
+	assert.Equal(t, [4]uint8{11, 5, 0, 0}, [4]uint8{f.rShift, f.gShift, f.bShift, f.aShift})
+	assert.Equal(t, [4]uint8{3, 2, 3, 8}, [4]uint8{f.rLoss, f.gLoss, f.bLoss, f.aLoss})
+	g, err := AllocFormat(PixelFormatRGB565)
+	require.NoError(t, err)
+	assert.True(t, f == g, "rgb formats are shared")
+	FreeFormat(g)
+	FreeFormat(f)
+
+	f, err = AllocFormat(PixelFormatARGB2101010)
+	require.NoError(t, err)
+	assert.Equal(t, [4]uint8{20, 10, 0, 30}, [4]uint8{f.rShift, f.gShift, f.bShift, f.aShift})
+	assert.Equal(t, [4]uint8{0, 0, 0, 6}, [4]uint8{f.rLoss, f.gLoss, f.bLoss, f.aLoss})
+	FreeFormat(f)
+
+	f, err = AllocFormat(PixelFormatIndex8)
+	require.NoError(t, err)
+	g, err = AllocFormat(PixelFormatIndex8)
+	require.NoError(t, err)
+	assert.False(t, f == g, "indexed formats are not shared")
+
+	_, err = AllocFormat(PixelFormatUnknown)
+	assert.Error(t, err)
+	_, err = AllocFormat(0x12345678)
+	assert.Error(t, err)
+}
+
+func TestMapRGBA(t *testing.T) {
+	cases := []struct {
+		format     uint32
+		r, g, b, a uint8
+		pixel      uint32
+	}{
+		{PixelFormatARGB8888, 0x12, 0x34, 0x56, 0x78, 0x78123456},
+		{PixelFormatABGR8888, 0x12, 0x34, 0x56, 0x78, 0x78563412},
+		{PixelFormatRGBA8888, 0x12, 0x34, 0x56, 0x78, 0x12345678},
+		{PixelFormatBGRA8888, 0x12, 0x34, 0x56, 0x78, 0x56341278},
+		{PixelFormatXRGB8888, 0x12, 0x34, 0x56, 0x78, 0x00123456},
+		{PixelFormatRGB565, 0xFF, 0x00, 0xFF, 0xFF, 0xF81F},
+		{PixelFormatRGB565, 0x08, 0x04, 0x08, 0xFF, 0x0821},
+		{PixelFormatARGB1555, 0xFF, 0xFF, 0xFF, 0x80, 0xFFFF},
+		{PixelFormatARGB1555, 0xFF, 0xFF, 0xFF, 0x7F, 0x7FFF},
+		{PixelFormatRGBA4444, 0x10, 0x20, 0x30, 0x40, 0x1234},
+		{PixelFormatRGB332, 0xFF, 0x00, 0xFF, 0xFF, 0xE3},
+		{PixelFormatARGB2101010, 0xFF, 0x80, 0x00, 0xFF, 0xFFF80800},
+	}
+	for _, c := range cases {
+		f, err := AllocFormat(c.format)
+		require.NoError(t, err)
+		assert.Equal(t, c.pixel, MapRGBA(f, c.r, c.g, c.b, c.a), GetPixelFormatName(c.format))
+		if f.aMask == 0 {
+			assert.Equal(t, c.pixel, MapRGB(f, c.r, c.g, c.b), GetPixelFormatName(c.format))
+		}
+		FreeFormat(f)
+	}
+
+	f, err := AllocFormat(PixelFormatARGB8888)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0xFF123456), MapRGB(f, 0x12, 0x34, 0x56))
+	FreeFormat(f)
+}
+
+func TestGetRGBA(t *testing.T) {
+	f, err := AllocFormat(PixelFormatRGB565)
+	require.NoError(t, err)
+	r, g, b, a := GetRGBA(0xF81F, f)
+	assert.Equal(t, [4]uint8{0xFF, 0x00, 0xFF, 0xFF}, [4]uint8{r, g, b, a})
+	r, g, b = GetRGB(0x0821, f)
+	assert.Equal(t, [3]uint8{0x08, 0x04, 0x08}, [3]uint8{r, g, b})
+	FreeFormat(f)
+
+	f, err = AllocFormat(PixelFormatARGB2101010)
+	require.NoError(t, err)
+	r, g, b, a = GetRGBA(0x7FF003FF, f)
+	assert.Equal(t, [4]uint8{0xFF, 0x00, 0xFF, 0x55}, [4]uint8{r, g, b, a})
+	FreeFormat(f)
+}
+
+// TestMapRoundTrip checks every component value of every format survives
+// a round trip through GetRGBA and MapRGBA, and that 8 bit channels are exact.
+func TestMapRoundTrip(t *testing.T) {
+	for _, format := range pixelFormats {
+		if IsPixelFormatIndexed(format) {
+			continue
+		}
+		f, err := AllocFormat(format)
+		require.NoError(t, err)
+		for v := 0; v < 256; v++ {
+			c := uint8(v)
+			pixel := MapRGBA(f, c, c, c, c)
+			r, g, b, a := GetRGBA(pixel, f)
+			assert.Equal(t, pixel, MapRGBA(f, r, g, b, a), "%s %d", GetPixelFormatName(format), v)
+			if f.rBits >= 8 {
+				assert.Equal(t, c, r, "%s %d", GetPixelFormatName(format), v)
+			}
+			if f.aBits == 0 {
+				assert.Equal(t, uint8(0xFF), a)
+			} else if f.aBits == 8 {
+				assert.Equal(t, c, a)
+			}
+		}
+		FreeFormat(f)
+	}
+}
+
+func TestPixelFormatQueries(t *testing.T) {
+	assert.Equal(t, uint32(PixelTypePacked32), PixelType(PixelFormatARGB8888))
+	assert.Equal(t, uint32(PackedOrderARGB), PixelOrder(PixelFormatARGB8888))
+	assert.Equal(t, uint32(PackedLayout8888), PixelLayout(PixelFormatARGB8888))
+	assert.Equal(t, uint32(32), BitsPerPixel(PixelFormatARGB8888))
+	assert.Equal(t, uint32(4), BytesPerPixel(PixelFormatARGB8888))
+	assert.Equal(t, uint32(24), BitsPerPixel(PixelFormatXRGB8888))
+	assert.True(t, IsPixelFormatArray(PixelFormatRGB24))
+	assert.True(t, IsPixelFormatPacked(PixelFormatRGB565))
+	assert.Equal(t, uint32(PixelFormatABGR8888), MasksToPixelFormatEnum(32, 0xFF, 0xFF00, 0xFF0000, 0xFF000000))
+	if !bigEndian {
+		assert.Equal(t, uint32(PixelFormatABGR8888), PixelFormatRGBA32)
+		assert.Equal(t, uint32(PixelFormatARGB8888), PixelFormatBGRA32)
+	}
+}
@@ -0,0 +1,145 @@
+package video
+
+import "image"
+
+type Point struct {
+	X, Y int
+}
+
+// FPoint is a point with float coordinates, used for renderer scales and
+// geometry.
+type FPoint struct {
+	X, Y float32
+}
+
+type Rect struct {
+	X, Y, W, H int
+}
+
+// Empty returns whether the rectangle has no area.
+func (r Rect) Empty() bool {
+	return r.W <= 0 || r.H <= 0
+}
+
+// Contains returns whether the point is inside the rectangle.
+func (r Rect) Contains(p Point) bool {
+	return p.X >= r.X && p.X < r.X+r.W && p.Y >= r.Y && p.Y < r.Y+r.H
+}
+
+// ImageRect converts the rectangle to an image.Rectangle.
+func (r Rect) ImageRect() image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H)
+}
+
+// HasIntersection returns whether two rectangles overlap.
+func HasIntersection(a, b Rect) bool {
+	_, ok := IntersectRect(a, b)
+	return ok
+}
+
+// IntersectRect returns the overlap of two rectangles, and whether it is
+// non-empty.
+func IntersectRect(a, b Rect) (Rect, bool) {
+	x0, y0 := max(a.X, b.X), max(a.Y, b.Y)
+	x1, y1 := min(a.X+a.W, b.X+b.W), min(a.Y+a.H, b.Y+b.H)
+	r := Rect{X: x0, Y: y0, W: x1 - x0, H: y1 - y0}
+	if r.Empty() {
+		return Rect{X: x0, Y: y0}, false
+	}
+	return r, true
+}
+
+// UnionRect returns the smallest rectangle containing both rectangles.
+func UnionRect(a, b Rect) Rect {
+	if a.Empty() {
+		return b
+	}
+	if b.Empty() {
+		return a
+	}
+	x0, y0 := min(a.X, b.X), min(a.Y, b.Y)
+	x1, y1 := max(a.X+a.W, b.X+b.W), max(a.Y+a.H, b.Y+b.H)
+	return Rect{X: x0, Y: y0, W: x1 - x0, H: y1 - y0}
+}
+
+// Outcodes of a point relative to a rectangle, for line clipping.
+const (
+	outcodeLeft = 1 << iota
+	outcodeRight
+	outcodeTop
+	outcodeBottom
+)
+
+func outcode(r Rect, x, y int) int {
+	code := 0
+	switch {
+	case y < r.Y:
+		code |= outcodeTop
+	case y >= r.Y+r.H:
+		code |= outcodeBottom
+	}
+	switch {
+	case x < r.X:
+		code |= outcodeLeft
+	case x >= r.X+r.W:
+		code |= outcodeRight
+	}
+	return code
+}
+
+// IntersectRectAndLine clips the line from (x1, y1) to (x2, y2) to the
+// rectangle. It returns the clipped end points, and false if no part of the
+// line is inside the rectangle.
+func IntersectRectAndLine(r Rect, x1, y1, x2, y2 int) (int, int, int, int, bool) {
+	if r.Empty() {
+		return x1, y1, x2, y2, false
+	}
+	left, top, right, bottom := r.X, r.Y, r.X+r.W-1, r.Y+r.H-1
+	clamp := func(v, lo, hi int) int { return min(max(v, lo), hi) }
+
+	code1, code2 := outcode(r, x1, y1), outcode(r, x2, y2)
+	switch {
+	case code1|code2 == 0:
+		return x1, y1, x2, y2, true
+	case code1&code2 != 0:
+		return x1, y1, x2, y2, false
+	case y1 == y2:
+		return clamp(x1, left, right), y1, clamp(x2, left, right), y2, true
+	case x1 == x2:
+		return x1, clamp(y1, top, bottom), x2, clamp(y2, top, bottom), true
+	}
+
+	// Cohen-Sutherland
+	for code1|code2 != 0 {
+		if code1&code2 != 0 {
+			return x1, y1, x2, y2, false
+		}
+		code := code1
+		if code == 0 {
+			code = code2
+		}
+		var x, y int
+		switch {
+		case code&outcodeTop != 0:
+			y = top
+			x = x1 + int(int64(x2-x1)*int64(y-y1)/int64(y2-y1))
+		case code&outcodeBottom != 0:
+			y = bottom
+			x = x1 + int(int64(x2-x1)*int64(y-y1)/int64(y2-y1))
+		case code&outcodeLeft != 0:
+			x = left
+			y = y1 + int(int64(y2-y1)*int64(x-x1)/int64(x2-x1))
+		default:
+			x = right
+			y = y1 + int(int64(y2-y1)*int64(x-x1)/int64(x2-x1))
+		}
+		if code == code1 {
+			x1, y1 = x, y
+			code1 = outcode(r, x, y)
+		} else {
+			x2, y2 = x, y
+			code2 = outcode(r, x, y)
+		}
+	}
+	return x1, y1, x2, y2, true
+}
@@ -0,0 +1,410 @@
+package video
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// swRenderDriver renders with surface blits, to a window surface or to any
+// surface.
+type swRenderDriver struct{}
+
+func (swRenderDriver) Info() RendererInfo {
+	info := RendererInfo{
+		name:  "software",
+		flags: RendererSoftware | RendererTargetRexture,
+	}
+	formats := []uint32{
+		PixelFormatARGB8888, PixelFormatABGR8888, PixelFormatRGBA8888, PixelFormatBGRA8888,
+		PixelFormatXRGB8888, PixelFormatXBGR8888, PixelFormatRGBX8888, PixelFormatBGRX8888,
+		PixelFormatRGB565, PixelFormatRGB24, PixelFormatBGR24,
+	}
+	info.numTextureFormats = uint32(copy(info.textureFormats[:], formats))
+	return info
+}
+
+func (d swRenderDriver) CreateRenderer(window *Window, flags uint32) (*Renderer, error) {
+	surface, err := GetWindowSurface(window)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newRenderer(d.Info(), &swRenderData{surface: surface, window: window})
+	if err != nil {
+		return nil, err
+	}
+	r.window = window
+	return r, nil
+}
+
+// CreateSoftwareRenderer creates a renderer that draws to a surface.
+func CreateSoftwareRenderer(surface *Surface) (*Renderer, error) {
+	if surface == nil || surface.format == nil {
+		return nil, errors.New("invalid surface")
+	}
+	return newRenderer(swRenderDriver{}.Info(), &swRenderData{surface: surface})
+}
+
+type swRenderData struct {
+	surface *Surface
+	window  *Window
+	target  *Surface // the surface of the render target texture
+}
+
+// activate returns the surface to draw to, fetching the window surface again
+// after it was invalidated by a resize.
+func (d *swRenderData) activate(r *Renderer) (*Surface, error) {
+	if d.target != nil {
+		if d.target.format == nil {
+			return nil, errors.New("render target has been freed")
+		}
+		return d.target, nil
+	}
+	if d.window != nil && !d.window.surfaceValid {
+		s, err := GetWindowSurface(d.window)
+		if err != nil {
+			return nil, err
+		}
+		d.surface = s
+		if err := d.updateViewport(r); err != nil {
+			return nil, err
+		}
+	}
+	if d.surface == nil || d.surface.format == nil {
+		return nil, errors.New("renderer surface has been freed")
+	}
+	return d.surface, nil
+}
+
+func (d *swRenderData) outputSize() (int, int, error) {
+	if d.window != nil && !d.window.surfaceValid {
+		return d.window.w, d.window.h, nil
+	}
+	if d.surface == nil {
+		return 0, 0, errors.New("renderer has no surface")
+	}
+	return d.surface.w, d.surface.h, nil
+}
+
+// updateViewport clips drawing to the viewport, and to the clip rect within
+// it when clipping is on.
+func (d *swRenderData) updateViewport(r *Renderer) error {
+	s := d.surface
+	if d.target != nil {
+		s = d.target
+	}
+	if s == nil {
+		return nil
+	}
+	clip := r.viewport
+	if r.clippingEnabled {
+		clip, _ = IntersectRect(clip, Rect{
+			X: r.viewport.X + r.clipRect.X,
+			Y: r.viewport.Y + r.clipRect.Y,
+			W: r.clipRect.W,
+			H: r.clipRect.H,
+		})
+	}
+	s.SetClipRect(&clip)
+	return nil
+}
+
+func (d *swRenderData) updateClipRect(r *Renderer) error {
+	return d.updateViewport(r)
+}
+
+func (d *swRenderData) setRenderTarget(r *Renderer, t *Texture) error {
+	if t == nil {
+		d.target = nil
+		return nil
+	}
+	s, ok := t.driverData.(*Surface)
+	if !ok || s.format == nil {
+		return errors.New("invalid texture")
+	}
+	d.target = s
+	return nil
+}
+
+func (d *swRenderData) createTexture(r *Renderer, t *Texture) error {
+	s, err := CreateRGBSurfaceWithFormat(0, t.w, t.h, 0, t.format)
+	if err != nil {
+		return err
+	}
+	if err := s.SetScaleMode(t.scaleMode); err != nil {
+		FreeSurface(s)
+		return err
+	}
+	t.driverData = s
+	return nil
+}
+
+func (d *swRenderData) updateTexture(t *Texture, rect Rect, pixels []byte, pitch int) error {
+	s, ok := t.driverData.(*Surface)
+	if !ok || s.format == nil {
+		return errors.New("invalid texture")
+	}
+	bpp := s.format.BytesPerPixel()
+	rowSize := rect.W * bpp
+	for y := 0; y < rect.H; y++ {
+		row := s.pixels[(rect.Y+y)*s.pitch+rect.X*bpp:]
+		copy(row[:rowSize], pixels[y*pitch:])
+	}
+	return nil
+}
+
+func (d *swRenderData) lockTexture(t *Texture, rect Rect) ([]byte, int, error) {
+	s, ok := t.driverData.(*Surface)
+	if !ok || s.format == nil {
+		return nil, 0, errors.New("invalid texture")
+	}
+	return s.pixels[rect.Y*s.pitch+rect.X*s.format.BytesPerPixel():], s.pitch, nil
+}
+
+func (d *swRenderData) unlockTexture(t *Texture) {}
+
+func (d *swRenderData) destroyTexture(t *Texture) {
+	if s, ok := t.driverData.(*Surface); ok {
+		FreeSurface(s)
+	}
+	t.driverData = nil
+}
+
+// textureSurface returns the surface of a texture with the texture color
+// modulation and blend mode applied.
+func textureSurface(t *Texture) (*Surface, error) {
+	s, ok := t.driverData.(*Surface)
+	if !ok || s.format == nil {
+		return nil, errors.New("invalid texture")
+	}
+	s.SetColorMod(t.r, t.g, t.b)
+	s.SetAlphaMod(t.a)
+	if err := s.SetBlendMode(t.blendMode); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (d *swRenderData) clear(r *Renderer) error {
+	s, err := d.activate(r)
+	if err != nil {
+		return err
+	}
+	clip := s.clipRect
+	s.SetClipRect(nil)
+	err = s.FillRect(nil, MapRGBA(s.format, r.r, r.g, r.b, r.a))
+	s.SetClipRect(&clip)
+	return err
+}
+
+func (d *swRenderData) drawPoints(r *Renderer, points []Point) error {
+	s, err := d.activate(r)
+	if err != nil {
+		return err
+	}
+	points = offsetPoints(points, r.viewport)
+	if r.blendMode == BlendModeNone {
+		return s.DrawPoints(points, MapRGBA(s.format, r.r, r.g, r.b, r.a))
+	}
+	return s.BlendPoints(points, r.blendMode, r.r, r.g, r.b, r.a)
+}
+
+func (d *swRenderData) drawLines(r *Renderer, points []Point) error {
+	s, err := d.activate(r)
+	if err != nil {
+		return err
+	}
+	points = offsetPoints(points, r.viewport)
+	if r.blendMode == BlendModeNone {
+		return s.DrawLines(points, MapRGBA(s.format, r.r, r.g, r.b, r.a))
+	}
+	return s.BlendLines(points, r.blendMode, r.r, r.g, r.b, r.a)
+}
+
+func (d *swRenderData) fillRects(r *Renderer, rects []Rect) error {
+	s, err := d.activate(r)
+	if err != nil {
+		return err
+	}
+	moved := make([]Rect, len(rects))
+	for i, rect := range rects {
+		moved[i] = Rect{X: rect.X + r.viewport.X, Y: rect.Y + r.viewport.Y, W: rect.W, H: rect.H}
+	}
+	if r.blendMode == BlendModeNone {
+		return s.FillRects(moved, MapRGBA(s.format, r.r, r.g, r.b, r.a))
+	}
+	return s.BlendFillRects(moved, r.blendMode, r.r, r.g, r.b, r.a)
+}
+
+// offsetPoints moves points from viewport to surface coordinates.
+func offsetPoints(points []Point, viewport Rect) []Point {
+	moved := make([]Point, len(points))
+	for i, p := range points {
+		moved[i] = Point{X: p.X + viewport.X, Y: p.Y + viewport.Y}
+	}
+	return moved
+}
+
+func (d *swRenderData) copy(r *Renderer, t *Texture, src, dst Rect) error {
+	s, err := d.activate(r)
+	if err != nil {
+		return err
+	}
+	ts, err := textureSurface(t)
+	if err != nil {
+		return err
+	}
+	dst.X += r.viewport.X
+	dst.Y += r.viewport.Y
+	return BlitScaled(ts, &src, s, &dst)
+}
+
+// copyEx maps each destination pixel in the bounds of the rotated rectangle
+// back to the texture, and samples the nearest texture pixel.
+func (d *swRenderData) copyEx(r *Renderer, t *Texture, src, dst Rect, angle float64, center Point, flip int) error {
+	if math.Mod(angle, 360) == 0 && flip == FlipNone {
+		return d.copy(r, t, src, dst)
+	}
+	s, err := d.activate(r)
+	if err != nil {
+		return err
+	}
+	ts, err := textureSurface(t)
+	if err != nil {
+		return err
+	}
+	dst.X += r.viewport.X
+	dst.Y += r.viewport.Y
+
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	cx, cy := float64(dst.X+center.X), float64(dst.Y+center.Y)
+	x0, y0 := float64(dst.X), float64(dst.Y)
+	x1, y1 := float64(dst.X+dst.W), float64(dst.Y+dst.H)
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range [4][2]float64{{x0, y0}, {x1, y0}, {x0, y1}, {x1, y1}} {
+		x := cx + (p[0]-cx)*cos - (p[1]-cy)*sin
+		y := cy + (p[0]-cx)*sin + (p[1]-cy)*cos
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	bounds := Rect{X: int(math.Floor(minX)), Y: int(math.Floor(minY))}
+	bounds.W = int(math.Ceil(maxX)) - bounds.X
+	bounds.H = int(math.Ceil(maxY)) - bounds.Y
+	bounds, ok := IntersectRect(bounds, s.clipRect)
+	if !ok {
+		return nil
+	}
+
+	info := ts.blitMap.info
+	info.srcFormat, info.dstFormat = ts.format, s.format
+	scaleX := float64(src.W) / float64(dst.W)
+	scaleY := float64(src.H) / float64(dst.H)
+	for y := bounds.Y; y < bounds.Y+bounds.H; y++ {
+		row := s.pixels[y*s.pitch:]
+		py := float64(y) + 0.5 - cy
+		for x := bounds.X; x < bounds.X+bounds.W; x++ {
+			px := float64(x) + 0.5 - cx
+			u := px*cos + py*sin + cx - x0
+			v := -px*sin + py*cos + cy - y0
+			if u < 0 || v < 0 || u >= float64(dst.W) || v >= float64(dst.H) {
+				continue
+			}
+			sx, sy := min(int(u*scaleX), src.W-1), min(int(v*scaleY), src.H-1)
+			if flip&FlipHorizontal > 0 {
+				sx = src.W - 1 - sx
+			}
+			if flip&FlipVertical > 0 {
+				sy = src.H - 1 - sy
+			}
+			cr, cg, cb, ca := GetRGBA(ts.getPixel(src.X+sx, src.Y+sy), ts.format)
+			info.put(row, x, cr, cg, cb, ca)
+		}
+	}
+	return nil
+}
+
+// geometry rasterizes each triangle, sampling the nearest texture pixel and
+// modulating it with the interpolated color.
+func (d *swRenderData) geometry(r *Renderer, t *Texture, vertices []Vertex, indices []int) error {
+	s, err := d.activate(r)
+	if err != nil {
+		return err
+	}
+	info := blitInfo{dstFormat: s.format, flags: blendModeFlags[r.blendMode]}
+	mod := Color{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	var ts *Surface
+	if t != nil {
+		var ok bool
+		ts, ok = t.driverData.(*Surface)
+		if !ok || ts.format == nil {
+			return errors.New("invalid texture")
+		}
+		info.flags = blendModeFlags[t.blendMode]
+		mod = Color{R: t.r, G: t.g, B: t.b, A: t.a}
+	}
+
+	var v [3]triangleVertex
+	plot := func(x, y int, w [3]float64) {
+		cr := interpolateColor(w, v[0].r, v[1].r, v[2].r)
+		cg := interpolateColor(w, v[0].g, v[1].g, v[2].g)
+		cb := interpolateColor(w, v[0].b, v[1].b, v[2].b)
+		ca := interpolateColor(w, v[0].a, v[1].a, v[2].a)
+		if ts != nil {
+			u := w[0]*v[0].u + w[1]*v[1].u + w[2]*v[2].u
+			tv := w[0]*v[0].v + w[1]*v[1].v + w[2]*v[2].v
+			tx := min(max(int(math.Floor(u*float64(ts.w))), 0), ts.w-1)
+			ty := min(max(int(math.Floor(tv*float64(ts.h))), 0), ts.h-1)
+			tr, tg, tb, ta := GetRGBA(readPixel(ts.pixels[ty*ts.pitch:], tx, ts.format), ts.format)
+			cr = uint8(uint32(tr) * uint32(cr) / 255)
+			cg = uint8(uint32(tg) * uint32(cg) / 255)
+			cb = uint8(uint32(tb) * uint32(cb) / 255)
+			ca = uint8(uint32(ta) * uint32(ca) / 255)
+		}
+		info.put(s.pixels[y*s.pitch:], x, cr, cg, cb, ca)
+	}
+
+	n := len(vertices)
+	if indices != nil {
+		n = len(indices)
+	}
+	for i := 0; i+2 < n; i += 3 {
+		for j := range v {
+			k := i + j
+			if indices != nil {
+				k = indices[k]
+			}
+			v[j] = newTriangleVertex(vertices[k], r.viewport, mod)
+		}
+		fillTriangle(s.clipRect, v, plot)
+	}
+	return nil
+}
+
+// interpolateColor returns a color channel at a point of a triangle from its
+// value at the vertices.
+func interpolateColor(w [3]float64, c0, c1, c2 float64) uint8 {
+	return uint8(min(max(math.Round(w[0]*c0+w[1]*c1+w[2]*c2), 0), 255))
+}
+
+func (d *swRenderData) readPixels(r *Renderer, rect Rect, format uint32, pixels []byte, pitch int) error {
+	s, err := d.activate(r)
+	if err != nil {
+		return err
+	}
+	src := s.pixels[rect.Y*s.pitch+rect.X*s.format.BytesPerPixel():]
+	return ConvertPixels(rect.W, rect.H, s.format.format, src, s.pitch, format, pixels, pitch)
+}
+
+func (d *swRenderData) present(r *Renderer) error {
+	if d.window == nil {
+		return nil
+	}
+	return UpdateWindowSurface(d.window)
+}
+
+func (d *swRenderData) destroy(r *Renderer) {
+	d.surface = nil
+	d.window = nil
+	d.target = nil
+}
@@ -0,0 +1,799 @@
+package video
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elliotmr/gdl/event"
+	"github.com/elliotmr/gdl/hint"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden images in testdata")
+
+// requireGolden compares a surface with a golden image in testdata, which
+// -update writes instead.
+func requireGolden(t *testing.T, s *Surface, name string) {
+	file := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, SaveBMP(s, file))
+	}
+	golden, err := LoadBMP(file)
+	require.NoError(t, err)
+	defer FreeSurface(golden)
+	assert.Equal(t, surfaceRGBA(golden), surfaceRGBA(s), "%s differs from the golden image", name)
+}
+
+func newTestRenderer(t *testing.T, w, h int) (*Renderer, *Surface) {
+	s, err := CreateRGBSurfaceWithFormat(0, w, h, 0, PixelFormatARGB8888)
+	require.NoError(t, err)
+	r, err := CreateSoftwareRenderer(s)
+	require.NoError(t, err)
+	return r, s
+}
+
+// newTestTexture creates an ARGB8888 texture from rows of pixels.
+func newTestTexture(t *testing.T, r *Renderer, rows [][]uint32) *Texture {
+	tex, err := r.createTexture(PixelFormatARGB8888, 0, len(rows[0]), len(rows))
+	require.NoError(t, err)
+	s := tex.driverData.(*Surface)
+	for y, row := range rows {
+		for x, p := range row {
+			s.setPixel(x, y, p)
+		}
+	}
+	return tex
+}
+
+func surfacePixels(s *Surface, rect Rect) [][]uint32 {
+	var rows [][]uint32
+	for y := rect.Y; y < rect.Y+rect.H; y++ {
+		var row []uint32
+		for x := rect.X; x < rect.X+rect.W; x++ {
+			row = append(row, s.getPixel(x, y))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestRendererDraw(t *testing.T) {
+	r, s := newTestRenderer(t, 6, 4)
+	w, h, err := r.GetOutputSize()
+	require.NoError(t, err)
+	assert.Equal(t, []int{6, 4}, []int{w, h})
+
+	r.SetDrawColor(1, 2, 3, 4)
+	red, green, blue, alpha := r.GetDrawColor()
+	assert.Equal(t, []uint8{1, 2, 3, 4}, []uint8{red, green, blue, alpha})
+	require.NoError(t, r.Clear())
+	assert.Equal(t, uint32(0x04010203), s.getPixel(5, 3))
+
+	const o, x = 0x04010203, 0xFFFFFFFF
+	r.SetDrawColor(0xFF, 0xFF, 0xFF, 0xFF)
+	require.NoError(t, r.DrawRect(&Rect{X: 1, Y: 0, W: 4, H: 3}))
+	require.NoError(t, r.DrawPoint(0, 3))
+	assert.Equal(t, [][]uint32{
+		{o, x, x, x, x, o},
+		{o, x, o, o, x, o},
+		{o, x, x, x, x, o},
+		{x, o, o, o, o, o},
+	}, surfacePixels(s, Rect{W: 6, H: 4}))
+
+	require.NoError(t, r.FillRect(nil))
+	assert.Equal(t, uint32(x), s.getPixel(0, 0))
+	require.NoError(t, r.FillRects([]Rect{{X: 4, Y: 2, W: 9, H: 9}}))
+
+	// blending with the draw color
+	r.SetDrawColor(0, 0, 0, 0x80)
+	require.NoError(t, r.SetDrawBlendMode(BlendModeBlend))
+	assert.Equal(t, uint32(BlendModeBlend), r.GetDrawBlendMode())
+	require.NoError(t, r.DrawLine(0, 1, 5, 1))
+	assert.Equal(t, MapRGBA(s.format, 0x7F, 0x7F, 0x7F, 0xFF), s.getPixel(5, 1))
+	require.NoError(t, r.DrawRects([]Rect{{X: 0, Y: 3, W: 6, H: 1}}))
+	assert.Equal(t, MapRGBA(s.format, 0x7F, 0x7F, 0x7F, 0xFF), s.getPixel(3, 3))
+	assert.Error(t, r.SetDrawBlendMode(3))
+
+	// Clear ignores the blend mode
+	require.NoError(t, r.Clear())
+	assert.Equal(t, uint32(0x80000000), s.getPixel(2, 2))
+	require.NoError(t, r.Present())
+
+	DestroyRenderer(r)
+	assert.Error(t, r.Clear())
+	assert.Error(t, r.DrawPoint(0, 0))
+	var none *Renderer
+	assert.Error(t, none.Present())
+}
+
+func TestRendererCopy(t *testing.T) {
+	r, s := newTestRenderer(t, 4, 4)
+	const a, b, c, d = 0xFF0000FF, 0xFF00FF00, 0xFFFF0000, 0xFFFFFFFF
+	tex := newTestTexture(t, r, [][]uint32{{a, b}, {c, d}})
+
+	require.NoError(t, r.Copy(tex, nil, nil))
+	assert.Equal(t, [][]uint32{
+		{a, a, b, b},
+		{a, a, b, b},
+		{c, c, d, d},
+		{c, c, d, d},
+	}, surfacePixels(s, Rect{W: 4, H: 4}))
+
+	// the source rect is clipped to the texture, the destination isn't moved
+	require.NoError(t, r.Copy(tex, &Rect{X: 1, Y: 1, W: 5, H: 5}, &Rect{X: 3, Y: 3, W: 1, H: 1}))
+	assert.Equal(t, uint32(d), s.getPixel(3, 3))
+
+	// modulation and blending come from the texture
+	tex.r, tex.g, tex.b = 0x80, 0x80, 0x80
+	tex.a = 0x80
+	tex.blendMode = BlendModeBlend
+	require.NoError(t, r.SetDrawBlendMode(BlendModeNone))
+	r.SetDrawColor(0, 0, 0, 0xFF)
+	require.NoError(t, r.Clear())
+	require.NoError(t, r.Copy(tex, &Rect{X: 1, Y: 1, W: 1, H: 1}, &Rect{W: 1, H: 1}))
+	assert.Equal(t, MapRGBA(s.format, 0x40, 0x40, 0x40, 0xFF), s.getPixel(0, 0))
+
+	other, _ := newTestRenderer(t, 1, 1)
+	assert.Error(t, other.Copy(tex, nil, nil))
+	assert.Error(t, r.Copy(nil, nil, nil))
+}
+
+func TestRendererCopyEx(t *testing.T) {
+	r, s := newTestRenderer(t, 4, 4)
+	const a, b, c, d = 0xFF0000FF, 0xFF00FF00, 0xFFFF0000, 0xFFFFFFFF
+	tex := newTestTexture(t, r, [][]uint32{{a, b}, {c, d}})
+	area := Rect{X: 1, Y: 1, W: 2, H: 2}
+
+	for _, tc := range []struct {
+		angle float64
+		flip  int
+		want  [][]uint32
+	}{
+		{0, FlipNone, [][]uint32{{a, b}, {c, d}}},
+		{90, FlipNone, [][]uint32{{c, a}, {d, b}}},
+		{180, FlipNone, [][]uint32{{d, c}, {b, a}}},
+		{-90, FlipNone, [][]uint32{{b, d}, {a, c}}},
+		{360, FlipHorizontal, [][]uint32{{b, a}, {d, c}}},
+		{0, FlipVertical, [][]uint32{{c, d}, {a, b}}},
+		{90, FlipHorizontal, [][]uint32{{d, b}, {c, a}}},
+	} {
+		require.NoError(t, r.Clear())
+		require.NoError(t, r.CopyEx(tex, nil, &area, tc.angle, nil, tc.flip))
+		assert.Equal(t, tc.want, surfacePixels(s, area), "angle %v flip %d", tc.angle, tc.flip)
+		assert.Equal(t, uint32(0), s.getPixel(0, 0))
+	}
+
+	// rotating a wide rect around its corner
+	require.NoError(t, r.Clear())
+	require.NoError(t, r.CopyEx(tex, &Rect{W: 2, H: 1}, &Rect{X: 2, Y: 0, W: 2, H: 1}, 90, &Point{}, FlipNone))
+	assert.Equal(t, [][]uint32{{0, a, 0, 0}, {0, b, 0, 0}, {0, 0, 0, 0}}, surfacePixels(s, Rect{W: 4, H: 3}))
+
+	// a rotation at 45 degrees stays within its bounds
+	require.NoError(t, r.Clear())
+	require.NoError(t, r.CopyEx(tex, nil, &area, 45, nil, FlipNone))
+	for _, p := range []Point{{0, 0}, {3, 0}, {0, 3}, {3, 3}} {
+		assert.Equal(t, uint32(0), s.getPixel(p.X, p.Y))
+	}
+	assert.NotEqual(t, uint32(0), s.getPixel(1, 1))
+}
+
+func TestRendererLogicalSize(t *testing.T) {
+	defer hint.ClearHints()
+	for _, tc := range []struct {
+		w, h     int
+		integer  bool
+		mode     string
+		viewport Rect
+		scale    float32
+	}{
+		{10, 4, false, "", Rect{X: 3, W: 4, H: 4}, 2},
+		{4, 10, false, "", Rect{Y: 3, W: 4, H: 4}, 2},
+		{10, 4, false, "overscan", Rect{Y: -3, W: 10, H: 10}, 5},
+		{9, 9, false, "", Rect{W: 9, H: 9}, 4.5},
+		{9, 9, true, "", Rect{W: 8, H: 8}, 4},
+		{1, 1, true, "", Rect{W: 2, H: 2}, 1},
+	} {
+		hint.SetHint(hint.RenderLogicalSizeMode, tc.mode)
+		r, s := newTestRenderer(t, tc.w, tc.h)
+		require.NoError(t, r.SetIntegerScale(tc.integer))
+		require.NoError(t, r.SetLogicalSize(2, 2))
+		assert.Equal(t, tc.viewport, r.viewport, "%dx%d", tc.w, tc.h)
+		sx, sy := r.GetScale()
+		assert.Equal(t, []float32{tc.scale, tc.scale}, []float32{sx, sy}, "%dx%d", tc.w, tc.h)
+		if tc.mode == "" {
+			clip, _ := IntersectRect(tc.viewport, Rect{W: tc.w, H: tc.h})
+			assert.Equal(t, clip, s.GetClipRect())
+		}
+	}
+
+	r, _ := newTestRenderer(t, 10, 4)
+	require.NoError(t, r.SetLogicalSize(2, 2))
+	w, h := r.GetLogicalSize()
+	assert.Equal(t, []int{2, 2}, []int{w, h})
+	assert.Equal(t, Rect{X: 1, W: 2, H: 2}, r.GetViewport())
+	require.NoError(t, r.SetLogicalSize(0, 0))
+	assert.Equal(t, Rect{W: 10, H: 4}, r.GetViewport())
+	sx, _ := r.GetScale()
+	assert.Equal(t, float32(1), sx)
+	assert.Error(t, r.SetLogicalSize(-1, 2))
+}
+
+func TestRendererScale(t *testing.T) {
+	r, s := newTestRenderer(t, 6, 4)
+	const o, x = 0, 0xFFFFFFFF
+	require.NoError(t, r.SetLogicalSize(3, 2))
+	r.SetDrawColor(0xFF, 0xFF, 0xFF, 0xFF)
+	require.NoError(t, r.DrawPoint(1, 0))
+	require.NoError(t, r.DrawLine(0, 1, 1, 1))
+	assert.Equal(t, [][]uint32{
+		{o, o, x, x, o, o},
+		{o, o, x, x, o, o},
+		{x, x, x, x, o, o},
+		{x, x, x, x, o, o},
+	}, surfacePixels(s, Rect{W: 6, H: 4}))
+
+	// the clip rect is relative to the viewport and in logical coordinates
+	r.SetDrawColor(0, 0, 0, 0)
+	require.NoError(t, r.Clear())
+	r.SetDrawColor(0xFF, 0xFF, 0xFF, 0xFF)
+	require.NoError(t, r.SetClipRect(&Rect{X: 1, W: 1, H: 2}))
+	assert.True(t, r.IsClipEnabled())
+	assert.Equal(t, Rect{X: 1, W: 1, H: 2}, r.GetClipRect())
+	require.NoError(t, r.FillRect(nil))
+	assert.Equal(t, [][]uint32{{o, o, x, x, o, o}}, surfacePixels(s, Rect{Y: 3, W: 6, H: 1}))
+	require.NoError(t, r.SetClipRect(nil))
+	assert.False(t, r.IsClipEnabled())
+	assert.Equal(t, Rect{W: 6, H: 4}, s.GetClipRect())
+
+	// a scale that isn't whole leaves no gaps between neighbours
+	require.NoError(t, r.SetLogicalSize(0, 0))
+	require.NoError(t, r.SetScale(1.5, 1))
+	r.SetDrawColor(0, 0, 0, 0)
+	require.NoError(t, r.Clear())
+	r.SetDrawColor(0xFF, 0xFF, 0xFF, 0xFF)
+	require.NoError(t, r.DrawPoints([]Point{{0, 0}, {1, 0}, {3, 0}}))
+	assert.Equal(t, [][]uint32{{x, x, x, o, x, x}}, surfacePixels(s, Rect{W: 6, H: 1}))
+	assert.Error(t, r.SetScale(0, 1))
+
+	// the viewport moves and clips drawing
+	require.NoError(t, r.SetScale(1, 1))
+	require.NoError(t, r.SetViewport(&Rect{X: 4, Y: 1, W: 2, H: 2}))
+	assert.Equal(t, Rect{X: 4, Y: 1, W: 2, H: 2}, r.GetViewport())
+	require.NoError(t, r.Clear())
+	require.NoError(t, r.SetDrawBlendMode(BlendModeNone))
+	r.SetDrawColor(0, 0, 0, 0)
+	require.NoError(t, r.DrawLine(0, 0, 5, 0))
+	assert.Equal(t, [][]uint32{{x, x, x, x, o, o}}, surfacePixels(s, Rect{Y: 1, W: 6, H: 1}))
+	require.NoError(t, r.SetViewport(nil))
+	assert.Equal(t, Rect{W: 6, H: 4}, r.GetViewport())
+}
+
+func TestRendererViewportFollowsScale(t *testing.T) {
+	r, s := newTestRenderer(t, 8, 8)
+	const o, x = 0, 0xFFFFFFFF
+	require.NoError(t, r.SetViewport(&Rect{X: 1, Y: 1, W: 2, H: 2}))
+	require.NoError(t, r.SetClipRect(&Rect{W: 1, H: 2}))
+	require.NoError(t, r.SetScale(2, 2))
+	assert.Equal(t, Rect{X: 1, Y: 1, W: 2, H: 2}, r.GetViewport())
+	assert.Equal(t, Rect{W: 1, H: 2}, r.GetClipRect())
+	assert.Equal(t, Rect{X: 2, Y: 2, W: 4, H: 4}, r.viewport)
+	assert.Equal(t, Rect{W: 2, H: 4}, r.clipRect)
+
+	// drawing uses the scaled viewport and clip rect
+	r.SetDrawColor(0xFF, 0xFF, 0xFF, 0xFF)
+	require.NoError(t, r.FillRect(nil))
+	assert.Equal(t, [][]uint32{
+		{o, o, o, o, o, o},
+		{o, x, x, o, o, o},
+		{o, x, x, o, o, o},
+		{o, x, x, o, o, o},
+		{o, x, x, o, o, o},
+		{o, o, o, o, o, o},
+	}, surfacePixels(s, Rect{X: 1, Y: 1, W: 6, H: 6}))
+
+	// a scale that isn't whole doesn't wear the rects down
+	for _, scale := range []float32{1.3, 0.7, 1.1, 1} {
+		require.NoError(t, r.SetScale(scale, scale))
+	}
+	assert.Equal(t, Rect{X: 1, Y: 1, W: 2, H: 2}, r.GetViewport())
+	assert.Equal(t, Rect{W: 1, H: 2}, r.GetClipRect())
+	assert.Equal(t, Rect{X: 1, Y: 1, W: 2, H: 2}, r.viewport)
+}
+
+func TestRendererEventWatch(t *testing.T) {
+	r, _ := newTestRenderer(t, 10, 4)
+	r.window = &Window{id: 9}
+	require.NoError(t, r.SetLogicalSize(2, 2))
+
+	watch := func(ev event.Data) event.Data {
+		assert.True(t, rendererEventWatch(r, &ev))
+		return ev
+	}
+	mme := event.MouseMotionEvent(watch(event.NewMouseMotionEvent(9, 0, 0, 5, 3, 3, -1)))
+	assert.Equal(t, []int32{1, 1, 1, 0}, []int32{mme.X(), mme.Y(), mme.XRel(), mme.YRel()})
+	mme = event.MouseMotionEvent(watch(event.NewMouseMotionEvent(9, 0, 0, 1, 0, 1, -1)))
+	assert.Equal(t, []int32{-1, 0, 1, -1}, []int32{mme.X(), mme.Y(), mme.XRel(), mme.YRel()})
+	mbe := event.MouseButton(watch(event.NewMouseButtonEvent(event.MouseButtonDown, 9, 0, 1, 1, 1, 6, 2)))
+	assert.Equal(t, []int32{1, 1}, []int32{mbe.X(), mbe.Y()})
+	tfe := event.TouchFinger(watch(event.NewTouchFingerEvent(event.FingerDown, 0, 0, 0.5, 0.25, 0, 0, 1, 9)))
+	assert.InDelta(t, 0.5, tfe.X(), 1e-6)
+	assert.InDelta(t, 0.25, tfe.Y(), 1e-6)
+	tfe = event.TouchFinger(watch(event.NewTouchFingerEvent(event.FingerMotion, 0, 0, 0.1, 0, 0, 0, 1, 9)))
+	assert.Equal(t, float32(0), tfe.X())
+
+	// events for other windows are left alone
+	mme = event.MouseMotionEvent(watch(event.NewMouseMotionEvent(3, 0, 0, 5, 3, 0, 0)))
+	assert.Equal(t, []int32{5, 3}, []int32{mme.X(), mme.Y()})
+
+	// the renderer follows the window visibility and size
+	watch(event.NewWindowEvent(9, event.WindowHidden, 0, 0))
+	assert.True(t, r.hidden)
+	assert.NoError(t, r.DrawPoint(0, 0))
+	watch(event.NewWindowEvent(9, event.WindowShown, 0, 0))
+	assert.False(t, r.hidden)
+	require.NoError(t, r.SetLogicalSize(0, 0))
+	require.NoError(t, r.SetViewport(&Rect{W: 1, H: 1}))
+	watch(event.NewWindowEvent(9, event.WindowResized, 10, 4))
+	assert.Equal(t, Rect{W: 10, H: 4}, r.viewport)
+}
+
+func TestRenderTarget(t *testing.T) {
+	r, s := newTestRenderer(t, 4, 4)
+	r.SetDrawColor(0, 0, 0, 0xFF)
+	require.NoError(t, r.Clear())
+	require.NoError(t, r.SetLogicalSize(2, 2))
+	require.NoError(t, r.SetClipRect(&Rect{W: 1, H: 1}))
+
+	target, err := r.CreateTexture(PixelFormatARGB8888, TextureAccessTarget, 3, 2)
+	require.NoError(t, err)
+	require.NoError(t, r.SetRenderTarget(target))
+	assert.Equal(t, target, r.GetRenderTarget())
+	assert.Equal(t, Rect{W: 3, H: 2}, r.GetViewport())
+	assert.Equal(t, FPoint{X: 1, Y: 1}, r.scale)
+	assert.False(t, r.IsClipEnabled())
+	w, h := r.GetLogicalSize()
+	assert.Equal(t, []int{0, 0}, []int{w, h})
+
+	// drawing goes to the texture only
+	r.SetDrawColor(0xFF, 0, 0, 0xFF)
+	require.NoError(t, r.Clear())
+	r.SetDrawColor(0, 0xFF, 0, 0xFF)
+	require.NoError(t, r.FillRect(&Rect{X: 1, W: 2, H: 1}))
+	const o, R, G = 0xFF000000, 0xFFFF0000, 0xFF00FF00
+	ts := target.driverData.(*Surface)
+	assert.Equal(t, [][]uint32{{R, G, G}, {R, R, R}}, surfacePixels(ts, Rect{W: 3, H: 2}))
+	assert.Equal(t, uint32(o), s.getPixel(0, 0))
+
+	// the state of the surface comes back with it
+	require.NoError(t, r.SetRenderTarget(nil))
+	assert.Nil(t, r.GetRenderTarget())
+	assert.Equal(t, Rect{W: 2, H: 2}, r.GetViewport())
+	assert.Equal(t, FPoint{X: 2, Y: 2}, r.scale)
+	assert.True(t, r.IsClipEnabled())
+	assert.Equal(t, Rect{W: 1, H: 1}, r.GetClipRect())
+	require.NoError(t, r.SetClipRect(nil))
+	require.NoError(t, r.Copy(target, &Rect{W: 2, H: 2}, nil))
+	assert.Equal(t, [][]uint32{
+		{R, R, G, G},
+		{R, R, G, G},
+		{R, R, R, R},
+		{R, R, R, R},
+	}, surfacePixels(s, Rect{W: 4, H: 4}))
+
+	// destroying the target draws to the surface again
+	require.NoError(t, r.SetRenderTarget(target))
+	DestroyTexture(target)
+	assert.Nil(t, r.GetRenderTarget())
+	assert.Equal(t, Rect{W: 2, H: 2}, r.GetViewport())
+
+	static, err := r.CreateTexture(PixelFormatARGB8888, TextureAccessStatic, 1, 1)
+	require.NoError(t, err)
+	assert.Error(t, r.SetRenderTarget(static))
+	other, _ := newTestRenderer(t, 1, 1)
+	otherTarget, err := other.CreateTexture(PixelFormatARGB8888, TextureAccessTarget, 1, 1)
+	require.NoError(t, err)
+	assert.Error(t, r.SetRenderTarget(otherTarget))
+}
+
+func TestRenderTargetNative(t *testing.T) {
+	r, _ := newTestRenderer(t, 2, 2)
+	target, err := r.CreateTexture(PixelFormatARGB4444, TextureAccessTarget, 2, 1)
+	require.NoError(t, err)
+	require.NotNil(t, target.native)
+	require.NoError(t, r.SetRenderTarget(target))
+	assert.Equal(t, target, r.GetRenderTarget())
+	r.SetDrawColor(0, 0, 0xFF, 0xFF)
+	require.NoError(t, r.DrawPoint(1, 0))
+	ns := target.native.driverData.(*Surface)
+	assert.Equal(t, [][]uint32{{0, 0xFF0000FF}}, surfacePixels(ns, Rect{W: 2, H: 1}))
+}
+
+func TestRenderTargetsReset(t *testing.T) {
+	require.NoError(t, event.Q.FlushType(event.RenderTargetsReset))
+	r, s := newTestRenderer(t, 2, 2)
+	r.window = &Window{id: 4}
+	target, err := r.CreateTexture(PixelFormatARGB8888, TextureAccessTarget, 1, 1)
+	require.NoError(t, err)
+
+	// drawing to a target goes on while the window is hidden, and the window
+	// viewport keeps mapping the mouse
+	require.NoError(t, r.SetRenderTarget(target))
+	assert.True(t, rendererEventWatch(r, event.NewWindowEvent(4, event.WindowHidden, 0, 0).Raw()))
+	r.SetDrawColor(0xFF, 0xFF, 0xFF, 0xFF)
+	require.NoError(t, r.Clear())
+	assert.Equal(t, uint32(0xFFFFFFFF), target.driverData.(*Surface).getPixel(0, 0))
+	assert.True(t, rendererEventWatch(r, event.NewWindowEvent(4, event.WindowShown, 0, 0).Raw()))
+	require.NoError(t, r.SetRenderTarget(nil))
+	require.NoError(t, r.SetViewport(&Rect{X: 1, W: 1, H: 1}))
+	require.NoError(t, r.SetRenderTarget(target))
+	ev := event.NewMouseButtonEvent(event.MouseButtonDown, 4, 0, 1, 1, 1, 1, 1)
+	assert.True(t, rendererEventWatch(r, &ev))
+	mbe := event.MouseButton(ev)
+	assert.Equal(t, []int32{0, 1}, []int32{mbe.X(), mbe.Y()})
+
+	// a resize updates the window viewport, not the target one
+	assert.True(t, rendererEventWatch(r, event.NewWindowEvent(4, event.WindowResized, 2, 2).Raw()))
+	assert.Equal(t, target, r.GetRenderTarget())
+	assert.Equal(t, Rect{W: 1, H: 1}, r.viewport)
+	assert.Equal(t, Rect{W: 2, H: 2}, r.viewportBackup)
+
+	// losing the target falls back to the surface with an event
+	FreeSurface(target.driverData.(*Surface))
+	require.NoError(t, r.SetRenderTarget(nil))
+	assert.Error(t, r.SetRenderTarget(target))
+	assert.Nil(t, r.GetRenderTarget())
+	assert.Equal(t, Rect{W: 2, H: 2}, r.viewport)
+	reset, err := event.Q.HasType(event.RenderTargetsReset)
+	require.NoError(t, err)
+	assert.True(t, reset)
+	require.NoError(t, event.Q.FlushType(event.RenderTargetsReset))
+	r.SetDrawColor(0, 0, 0, 0xFF)
+	require.NoError(t, r.Clear())
+	assert.Equal(t, uint32(0xFF000000), s.getPixel(1, 1))
+
+	// a target lost during a resize is reset from inside the event watcher
+	target, err = r.CreateTexture(PixelFormatARGB8888, TextureAccessTarget, 1, 1)
+	require.NoError(t, err)
+	require.NoError(t, r.SetRenderTarget(target))
+	FreeSurface(target.driverData.(*Surface))
+	watcher := &event.Watcher{Callback: rendererEventWatch, Userdata: r}
+	event.Q.AddWatch(watcher)
+	done := make(chan struct{})
+	go func() {
+		event.Q.Push(event.NewWindowEvent(4, event.WindowResized, 2, 2))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("resetting the targets from the event watcher deadlocked")
+	}
+	event.Q.DelWatch(watcher)
+	assert.Nil(t, r.GetRenderTarget())
+	reset, err = event.Q.HasType(event.RenderTargetsReset)
+	require.NoError(t, err)
+	assert.True(t, reset)
+	require.NoError(t, event.Q.FlushType(event.RenderTargetsReset))
+	require.NoError(t, event.Q.FlushType(event.WindowStateChange))
+}
+
+// checkerTexture creates a texture of n by n squares alternating between two
+// colors.
+func checkerTexture(t *testing.T, r *Renderer, n int, c0, c1 uint32) *Texture {
+	rows := make([][]uint32, n)
+	for y := range rows {
+		rows[y] = make([]uint32, n)
+		for x := range rows[y] {
+			rows[y][x] = c0
+			if (x+y)%2 == 1 {
+				rows[y][x] = c1
+			}
+		}
+	}
+	return newTestTexture(t, r, rows)
+}
+
+func TestRenderGeometry(t *testing.T) {
+	white := Color{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	quad := func(x, y, w, h float32, c Color, u0, v0, u1, v1 float32) []Vertex {
+		return []Vertex{
+			{Position: FPoint{x, y}, Color: c, TexCoord: FPoint{u0, v0}},
+			{Position: FPoint{x + w, y}, Color: c, TexCoord: FPoint{u1, v0}},
+			{Position: FPoint{x + w, y + h}, Color: c, TexCoord: FPoint{u1, v1}},
+			{Position: FPoint{x, y + h}, Color: c, TexCoord: FPoint{u0, v1}},
+		}
+	}
+	quadIndices := []int{0, 1, 2, 0, 2, 3}
+
+	t.Run("colors", func(t *testing.T) {
+		r, s := newTestRenderer(t, 24, 24)
+		r.SetDrawColor(0x20, 0x20, 0x20, 0xFF)
+		require.NoError(t, r.Clear())
+		require.NoError(t, r.RenderGeometry(nil, []Vertex{
+			{Position: FPoint{12, 1.5}, Color: Color{R: 0xFF, A: 0xFF}},
+			{Position: FPoint{22.5, 21}, Color: Color{G: 0xFF, A: 0xFF}},
+			{Position: FPoint{1, 17.25}, Color: Color{B: 0xFF, A: 0xFF}},
+		}, nil))
+		requireGolden(t, s, "geometry_colors.bmp")
+	})
+
+	t.Run("texture", func(t *testing.T) {
+		r, s := newTestRenderer(t, 24, 24)
+		r.SetDrawColor(0x20, 0x20, 0x20, 0xFF)
+		require.NoError(t, r.Clear())
+		tex := checkerTexture(t, r, 4, 0xFFFFFFFF, 0xFF3060C0)
+		require.NoError(t, r.RenderGeometry(tex, quad(1, 1, 10, 10, white, 0, 0, 1, 1), quadIndices))
+		half := quad(13, 1, 10, 10, Color{R: 0xFF, G: 0x80, B: 0x80, A: 0xFF}, 0.25, 0.25, 0.75, 0.75)
+		require.NoError(t, r.RenderGeometry(tex, half, quadIndices))
+
+		// a rotated quad with the texture modulation
+		tex.SetColorMod(0xFF, 0xFF, 0x40)
+		require.NoError(t, r.RenderGeometry(tex, []Vertex{
+			{Position: FPoint{12, 12.5}, Color: white, TexCoord: FPoint{0, 0}},
+			{Position: FPoint{22.5, 17}, Color: white, TexCoord: FPoint{1, 0}},
+			{Position: FPoint{17, 23}, Color: white, TexCoord: FPoint{1, 1}},
+			{Position: FPoint{6.5, 18.5}, Color: white, TexCoord: FPoint{0, 1}},
+		}, quadIndices))
+		requireGolden(t, s, "geometry_texture.bmp")
+	})
+
+	t.Run("blend", func(t *testing.T) {
+		r, s := newTestRenderer(t, 24, 24)
+		for i, c := range []uint32{0xFF000000, 0xFFFFFFFF, 0xFF808080, 0xFF2080E0} {
+			require.NoError(t, s.FillRect(&Rect{Y: i * 6, W: 24, H: 6}, c))
+		}
+		c := Color{R: 0xFF, G: 0x80, A: 0x80}
+		for i, mode := range []uint32{BlendModeNone, BlendModeBlend, BlendModeAdd, BlendModeMod, BlendModeMul} {
+			require.NoError(t, r.SetDrawBlendMode(mode))
+			x := float32(i*5) - 1
+			require.NoError(t, r.RenderGeometry(nil, []Vertex{
+				{Position: FPoint{x, 0}, Color: c},
+				{Position: FPoint{x + 6, 24}, Color: c},
+				{Position: FPoint{x, 24}, Color: c},
+			}, nil))
+		}
+
+		// textures use their own blend mode
+		require.NoError(t, r.SetDrawBlendMode(BlendModeNone))
+		tex := checkerTexture(t, r, 2, 0x80FFFFFF, 0x00000000)
+		require.NoError(t, tex.SetBlendMode(BlendModeBlend))
+		require.NoError(t, r.RenderGeometry(tex, quad(16, 2, 6, 20, white, 0, 0, 1, 1), quadIndices))
+		requireGolden(t, s, "geometry_blend.bmp")
+	})
+
+	t.Run("viewport", func(t *testing.T) {
+		r, s := newTestRenderer(t, 24, 24)
+		r.SetDrawColor(0x20, 0x20, 0x20, 0xFF)
+		require.NoError(t, r.Clear())
+		require.NoError(t, r.SetScale(2, 2))
+		require.NoError(t, r.SetViewport(&Rect{X: 1, Y: 2, W: 10, H: 8}))
+		require.NoError(t, r.SetClipRect(&Rect{W: 8, H: 6}))
+		require.NoError(t, r.RenderGeometry(nil, []Vertex{
+			{Position: FPoint{1, 1}, Color: Color{R: 0xFF, A: 0xFF}},
+			{Position: FPoint{12, 2}, Color: Color{R: 0xFF, G: 0xFF, A: 0xFF}},
+			{Position: FPoint{3, 9}, Color: Color{B: 0xFF, A: 0xFF}},
+		}, nil))
+		requireGolden(t, s, "geometry_viewport.bmp")
+	})
+}
+
+func TestRenderGeometryArguments(t *testing.T) {
+	r, s := newTestRenderer(t, 4, 4)
+	square := []Vertex{
+		{Position: FPoint{0, 0}, Color: Color{R: 0x40, A: 0xFF}},
+		{Position: FPoint{4, 0}, Color: Color{R: 0x40, A: 0xFF}},
+		{Position: FPoint{4, 4}, Color: Color{R: 0x40, A: 0xFF}},
+		{Position: FPoint{0, 4}, Color: Color{R: 0x40, A: 0xFF}},
+	}
+
+	// triangles sharing an edge cover each pixel once
+	require.NoError(t, r.SetDrawBlendMode(BlendModeAdd))
+	require.NoError(t, r.RenderGeometry(nil, square, []int{0, 1, 3, 1, 2, 3}))
+	for _, row := range surfacePixels(s, Rect{W: 4, H: 4}) {
+		assert.Equal(t, []uint32{0x00400000, 0x00400000, 0x00400000, 0x00400000}, row)
+	}
+	require.NoError(t, r.RenderGeometry(nil, square[:3], nil))
+	assert.Equal(t, uint32(0x00800000), s.getPixel(3, 0))
+	assert.Equal(t, uint32(0x00400000), s.getPixel(0, 3))
+
+	assert.Error(t, r.RenderGeometry(nil, square, nil))
+	assert.Error(t, r.RenderGeometry(nil, square, []int{0, 1}))
+	assert.Error(t, r.RenderGeometry(nil, square, []int{0, 1, 4}))
+	assert.Error(t, r.RenderGeometry(nil, []Vertex{{}, {}, {Position: FPoint{float32(math.NaN()), 0}}}, nil))
+	other, _ := newTestRenderer(t, 1, 1)
+	tex := checkerTexture(t, other, 1, 0, 0)
+	assert.Error(t, r.RenderGeometry(tex, square, []int{0, 1, 2}))
+	assert.NoError(t, r.RenderGeometry(nil, nil, nil))
+}
+
+// fakeRenderDriver draws to its own surface instead of the window.
+type fakeRenderDriver struct {
+	name  string
+	flags uint32
+	fail  bool
+}
+
+func (d fakeRenderDriver) Info() RendererInfo {
+	info := swRenderDriver{}.Info()
+	info.name, info.flags = d.name, d.flags
+	info.maxTextureWidth, info.maxTextureHeight = 64, 32
+	return info
+}
+
+func (d fakeRenderDriver) CreateRenderer(window *Window, flags uint32) (*Renderer, error) {
+	if d.fail {
+		return nil, errors.New("no device")
+	}
+	s, err := CreateRGBSurfaceWithFormat(0, 4, 4, 0, PixelFormatARGB8888)
+	if err != nil {
+		return nil, err
+	}
+	info := d.Info()
+	if flags&RendererPresentVSync == 0 {
+		info.flags &^= RendererPresentVSync
+	}
+	return newRenderer(info, &swRenderData{surface: s})
+}
+
+func TestRenderDrivers(t *testing.T) {
+	saved := renderDrivers
+	defer func() { renderDrivers = saved }()
+	defer hint.ClearHints()
+
+	require.Equal(t, 1, GetNumRenderDrivers())
+	info, err := GetRenderDriverInfo(0)
+	require.NoError(t, err)
+	assert.Equal(t, "software", info.Name())
+	assert.Equal(t, uint32(RendererSoftware|RendererTargetRexture), info.Flags())
+	assert.Contains(t, info.TextureFormats(), uint32(PixelFormatARGB8888))
+	w, h := info.MaxTextureSize()
+	assert.Equal(t, []int{0, 0}, []int{w, h})
+	_, err = GetRenderDriverInfo(1)
+	assert.Error(t, err)
+
+	registerRenderDriver(fakeRenderDriver{name: "fast", flags: RendererAccelerated | RendererPresentVSync})
+	registerRenderDriver(fakeRenderDriver{name: "broken", flags: RendererAccelerated, fail: true})
+	require.Equal(t, 3, GetNumRenderDrivers())
+	info, err = GetRenderDriverInfo(1)
+	require.NoError(t, err)
+	assert.Equal(t, "fast", info.Name())
+
+	create := func(index int, flags uint32) string {
+		window := &Window{id: 5, data: map[string]interface{}{}}
+		r, err := CreateRenderer(window, index, flags)
+		if err != nil {
+			return err.Error()
+		}
+		defer DestroyRenderer(r)
+		assert.Equal(t, r, GetRenderer(window))
+		info, err := r.GetRendererInfo()
+		require.NoError(t, err)
+		return info.Name()
+	}
+
+	// the first driver with the flags that works
+	assert.Equal(t, "fast", create(-1, 0))
+	assert.Equal(t, "fast", create(-1, RendererPresentVSync))
+	assert.Contains(t, create(-1, RendererAccelerated|RendererTargetRexture), "couldn't find matching render driver")
+	assert.Contains(t, create(0, 0), "no device")
+	assert.Equal(t, "fast", create(1, RendererSoftware))
+	assert.Contains(t, create(3, 0), "index must be -1 or in the range of 0 - 2")
+	assert.Contains(t, create(-2, 0), "index must be -1")
+
+	// the hint names a driver, ignoring case and the flags
+	hint.SetHint(hint.RenderDriver, "FAST")
+	assert.Equal(t, "fast", create(-1, RendererSoftware))
+	hint.SetHint(hint.RenderDriver, "broken")
+	assert.Equal(t, "fast", create(-1, 0))
+	hint.ClearHints()
+
+	// vsync doesn't rule out drivers, the software driver is tried but can't
+	// draw to the test window
+	assert.Contains(t, create(-1, RendererSoftware|RendererPresentVSync), "unable to create window framebuffer")
+	hint.SetHint(hint.RenderVSync, "1")
+	assert.Contains(t, create(-1, RendererSoftware), "unable to create window framebuffer")
+
+	// the vsync hint overrides the vsync flag
+	vsync := func(flags uint32) uint32 {
+		window := &Window{id: 6, data: map[string]interface{}{}}
+		r, err := CreateRenderer(window, 1, flags)
+		require.NoError(t, err)
+		defer DestroyRenderer(r)
+		return r.info.flags & RendererPresentVSync
+	}
+	assert.Equal(t, uint32(RendererPresentVSync), vsync(0))
+	hint.SetHint(hint.RenderVSync, "0")
+	assert.Equal(t, uint32(0), vsync(RendererPresentVSync))
+	hint.ClearHints()
+	assert.Equal(t, uint32(RendererPresentVSync), vsync(RendererPresentVSync))
+
+	r, _ := newTestRenderer(t, 1, 1)
+	info, err = r.GetRendererInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "software", info.Name())
+	DestroyRenderer(r)
+	_, err = r.GetRendererInfo()
+	assert.Error(t, err)
+}
+
+func TestRenderReadPixels(t *testing.T) {
+	r, s := newTestRenderer(t, 4, 3)
+	const o, R, G, B = 0xFF000000, 0xFFFF0000, 0xFF00FF00, 0xFF0000FF
+	rows := [][]uint32{{R, G, B, o}, {G, B, o, R}, {B, o, R, G}}
+	for y, row := range rows {
+		for x, p := range row {
+			s.setPixel(x, y, p)
+		}
+	}
+
+	pixels, pitch, err := r.RenderReadPixels(nil, PixelFormatARGB8888)
+	require.NoError(t, err)
+	assert.Equal(t, 16, pitch)
+	assert.Equal(t, s.pixels, pixels)
+
+	pixels, pitch, err = r.RenderReadPixels(&Rect{X: 1, Y: 1, W: 3, H: 2}, PixelFormatRGB24)
+	require.NoError(t, err)
+	assert.Equal(t, 12, pitch)
+	assert.Equal(t, []byte{
+		0, 0, 0xFF, 0, 0, 0, 0xFF, 0, 0, 0, 0, 0,
+		0, 0, 0, 0xFF, 0, 0, 0, 0xFF, 0,
+	}, pixels[:21])
+
+	// the rect is relative to the viewport and scaled, outside it is zero
+	require.NoError(t, r.SetScale(2, 1))
+	require.NoError(t, r.SetViewport(&Rect{X: 1, W: 1, H: 3}))
+	pixels, pitch, err = r.RenderReadPixels(&Rect{X: 0, Y: 2, W: 2, H: 2}, PixelFormatABGR8888)
+	require.NoError(t, err)
+	require.Equal(t, 16, pitch)
+	read, err := CreateRGBSurfaceWithFormatFrom(pixels, 4, 2, 0, pitch, PixelFormatABGR8888)
+	require.NoError(t, err)
+	assert.Equal(t, [][]uint32{{0xFF0000FF, 0xFF00FF00, 0, 0}, {0, 0, 0, 0}}, surfacePixels(read, Rect{W: 4, H: 2}))
+	pixels, _, err = r.RenderReadPixels(&Rect{}, PixelFormatARGB8888)
+	assert.NoError(t, err)
+	assert.Nil(t, pixels)
+
+	_, _, err = r.RenderReadPixels(nil, PixelFormatIndex8)
+	assert.Error(t, err)
+	_, _, err = r.RenderReadPixels(nil, PixelFormatUnknown)
+	assert.Error(t, err)
+	_, _, err = r.RenderReadPixels(nil, PixelFormatYV12)
+	assert.Error(t, err)
+}
+
+func TestScreenshot(t *testing.T) {
+	r, s := newTestRenderer(t, 3, 2)
+	r.SetDrawColor(0x10, 0x20, 0x30, 0xFF)
+	require.NoError(t, r.Clear())
+	require.NoError(t, r.SetViewport(&Rect{X: 1, W: 1, H: 1}))
+	r.SetDrawColor(0xFF, 0, 0, 0x80)
+	require.NoError(t, r.FillRect(nil))
+
+	shot, err := r.Screenshot()
+	require.NoError(t, err)
+	defer FreeSurface(shot)
+	assert.Equal(t, surfaceRGBA(s), surfaceRGBA(shot))
+	var img image.Image = shot
+	assert.Equal(t, color.NRGBA{R: 0xFF, A: 0x80}, img.At(1, 0))
+	assert.Equal(t, color.NRGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xFF}, img.At(2, 1))
+
+	// a render target is read instead of the surface
+	target, err := r.CreateTexture(PixelFormatRGB565, TextureAccessTarget, 2, 2)
+	require.NoError(t, err)
+	require.NoError(t, r.SetRenderTarget(target))
+	r.SetDrawColor(0, 0xFF, 0, 0xFF)
+	require.NoError(t, r.Clear())
+	shot, err = r.Screenshot()
+	require.NoError(t, err)
+	defer FreeSurface(shot)
+	assert.Equal(t, [][]uint32{{0xFF00FF00, 0xFF00FF00}, {0xFF00FF00, 0xFF00FF00}}, surfacePixels(shot, Rect{W: 2, H: 2}))
+	pixels, _, err := r.RenderReadPixels(&Rect{X: 1, Y: 1, W: 1, H: 1}, PixelFormatRGB565)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xE0, 0x07}, pixels[:2])
+}
@@ -1,6 +1,14 @@
 package video
 
-import "github.com/pkg/errors"
+import (
+	"math"
+	"strings"
+
+	"github.com/elliotmr/gdl/event"
+	"github.com/elliotmr/gdl/hint"
+	"github.com/elliotmr/gdl/log"
+	"github.com/pkg/errors"
+)
 
 const (
 	RendererSoftware      = 1 << iota // The renderer is a software fallback
@@ -9,6 +17,17 @@ const (
 	RendererTargetRexture             // The renderer supports rendering to texture
 )
 
+// Flip modes for CopyEx, they can be combined.
+const (
+	FlipNone       = 0
+	FlipHorizontal = 1
+	FlipVertical   = 2
+)
+
+// windowRenderDataKey is the window data key holding the window renderer.
+const windowRenderDataKey = "_GDL_WindowRenderData"
+
+// RendererInfo describes a render driver or a renderer.
 type RendererInfo struct {
 	name                              string
 	flags                             uint32
@@ -17,6 +36,35 @@ type RendererInfo struct {
 	maxTextureWidth, maxTextureHeight int
 }
 
+// Name returns the name of the driver, which the RenderDriver hint matches.
+func (ri RendererInfo) Name() string {
+	return ri.name
+}
+
+// Flags returns the Renderer* flags the driver supports.
+func (ri RendererInfo) Flags() uint32 {
+	return ri.flags
+}
+
+// TextureFormats returns the texture formats supported directly, other
+// formats are converted when drawing.
+func (ri RendererInfo) TextureFormats() []uint32 {
+	return append([]uint32(nil), ri.textureFormats[:ri.numTextureFormats]...)
+}
+
+// MaxTextureSize returns the largest texture size, 0 means no limit.
+func (ri RendererInfo) MaxTextureSize() (w, h int) {
+	return ri.maxTextureWidth, ri.maxTextureHeight
+}
+
+// Vertex is a corner of a triangle drawn by RenderGeometry. TexCoord is the
+// texture position, normalized to 0..1.
+type Vertex struct {
+	Position FPoint
+	Color    Color
+	TexCoord FPoint
+}
+
 type Renderer struct {
 	info RendererInfo
 
@@ -30,22 +78,61 @@ type Renderer struct {
 
 	integerScale bool
 
-	viewport       Rect
-	viewportBackup Rect
+	// The viewport and clip rect are kept in output pixels, and in logical
+	// coordinates as set so that a new scale applies to them
+	viewport              Rect
+	logicalViewport       Rect
+	viewportBackup        Rect
+	logicalViewportBackup Rect
 
+	clipRect              Rect
+	logicalClipRect       Rect
+	clipRectBackup        Rect
+	logicalClipRectBackup Rect
 	clippingEnabled       bool
 	clippingEnabledBackup bool
 
-	scale       Point
-	scaleBackup Point
+	scale       FPoint
+	scaleBackup FPoint
+
+	// Fractions of relative mouse motion left over from scaling
+	relX, relY float32
+
+	watcher *event.Watcher
 
 	textures *Texture
-	target   *Texture
+	target   *Texture // nil when drawing to the window or surface
 
 	r, g, b, a uint8
 	blendMode  uint32
 
-	driverData interface{}
+	driverData rendererImpl
+}
+
+// rendererImpl is the part of a renderer that a driver provides. Drawing
+// happens in output pixels relative to the viewport, with the draw color and
+// blend mode of the renderer.
+type rendererImpl interface {
+	outputSize() (w, h int, err error)
+	updateViewport(r *Renderer) error
+	updateClipRect(r *Renderer) error
+	setRenderTarget(r *Renderer, t *Texture) error
+	createTexture(r *Renderer, t *Texture) error
+	updateTexture(t *Texture, rect Rect, pixels []byte, pitch int) error
+	lockTexture(t *Texture, rect Rect) (pixels []byte, pitch int, err error)
+	unlockTexture(t *Texture)
+	destroyTexture(t *Texture)
+
+	clear(r *Renderer) error
+	drawPoints(r *Renderer, points []Point) error
+	drawLines(r *Renderer, points []Point) error
+	fillRects(r *Renderer, rects []Rect) error
+	copy(r *Renderer, t *Texture, src, dst Rect) error
+	copyEx(r *Renderer, t *Texture, src, dst Rect, angle float64, center Point, flip int) error
+	geometry(r *Renderer, t *Texture, vertices []Vertex, indices []int) error
+	readPixels(r *Renderer, rect Rect, format uint32, pixels []byte, pitch int) error
+	present(r *Renderer) error
+	destroy(r *Renderer)
 }
 
 type RenderDriver interface {
@@ -53,9 +140,954 @@ type RenderDriver interface {
 	Info() RendererInfo
 }
 
-func CreateRenderer(window *Window, flags uint32) (*Renderer, error) {
-	if window  == nil {
+// renderDrivers holds the render drivers in order of preference, with the
+// software driver as the last resort.
+var renderDrivers = []RenderDriver{swRenderDriver{}}
+
+// registerRenderDriver adds a driver, which is preferred over the drivers
+// registered before it.
+func registerRenderDriver(driver RenderDriver) {
+	renderDrivers = append([]RenderDriver{driver}, renderDrivers...)
+}
+
+// GetNumRenderDrivers returns the number of render drivers.
+func GetNumRenderDrivers() int {
+	return len(renderDrivers)
+}
+
+// GetRenderDriverInfo describes the render driver at index.
+func GetRenderDriverInfo(index int) (RendererInfo, error) {
+	if index < 0 || index >= len(renderDrivers) {
+		return RendererInfo{}, errors.Errorf("index must be in the range of 0 - %d", len(renderDrivers)-1)
+	}
+	return renderDrivers[index].Info(), nil
+}
+
+// CreateRenderer creates a renderer that draws to the window, a window has
+// at most one renderer. The driver at index is used, or with an index of -1
+// the driver named by hint.RenderDriver, and otherwise the first driver that
+// supports the flags. hint.RenderVSync adds or removes RendererPresentVSync
+// from the flags, vsync is only a request and doesn't rule out a driver.
+func CreateRenderer(window *Window, index int, flags uint32) (*Renderer, error) {
+	if window == nil {
 		return nil, errors.New("invalid window")
 	}
-	return nil, nil
+	if GetRenderer(window) != nil {
+		return nil, errors.New("renderer already associated with window")
+	}
+	if index < -1 || index >= len(renderDrivers) {
+		return nil, errors.Errorf("index must be -1 or in the range of 0 - %d", len(renderDrivers)-1)
+	}
+	if hint.GetHint(hint.RenderVSync) != "" {
+		if hint.GetHintBoolean(hint.RenderVSync, false) {
+			flags |= RendererPresentVSync
+		} else {
+			flags &^= RendererPresentVSync
+		}
+	}
+
+	var r *Renderer
+	var err error
+	if index >= 0 {
+		r, err = renderDrivers[index].CreateRenderer(window, flags)
+	} else {
+		r, err = createRendererByHint(window, flags)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't create renderer")
+	}
+	r.window = window
+	r.hidden = window.flags&(WindowHidden|WindowMinimized) > 0
+	window.data[windowRenderDataKey] = r
+	r.watcher = &event.Watcher{Callback: rendererEventWatch, Userdata: r}
+	event.Q.AddWatch(r.watcher)
+	return r, nil
+}
+
+// createRendererByHint tries the driver named by the hint, then each driver
+// that supports the flags other than RendererPresentVSync.
+func createRendererByHint(window *Window, flags uint32) (*Renderer, error) {
+	if name := hint.GetHint(hint.RenderDriver); name != "" {
+		for _, driver := range renderDrivers {
+			if !strings.EqualFold(driver.Info().name, name) {
+				continue
+			}
+			r, err := driver.CreateRenderer(window, flags)
+			if err == nil {
+				return r, nil
+			}
+			log.Error(log.CategoryRender, "render driver %s: %v", name, err)
+			break
+		}
+	}
+
+	required := flags &^ RendererPresentVSync
+	err := errors.New("couldn't find matching render driver")
+	for _, driver := range renderDrivers {
+		if driver.Info().flags&required != required {
+			continue
+		}
+		var r *Renderer
+		if r, err = driver.CreateRenderer(window, flags); err == nil {
+			return r, nil
+		}
+	}
+	return nil, err
+}
+
+// GetRenderer returns the renderer of the window, or nil.
+func GetRenderer(window *Window) *Renderer {
+	if window == nil {
+		return nil
+	}
+	r, _ := window.data[windowRenderDataKey].(*Renderer)
+	return r
+}
+
+// newRenderer sets up the renderer state around a driver once it knows its
+// output size.
+func newRenderer(info RendererInfo, impl rendererImpl) (*Renderer, error) {
+	r := &Renderer{
+		info:       info,
+		driverData: impl,
+		blendMode:  BlendModeNone,
+		scale:      FPoint{X: 1, Y: 1},
+	}
+	w, h, err := impl.outputSize()
+	if err != nil {
+		return nil, err
+	}
+	r.viewport = Rect{W: w, H: h}
+	r.logicalViewport = r.viewport
+	if err := impl.updateViewport(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DestroyRenderer releases the renderer and its textures, and detaches it
+// from its window.
+func DestroyRenderer(r *Renderer) {
+	if r == nil || r.driverData == nil {
+		return
+	}
+	for r.textures != nil {
+		DestroyTexture(r.textures)
+	}
+	if r.watcher != nil {
+		event.Q.DelWatch(r.watcher)
+		r.watcher = nil
+	}
+	r.driverData.destroy(r)
+	r.driverData = nil
+	if r.window != nil && GetRenderer(r.window) == r {
+		delete(r.window.data, windowRenderDataKey)
+	}
+}
+
+func (r *Renderer) check() error {
+	if r == nil || r.driverData == nil {
+		return errors.New("invalid renderer")
+	}
+	return nil
+}
+
+// renderingAllowed reports whether drawing has any effect, drawing to the
+// window is skipped while it is hidden.
+func (r *Renderer) renderingAllowed() bool {
+	return r.target != nil || !r.hidden
+}
+
+// GetRendererInfo describes the driver of the renderer.
+func (r *Renderer) GetRendererInfo() (RendererInfo, error) {
+	if err := r.check(); err != nil {
+		return RendererInfo{}, err
+	}
+	return r.info, nil
+}
+
+// GetOutputSize returns the size of the renderer output in pixels.
+func (r *Renderer) GetOutputSize() (w, h int, err error) {
+	if err := r.check(); err != nil {
+		return 0, 0, err
+	}
+	return r.driverData.outputSize()
+}
+
+// targetSize returns the size of the current render target in pixels.
+func (r *Renderer) targetSize() (w, h int, err error) {
+	if r.target != nil {
+		return r.target.w, r.target.h, nil
+	}
+	return r.driverData.outputSize()
+}
+
+// SetRenderTarget makes drawing go to a texture created with
+// TextureAccessTarget, or back to the window or surface for nil. Each target
+// starts with the whole texture as viewport, a scale of 1 and no clipping,
+// the state of the window is restored when switching back to it.
+func (r *Renderer) SetRenderTarget(texture *Texture) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if r.info.flags&RendererTargetRexture == 0 {
+		return errors.New("renderer doesn't support render targets")
+	}
+	if texture != nil {
+		if err := r.checkTexture(texture); err != nil {
+			return err
+		}
+		if texture.access != TextureAccessTarget {
+			return errors.New("texture not created with TextureAccessTarget")
+		}
+	}
+	if texture == r.target {
+		return nil
+	}
+
+	if r.target == nil {
+		r.viewportBackup, r.logicalViewportBackup = r.viewport, r.logicalViewport
+		r.clipRectBackup, r.logicalClipRectBackup = r.clipRect, r.logicalClipRect
+		r.clippingEnabledBackup = r.clippingEnabled
+		r.scaleBackup = r.scale
+		r.logicalWBackup, r.logicalHBackup = r.logicalW, r.logicalH
+	}
+	if err := r.driverData.setRenderTarget(r, renderTexture(texture)); err != nil {
+		r.targetsReset()
+		return errors.Wrap(err, "unable to set render target")
+	}
+	r.target = texture
+	if texture != nil {
+		r.viewport = Rect{W: texture.w, H: texture.h}
+		r.logicalViewport = r.viewport
+		r.clipRect, r.logicalClipRect = Rect{}, Rect{}
+		r.clippingEnabled = false
+		r.scale = FPoint{X: 1, Y: 1}
+		r.logicalW, r.logicalH = 0, 0
+	} else {
+		r.restoreWindowState()
+	}
+	return r.driverData.updateViewport(r)
+}
+
+// GetRenderTarget returns the texture being drawn to, or nil for the window
+// or surface.
+func (r *Renderer) GetRenderTarget() *Texture {
+	if r == nil {
+		return nil
+	}
+	return r.target
+}
+
+// restoreWindowState restores the viewport, clip and scale put aside when
+// the first render target was set.
+func (r *Renderer) restoreWindowState() {
+	r.viewport, r.logicalViewport = r.viewportBackup, r.logicalViewportBackup
+	r.clipRect, r.logicalClipRect = r.clipRectBackup, r.logicalClipRectBackup
+	r.clippingEnabled = r.clippingEnabledBackup
+	r.scale = r.scaleBackup
+	r.logicalW, r.logicalH = r.logicalWBackup, r.logicalHBackup
+}
+
+// targetsReset returns drawing to the window after the current render target
+// was lost, and tells the application with a RenderTargetsReset event that
+// the contents of its targets have to be drawn again.
+func (r *Renderer) targetsReset() {
+	if r.target != nil {
+		r.target = nil
+		r.restoreWindowState()
+		if err := r.driverData.setRenderTarget(r, nil); err != nil {
+			log.Error(log.CategoryRender, "unable to reset render target: %v", err)
+		}
+		if err := r.driverData.updateViewport(r); err != nil {
+			log.Error(log.CategoryRender, "unable to reset viewport: %v", err)
+		}
+	}
+	if event.Q.Enabled(event.RenderTargetsReset) {
+		event.Q.Push(event.NewRenderEvent(event.RenderTargetsReset))
+	}
+}
+
+// SetLogicalSize sets a device independent resolution for rendering. The
+// output is scaled to fit the logical size, centered with letterboxing or, if
+// hint.RenderLogicalSizeMode is "overscan", cropped. A zero size turns
+// logical sizing off.
+func (r *Renderer) SetLogicalSize(w, h int) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if w < 0 || h < 0 {
+		return errors.Errorf("invalid logical size %dx%d", w, h)
+	}
+	if w == 0 || h == 0 {
+		r.logicalW, r.logicalH = 0, 0
+		r.scale = FPoint{X: 1, Y: 1}
+		r.rescaleClipRect()
+		return r.SetViewport(nil)
+	}
+	r.logicalW, r.logicalH = w, h
+	return r.updateLogicalSize()
+}
+
+// GetLogicalSize returns the logical size, or zeros if it isn't set.
+func (r *Renderer) GetLogicalSize() (w, h int) {
+	return r.logicalW, r.logicalH
+}
+
+// updateLogicalSize fits the viewport and scale of the logical size to the
+// output.
+func (r *Renderer) updateLogicalSize() error {
+	if r.logicalW == 0 || r.logicalH == 0 {
+		return nil
+	}
+	w, h, err := r.targetSize()
+	if err != nil {
+		return err
+	}
+	if w == 0 || h == 0 {
+		return nil
+	}
+	mode := hint.GetHint(hint.RenderLogicalSizeMode)
+	overscan := mode == "overscan" || mode == "1"
+	lw, lh := float64(r.logicalW), float64(r.logicalH)
+	wantAspect, realAspect := lw/lh, float64(w)/float64(h)
+
+	var scale float64
+	var v Rect
+	switch {
+	case r.integerScale:
+		if wantAspect > realAspect {
+			scale = float64(w / r.logicalW)
+		} else {
+			scale = float64(h / r.logicalH)
+		}
+		scale = math.Max(scale, 1)
+		v.W, v.H = int(lw*scale), int(lh*scale)
+		v.X, v.Y = (w-v.W)/2, (h-v.H)/2
+	case math.Abs(wantAspect-realAspect) < 0.0001:
+		scale = float64(w) / lw
+		v = Rect{W: w, H: h}
+	case (wantAspect > realAspect) != overscan:
+		// fit the width
+		scale = float64(w) / lw
+		v = Rect{W: w, H: int(math.Floor(lh * scale))}
+		v.Y = (h - v.H) / 2
+	default:
+		// fit the height
+		scale = float64(h) / lh
+		v = Rect{W: int(math.Floor(lw * scale)), H: h}
+		v.X = (w - v.W) / 2
+	}
+	r.scale = FPoint{X: float32(scale), Y: float32(scale)}
+	r.viewport = v
+	r.logicalViewport = r.unscaleRect(v)
+	r.rescaleClipRect()
+	return r.driverData.updateViewport(r)
+}
+
+// SetIntegerScale restricts the logical size scaling to whole numbers.
+func (r *Renderer) SetIntegerScale(enable bool) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	r.integerScale = enable
+	return r.updateLogicalSize()
+}
+
+func (r *Renderer) GetIntegerScale() bool {
+	return r.integerScale
+}
+
+// SetViewport sets the drawing area of the target in logical coordinates, a
+// nil rect is the whole target. Drawing is relative to the viewport.
+func (r *Renderer) SetViewport(rect *Rect) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if rect == nil {
+		w, h, err := r.targetSize()
+		if err != nil {
+			return err
+		}
+		r.viewport = Rect{W: w, H: h}
+		r.logicalViewport = r.unscaleRect(r.viewport)
+	} else {
+		r.viewport = r.scaleArea(*rect)
+		r.logicalViewport = *rect
+	}
+	return r.driverData.updateViewport(r)
+}
+
+// GetViewport returns the drawing area in logical coordinates.
+func (r *Renderer) GetViewport() Rect {
+	return r.logicalViewport
+}
+
+// SetClipRect restricts drawing to a rectangle relative to the viewport, a
+// nil rect turns clipping off.
+func (r *Renderer) SetClipRect(rect *Rect) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	r.clippingEnabled = rect != nil
+	r.logicalClipRect = Rect{}
+	if rect != nil {
+		r.logicalClipRect = *rect
+	}
+	r.rescaleClipRect()
+	return r.driverData.updateClipRect(r)
+}
+
+// GetClipRect returns the clip rect, which is empty when clipping is off.
+func (r *Renderer) GetClipRect() Rect {
+	return r.logicalClipRect
+}
+
+// rescaleClipRect converts the clip rect to output pixels with the current
+// scale.
+func (r *Renderer) rescaleClipRect() {
+	r.clipRect = Rect{}
+	if r.clippingEnabled {
+		r.clipRect = r.scaleArea(r.logicalClipRect)
+	}
+}
+
+func (r *Renderer) IsClipEnabled() bool {
+	return r.clippingEnabled
+}
+
+// SetScale sets the scale from logical coordinates to output pixels, which
+// SetLogicalSize otherwise manages. The viewport and clip rect keep their
+// logical coordinates and are scaled again.
+func (r *Renderer) SetScale(scaleX, scaleY float32) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if scaleX <= 0 || scaleY <= 0 {
+		return errors.Errorf("invalid scale %vx%v", scaleX, scaleY)
+	}
+	r.scale = FPoint{X: scaleX, Y: scaleY}
+	r.viewport = r.scaleArea(r.logicalViewport)
+	r.rescaleClipRect()
+	return r.driverData.updateViewport(r)
+}
+
+func (r *Renderer) GetScale() (scaleX, scaleY float32) {
+	return r.scale.X, r.scale.Y
+}
+
+func (r *Renderer) unscaled() bool {
+	return r.scale == FPoint{X: 1, Y: 1}
+}
+
+// scaleRect converts a rectangle from logical coordinates to output pixels.
+// Neighbouring rectangles stay neighbours and no rectangle disappears.
+func (r *Renderer) scaleRect(rect Rect) Rect {
+	if r.unscaled() {
+		return rect
+	}
+	sx, sy := float64(r.scale.X), float64(r.scale.Y)
+	x0, y0 := int(math.Floor(float64(rect.X)*sx)), int(math.Floor(float64(rect.Y)*sy))
+	if rect.Empty() {
+		return Rect{X: x0, Y: y0}
+	}
+	x1, y1 := int(math.Floor(float64(rect.X+rect.W)*sx)), int(math.Floor(float64(rect.Y+rect.H)*sy))
+	return Rect{X: x0, Y: y0, W: max(x1-x0, 1), H: max(y1-y0, 1)}
+}
+
+// scaleArea converts a rectangle from logical coordinates to the output
+// pixels it touches.
+func (r *Renderer) scaleArea(rect Rect) Rect {
+	return Rect{
+		X: int(math.Floor(float64(float32(rect.X) * r.scale.X))),
+		Y: int(math.Floor(float64(float32(rect.Y) * r.scale.Y))),
+		W: int(math.Ceil(float64(float32(rect.W) * r.scale.X))),
+		H: int(math.Ceil(float64(float32(rect.H) * r.scale.Y))),
+	}
+}
+
+// unscaleRect converts a rectangle from output pixels to logical coordinates.
+func (r *Renderer) unscaleRect(rect Rect) Rect {
+	return Rect{
+		X: int(float32(rect.X) / r.scale.X),
+		Y: int(float32(rect.Y) / r.scale.Y),
+		W: int(float32(rect.W) / r.scale.X),
+		H: int(float32(rect.H) / r.scale.Y),
+	}
+}
+
+// rendererEventWatch follows the size and visibility of the renderer
+// window, and maps mouse and touch positions in the window to logical
+// coordinates.
+func rendererEventWatch(userdata interface{}, ev event.Event) bool {
+	r, _ := userdata.(*Renderer)
+	data, ok := ev.(*event.Data)
+	if !ok || r == nil || r.driverData == nil || r.window == nil {
+		return true
+	}
+	switch data.Type() {
+	case event.WindowStateChange:
+		we := event.Window(*data)
+		if we.WindowID() == r.window.id {
+			if err := r.windowEvent(we.Event()); err != nil {
+				log.Error(log.CategoryRender, "window %d: %v", r.window.id, err)
+			}
+		}
+	case event.MouseMotion:
+		mme := (*event.MouseMotionEvent)(data)
+		if mme.WindowID() != r.window.id {
+			break
+		}
+		mme.SetPosition(r.windowToLogical(mme.X(), mme.Y()))
+		_, scale := r.windowViewport()
+		xrel := r.relX + float32(mme.XRel())/scale.X
+		yrel := r.relY + float32(mme.YRel())/scale.Y
+		r.relX = xrel - float32(math.Trunc(float64(xrel)))
+		r.relY = yrel - float32(math.Trunc(float64(yrel)))
+		mme.SetRel(int32(xrel), int32(yrel))
+	case event.MouseButtonDown, event.MouseButtonUp:
+		mbe := (*event.MouseButton)(data)
+		if mbe.WindowID() == r.window.id {
+			mbe.SetPosition(r.windowToLogical(mbe.X(), mbe.Y()))
+		}
+	case event.FingerDown, event.FingerUp, event.FingerMotion:
+		tfe := (*event.TouchFinger)(data)
+		if tfe.WindowID() != r.window.id {
+			break
+		}
+		w, h, err := r.driverData.outputSize()
+		if err != nil || w == 0 || h == 0 {
+			break
+		}
+		viewport, _ := r.windowViewport()
+		tfe.SetPosition(
+			viewportFraction(tfe.X(), viewport.X, viewport.W, w),
+			viewportFraction(tfe.Y(), viewport.Y, viewport.H, h),
+		)
+	}
+	return true
+}
+
+// windowEvent updates the renderer for a change of its window.
+func (r *Renderer) windowEvent(windowEvent uint8) error {
+	switch windowEvent {
+	case event.WindowResized, event.WindowSizeChanged:
+		return r.windowResized()
+	case event.WindowHidden, event.WindowMinimized:
+		r.hidden = true
+	case event.WindowShown:
+		r.hidden = r.window.flags&WindowMinimized > 0
+	case event.WindowRestored, event.WindowMaximized:
+		r.hidden = r.window.flags&WindowHidden > 0
+	}
+	return nil
+}
+
+// windowResized fits the window viewport to the new size, the render target
+// is switched back to the window while doing so.
+func (r *Renderer) windowResized() error {
+	target := r.target
+	if target != nil {
+		if err := r.SetRenderTarget(nil); err != nil {
+			return err
+		}
+	}
+	var err error
+	if r.logicalW > 0 {
+		err = r.updateLogicalSize()
+	} else {
+		err = r.SetViewport(nil)
+	}
+	if err != nil {
+		return err
+	}
+	if target != nil {
+		return r.SetRenderTarget(target)
+	}
+	return nil
+}
+
+// windowViewport returns the viewport and scale of the window, which are
+// put aside while drawing to a texture.
+func (r *Renderer) windowViewport() (Rect, FPoint) {
+	if r.target != nil {
+		return r.viewportBackup, r.scaleBackup
+	}
+	return r.viewport, r.scale
+}
+
+// windowToLogical maps a position in window pixels to logical coordinates.
+func (r *Renderer) windowToLogical(x, y int32) (int32, int32) {
+	viewport, scale := r.windowViewport()
+	lx := math.Floor(float64(float32(int(x)-viewport.X) / scale.X))
+	ly := math.Floor(float64(float32(int(y)-viewport.Y) / scale.Y))
+	return int32(lx), int32(ly)
+}
+
+// viewportFraction maps a normalized touch position in the output to the
+// viewport, positions outside it are clamped to its edge.
+func viewportFraction(v float32, offset, size, output int) float32 {
+	start := float32(offset) / float32(output)
+	extent := float32(size) / float32(output)
+	switch {
+	case v <= start:
+		return 0
+	case v >= start+extent:
+		return 1
+	}
+	return (v - start) / extent
+}
+
+// SetDrawColor sets the color used by Clear and the drawing functions.
+func (r *Renderer) SetDrawColor(red, green, blue, alpha uint8) {
+	r.r, r.g, r.b, r.a = red, green, blue, alpha
+}
+
+func (r *Renderer) GetDrawColor() (red, green, blue, alpha uint8) {
+	return r.r, r.g, r.b, r.a
+}
+
+// SetDrawBlendMode sets how the drawing functions combine the draw color
+// with the target, Clear always replaces it.
+func (r *Renderer) SetDrawBlendMode(blendMode uint32) error {
+	if _, ok := blendModeFlags[blendMode]; !ok {
+		return errors.Errorf("unsupported blend mode 0x%x", blendMode)
+	}
+	r.blendMode = blendMode
+	return nil
+}
+
+func (r *Renderer) GetDrawBlendMode() uint32 {
+	return r.blendMode
+}
+
+// Clear fills the whole target with the draw color, ignoring the viewport
+// and the blend mode.
+func (r *Renderer) Clear() error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if !r.renderingAllowed() {
+		return nil
+	}
+	return r.driverData.clear(r)
+}
+
+// DrawPoint draws a point with the draw color.
+func (r *Renderer) DrawPoint(x, y int) error {
+	return r.DrawPoints([]Point{{X: x, Y: y}})
+}
+
+// DrawPoints draws each point with the draw color.
+func (r *Renderer) DrawPoints(points []Point) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if len(points) == 0 || !r.renderingAllowed() {
+		return nil
+	}
+	if r.unscaled() {
+		return r.driverData.drawPoints(r, points)
+	}
+	rects := make([]Rect, len(points))
+	for i, p := range points {
+		rects[i] = r.scaleRect(Rect{X: p.X, Y: p.Y, W: 1, H: 1})
+	}
+	return r.driverData.fillRects(r, rects)
+}
+
+// DrawLine draws a line including both end points.
+func (r *Renderer) DrawLine(x1, y1, x2, y2 int) error {
+	return r.DrawLines([]Point{{X: x1, Y: y1}, {X: x2, Y: y2}})
+}
+
+// DrawLines draws a line through each of the points, every pixel is drawn
+// once so blending a closed path doesn't darken its corners.
+func (r *Renderer) DrawLines(points []Point) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if len(points) == 0 || !r.renderingAllowed() {
+		return nil
+	}
+	return r.drawLines(points)
+}
+
+// drawLines draws a path, when scaled each logical pixel of the path
+// becomes a rectangle.
+func (r *Renderer) drawLines(points []Point) error {
+	if r.unscaled() {
+		return r.driverData.drawLines(r, points)
+	}
+	var rects []Rect
+	bounds := Rect{
+		W: int(math.Ceil(float64(float32(r.viewport.W) / r.scale.X))),
+		H: int(math.Ceil(float64(float32(r.viewport.H) / r.scale.Y))),
+	}
+	plotLines(bounds, points, func(x, y int) {
+		rects = append(rects, r.scaleRect(Rect{X: x, Y: y, W: 1, H: 1}))
+	})
+	if len(rects) == 0 {
+		return nil
+	}
+	return r.driverData.fillRects(r, rects)
+}
+
+// DrawRect draws the outline of a rectangle, a nil rect outlines the whole
+// viewport.
+func (r *Renderer) DrawRect(rect *Rect) error {
+	if rect == nil {
+		v := r.GetViewport()
+		return r.DrawRects([]Rect{{W: v.W, H: v.H}})
+	}
+	return r.DrawRects([]Rect{*rect})
+}
+
+// DrawRects draws the outline of each rectangle.
+func (r *Renderer) DrawRects(rects []Rect) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if !r.renderingAllowed() {
+		return nil
+	}
+	for _, rect := range rects {
+		if rect.Empty() {
+			continue
+		}
+		x0, y0 := rect.X, rect.Y
+		x1, y1 := rect.X+rect.W-1, rect.Y+rect.H-1
+		points := []Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}, {X: x0, Y: y0}}
+		if rect.W == 1 || rect.H == 1 {
+			points = []Point{{X: x0, Y: y0}, {X: x1, Y: y1}}
+		}
+		if err := r.drawLines(points); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FillRect fills a rectangle with the draw color, a nil rect fills the whole
+// viewport.
+func (r *Renderer) FillRect(rect *Rect) error {
+	if rect == nil {
+		v := r.GetViewport()
+		return r.FillRects([]Rect{{W: v.W, H: v.H}})
+	}
+	return r.FillRects([]Rect{*rect})
+}
+
+// FillRects fills each rectangle with the draw color.
+func (r *Renderer) FillRects(rects []Rect) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if len(rects) == 0 || !r.renderingAllowed() {
+		return nil
+	}
+	if !r.unscaled() {
+		scaled := make([]Rect, len(rects))
+		for i, rect := range rects {
+			scaled[i] = r.scaleRect(rect)
+		}
+		rects = scaled
+	}
+	return r.driverData.fillRects(r, rects)
+}
+
+// copyRects returns the texture area and the viewport area in output pixels
+// of a copy, and false if nothing would be drawn.
+func (r *Renderer) copyRects(texture *Texture, srcRect, dstRect *Rect) (Rect, Rect, bool) {
+	src := Rect{W: texture.w, H: texture.h}
+	if srcRect != nil {
+		var ok bool
+		if src, ok = IntersectRect(*srcRect, src); !ok {
+			return src, Rect{}, false
+		}
+	}
+	v := r.GetViewport()
+	dst := Rect{W: v.W, H: v.H}
+	if dstRect != nil {
+		dst = *dstRect
+	}
+	if dst.Empty() {
+		return src, dst, false
+	}
+	return src, r.scaleRect(dst), true
+}
+
+func (r *Renderer) checkTexture(texture *Texture) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if texture == nil || texture.renderer == nil {
+		return errors.New("invalid texture")
+	}
+	if texture.renderer != r {
+		return errors.New("texture was not created with this renderer")
+	}
+	return nil
+}
+
+// renderTexture returns the texture the driver works with, which is the
+// native texture when the format isn't supported directly.
+func renderTexture(t *Texture) *Texture {
+	if t != nil && t.native != nil {
+		return t.native
+	}
+	return t
+}
+
+// Copy draws part of a texture to the target, scaling it to fit dstRect. A
+// nil srcRect is the whole texture and a nil dstRect the whole viewport.
+func (r *Renderer) Copy(texture *Texture, srcRect, dstRect *Rect) error {
+	if err := r.checkTexture(texture); err != nil {
+		return err
+	}
+	src, dst, ok := r.copyRects(texture, srcRect, dstRect)
+	if !ok || !r.renderingAllowed() {
+		return nil
+	}
+	return r.driverData.copy(r, renderTexture(texture), src, dst)
+}
+
+// CopyEx is Copy with a rotation of angle degrees clockwise around center,
+// which is relative to dstRect and defaults to its middle, and optional
+// flipping of the texture.
+func (r *Renderer) CopyEx(texture *Texture, srcRect, dstRect *Rect, angle float64, center *Point, flip int) error {
+	if err := r.checkTexture(texture); err != nil {
+		return err
+	}
+	src, dst, ok := r.copyRects(texture, srcRect, dstRect)
+	if !ok || !r.renderingAllowed() {
+		return nil
+	}
+	c := Point{X: dst.W / 2, Y: dst.H / 2}
+	if center != nil {
+		c.X = int(float32(center.X) * r.scale.X)
+		c.Y = int(float32(center.Y) * r.scale.Y)
+	}
+	return r.driverData.copyEx(r, renderTexture(texture), src, dst, angle, c, flip)
+}
+
+// RenderGeometry draws triangles with colors interpolated between their
+// vertices, and the texture, if not nil, mapped onto them and modulated by
+// the colors. The indices pick the vertices of each triangle, or with nil
+// indices every three vertices are a triangle. The texture blend mode is
+// used, or the renderer blend mode without a texture.
+func (r *Renderer) RenderGeometry(texture *Texture, vertices []Vertex, indices []int) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if texture != nil {
+		if err := r.checkTexture(texture); err != nil {
+			return err
+		}
+	}
+	n := len(vertices)
+	if indices != nil {
+		n = len(indices)
+		for _, i := range indices {
+			if i < 0 || i >= len(vertices) {
+				return errors.Errorf("vertex index %d out of range", i)
+			}
+		}
+	}
+	if n%3 != 0 {
+		return errors.Errorf("%d vertices don't make whole triangles", n)
+	}
+	scaled := make([]Vertex, len(vertices))
+	for i, v := range vertices {
+		x, y := float64(v.Position.X), float64(v.Position.Y)
+		if math.IsNaN(x) || math.IsNaN(y) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+			return errors.Errorf("invalid position of vertex %d", i)
+		}
+		v.Position.X *= r.scale.X
+		v.Position.Y *= r.scale.Y
+		scaled[i] = v
+	}
+	if n == 0 || !r.renderingAllowed() {
+		return nil
+	}
+	return r.driverData.geometry(r, renderTexture(texture), scaled, indices)
+}
+
+// RenderReadPixels reads a rectangle of the viewport from the current target
+// as pixels of the format, in rows aligned to 4 bytes. The rect is in
+// logical coordinates, or the whole viewport if nil, and is read in output
+// pixels. The parts of it outside the viewport are left zero, and an empty
+// rect reads nothing.
+func (r *Renderer) RenderReadPixels(rect *Rect, format uint32) (pixels []byte, pitch int, err error) {
+	if err := r.check(); err != nil {
+		return nil, 0, err
+	}
+	if format == PixelFormatUnknown || IsPixelFormatIndexed(format) || IsPixelFormatFourCC(format) {
+		return nil, 0, errors.Errorf("unsupported pixel format %s", GetPixelFormatName(format))
+	}
+	area := r.viewport
+	if rect != nil {
+		area = r.scaleArea(*rect)
+		area.X += r.viewport.X
+		area.Y += r.viewport.Y
+	}
+	if area.Empty() {
+		return nil, 0, nil
+	}
+	w, h, err := r.targetSize()
+	if err != nil {
+		return nil, 0, err
+	}
+	bounds, _ := IntersectRect(r.viewport, Rect{W: w, H: h})
+
+	pitch = calculatePitch(format, area.W)
+	pixels = make([]byte, pitch*area.H)
+	if err := r.readPixels(area, bounds, format, pixels, pitch); err != nil {
+		return nil, 0, err
+	}
+	return pixels, pitch, nil
+}
+
+// Screenshot reads the whole current target into a new ARGB8888 surface,
+// which is also an image.Image.
+func (r *Renderer) Screenshot() (*Surface, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	w, h, err := r.targetSize()
+	if err != nil {
+		return nil, err
+	}
+	s, err := CreateRGBSurfaceWithFormat(0, w, h, 0, PixelFormatARGB8888)
+	if err != nil {
+		return nil, err
+	}
+	target := Rect{W: w, H: h}
+	if err := r.readPixels(target, target, PixelFormatARGB8888, s.pixels, s.pitch); err != nil {
+		FreeSurface(s)
+		return nil, err
+	}
+	return s, nil
+}
+
+// readPixels reads the part of area within bounds, both in target pixels,
+// into pixels which start at the top left of area.
+func (r *Renderer) readPixels(area, bounds Rect, format uint32, pixels []byte, pitch int) error {
+	visible, ok := IntersectRect(area, bounds)
+	if !ok {
+		return nil
+	}
+	offset := (visible.Y-area.Y)*pitch + (visible.X-area.X)*int(BytesPerPixel(format))
+	return r.driverData.readPixels(r, visible, format, pixels[offset:], pitch)
+}
+
+// Present shows everything drawn since the last call.
+func (r *Renderer) Present() error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if r.hidden {
+		return nil
+	}
+	return r.driverData.present(r)
 }
@@ -0,0 +1,379 @@
+package video
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Scale modes, used for scaled blits and textures.
+const (
+	ScaleModeNearest = iota // nearest pixel sampling
+	ScaleModeLinear         // linear filtering
+	ScaleModeBest           // anisotropic filtering, the same as linear in software
+)
+
+// SetScaleMode sets the filtering used when the surface is scaled by
+// BlitScaled.
+func (s *Surface) SetScaleMode(scaleMode int) error {
+	if scaleMode < ScaleModeNearest || scaleMode > ScaleModeBest {
+		return errors.Errorf("unsupported scale mode %d", scaleMode)
+	}
+	s.scaleMode = scaleMode
+	return nil
+}
+
+func (s *Surface) GetScaleMode() int {
+	return s.scaleMode
+}
+
+// BlitScaled copies srcRect from src to dstRect in dst, scaling it to fit
+// with the scale mode of src. Color key, modulation and blending are the
+// same as for BlitSurface. A nil srcRect is the whole source and a nil
+// dstRect the whole destination, dstRect is updated with the area that was
+// actually drawn after clipping.
+//
+// With linear filtering color keyed pixels become transparent, so their
+// edges are blended even when the blend mode is none.
+func BlitScaled(src *Surface, srcRect *Rect, dst *Surface, dstRect *Rect) error {
+	if err := checkBlit(src, dst); err != nil {
+		return err
+	}
+	sr := Rect{W: src.w, H: src.h}
+	if srcRect != nil {
+		sr = *srcRect
+	}
+	dr := Rect{W: dst.w, H: dst.h}
+	if dstRect != nil {
+		dr = *dstRect
+	}
+	if sr.W == dr.W && sr.H == dr.H {
+		err := BlitSurface(src, &sr, dst, &dr)
+		if dstRect != nil {
+			*dstRect = dr
+		}
+		return err
+	}
+
+	m := src.blitMap
+	if !m.valid(src, dst) {
+		if err := m.mapSurface(src, dst); err != nil {
+			return err
+		}
+	}
+	info := m.info
+	info.flags = m.flags
+	linear := src.scaleMode != ScaleModeNearest
+	if linear && info.flags&copyColorKey > 0 && info.flags&copyBlendMask == 0 {
+		info.flags |= copyBlend
+	}
+	r := stretchBlit(&info, scaledBlitFunc(src.format, dst.format, info.flags, linear), src, sr, dst, dr, dst.clipRect)
+	if dstRect != nil {
+		*dstRect = r
+	}
+	return nil
+}
+
+// scaledBlitFunc picks the scaled blit function between two formats.
+func scaledBlitFunc(sf, df *PixelFormat, flags uint32, linear bool) blitFunc {
+	switch {
+	case linear && is8888(sf) && is8888(df):
+		return blitLinear8888
+	case linear:
+		return blitLinearGeneric
+	case canCopy(sf, df, flags):
+		return blitScaledCopy
+	case is8888(sf) && is8888(df):
+		return blitScaled8888
+	}
+	return blitScaledGeneric
+}
+
+// SoftStretch copies srcRect from src to dstRect in dst with nearest pixel
+// sampling. Both surfaces must have the same format, no conversion,
+// blending or clipping to the clip rect is done.
+func SoftStretch(src *Surface, srcRect *Rect, dst *Surface, dstRect *Rect) error {
+	return softStretch(src, srcRect, dst, dstRect, false)
+}
+
+// SoftStretchLinear is SoftStretch with linear filtering, it doesn't work
+// with indexed formats.
+func SoftStretchLinear(src *Surface, srcRect *Rect, dst *Surface, dstRect *Rect) error {
+	return softStretch(src, srcRect, dst, dstRect, true)
+}
+
+func softStretch(src *Surface, srcRect *Rect, dst *Surface, dstRect *Rect, linear bool) error {
+	if err := checkBlit(src, dst); err != nil {
+		return err
+	}
+	if src.format.format != dst.format.format {
+		return errors.New("only works with same format surfaces")
+	}
+	if linear && src.format.palette != nil {
+		return errors.New("linear filtering doesn't work with indexed formats")
+	}
+	sr := Rect{W: src.w, H: src.h}
+	if srcRect != nil {
+		sr = *srcRect
+	}
+	dr := Rect{W: dst.w, H: dst.h}
+	if dstRect != nil {
+		dr = *dstRect
+	}
+	info := blitInfo{srcFormat: src.format, dstFormat: dst.format}
+	stretchBlit(&info, scaledBlitFunc(src.format, dst.format, 0, linear), src, sr, dst, dr, Rect{W: dst.w, H: dst.h})
+	return nil
+}
+
+// stretchBlit sets up the lookup tables for a scaled blit from sr to dr,
+// clipped to the source surface and to clip, and runs f. It returns the
+// destination area that was drawn.
+func stretchBlit(info *blitInfo, f blitFunc, src *Surface, sr Rect, dst *Surface, dr Rect, clip Rect) Rect {
+	srcClip, ok := IntersectRect(sr, Rect{W: src.w, H: src.h})
+	if !ok || dr.Empty() {
+		return Rect{X: dr.X, Y: dr.Y}
+	}
+	x0, xs := stretchAxis(sr.X, sr.W, dr.X, dr.W, srcClip.X, srcClip.X+srcClip.W, clip.X, clip.X+clip.W)
+	y0, ys := stretchAxis(sr.Y, sr.H, dr.Y, dr.H, srcClip.Y, srcClip.Y+srcClip.H, clip.Y, clip.Y+clip.H)
+	if len(xs) == 0 || len(ys) == 0 {
+		return Rect{X: dr.X, Y: dr.Y}
+	}
+
+	info.src, info.srcPitch = src.pixels, src.pitch
+	info.srcClip = srcClip
+	info.dst, info.dstPitch = dst.pixels[y0*dst.pitch:], dst.pitch
+	info.dstX, info.dstW, info.dstH = x0, len(xs), len(ys)
+	info.xs, info.ys = xs, ys
+	f(info)
+	return Rect{X: x0, Y: y0, W: len(xs), H: len(ys)}
+}
+
+// stretchAxis maps the destination pixels on one axis to the centre of
+// their footprint in the source, in 16.16 fixed point. Only pixels inside
+// [clipMin, clipMax) whose nearest source pixel is inside [srcMin, srcMax)
+// are returned, along with the position of the first one. The mapping
+// doesn't depend on the clipping, so a clipped blit draws exactly the same
+// pixels as the matching part of an unclipped one.
+func stretchAxis(srcPos, srcLen, dstPos, dstLen, srcMin, srcMax, clipMin, clipMax int) (int, []int) {
+	first := 0
+	var pos []int
+	for i := max(0, clipMin-dstPos); i < dstLen && dstPos+i < clipMax; i++ {
+		centre := (int64(2*i+1) * int64(srcLen) << 16) / int64(2*dstLen)
+		p := int(int64(srcPos)<<16 + centre)
+		if p>>16 < srcMin {
+			continue
+		}
+		if p>>16 >= srcMax {
+			break
+		}
+		if pos == nil {
+			first = dstPos + i
+		}
+		pos = append(pos, p)
+	}
+	return first, pos
+}
+
+// blitScaledCopy copies the nearest source pixel between surfaces of the
+// same format.
+func blitScaledCopy(info *blitInfo) {
+	f := info.dstFormat
+	if f.bitsPerPixel < 8 {
+		for y, sy := range info.ys {
+			src := info.src[(sy>>16)*info.srcPitch:]
+			dst := info.dst[y*info.dstPitch:]
+			for x, sx := range info.xs {
+				setIndex(dst, info.dstX+x, f.format, getIndex(src, sx>>16, f.format))
+			}
+		}
+		return
+	}
+	bpp := int(f.bytesPerPixel)
+	for y, sy := range info.ys {
+		src := info.src[(sy>>16)*info.srcPitch:]
+		dst := info.dst[y*info.dstPitch+info.dstX*bpp:]
+		if bpp == 4 {
+			for x, sx := range info.xs {
+				store32(dst[x*4:], load32(src[(sx>>16)*4:]))
+			}
+			continue
+		}
+		for x, sx := range info.xs {
+			copy(dst[x*bpp:x*bpp+bpp], src[(sx>>16)*bpp:])
+		}
+	}
+}
+
+// blitScaledGeneric is blitGeneric with nearest pixel sampling.
+func blitScaledGeneric(info *blitInfo) {
+	sf := info.srcFormat
+	rgbMask, key := info.keyMask()
+	colorKey := info.flags&copyColorKey > 0
+	for y, sy := range info.ys {
+		src := info.src[(sy>>16)*info.srcPitch:]
+		dst := info.dst[y*info.dstPitch:]
+		for x, sx := range info.xs {
+			sp := readPixel(src, sx>>16, sf)
+			if colorKey && sp&rgbMask == key {
+				continue
+			}
+			r, g, b, a := GetRGBA(sp, sf)
+			info.put(dst, info.dstX+x, r, g, b, a)
+		}
+	}
+}
+
+// blitScaled8888 is blit8888 with nearest pixel sampling.
+func blitScaled8888(info *blitInfo) {
+	sc, dc := newChannels8888(info.srcFormat), newChannels8888(info.dstFormat)
+	if info.flags == copyBlend {
+		for y, sy := range info.ys {
+			src := info.src[(sy>>16)*info.srcPitch:]
+			dst := info.dst[y*info.dstPitch+info.dstX*4:]
+			for x, sx := range info.xs {
+				blendOver8888(dst[x*4:], load32(src[(sx>>16)*4:]), sc, dc)
+			}
+		}
+		return
+	}
+	rgbMask, key := info.keyMask()
+	colorKey := info.flags&copyColorKey > 0
+	for y, sy := range info.ys {
+		src := info.src[(sy>>16)*info.srcPitch:]
+		dst := info.dst[y*info.dstPitch+info.dstX*4:]
+		for x, sx := range info.xs {
+			sp := load32(src[(sx>>16)*4:])
+			if colorKey && sp&rgbMask == key {
+				continue
+			}
+			r, g, b, a := sc.unpack(sp)
+			info.put8888(dst[x*4:], dc, r, g, b, a)
+		}
+	}
+}
+
+// linearTap holds the two source pixels either side of a sample and the
+// weight of the second one, out of 256.
+type linearTap struct {
+	p0, p1 int
+	w      uint32
+}
+
+// linearTaps converts sample positions to taps, clamped to [lo, hi).
+func linearTaps(pos []int, lo, hi int) []linearTap {
+	taps := make([]linearTap, len(pos))
+	for i, p := range pos {
+		p -= 0x8000 // sample between pixel centres
+		p0 := p >> 16
+		taps[i] = linearTap{
+			p0: min(max(p0, lo), hi-1),
+			p1: min(max(p0+1, lo), hi-1),
+			w:  uint32(p&0xFFFF) >> 8,
+		}
+	}
+	return taps
+}
+
+// bilinear interpolates each channel of four samples.
+func bilinear(c00, c10, c01, c11 [4]uint32, wx, wy uint32) (c [4]uint32) {
+	for i := range c {
+		top := c00[i]*(256-wx) + c10[i]*wx
+		bottom := c01[i]*(256-wx) + c11[i]*wx
+		c[i] = (top*(256-wy) + bottom*wy) >> 16
+	}
+	return c
+}
+
+// bilinearKeyed is bilinear with the colors weighted by their alpha, so
+// transparent samples don't pull the color of their neighbours towards
+// black.
+func bilinearKeyed(c00, c10, c01, c11 [4]uint32, wx, wy uint32) (c [4]uint32) {
+	samples := [4][4]uint32{c00, c10, c01, c11}
+	weights := [4]uint32{(256 - wx) * (256 - wy), wx * (256 - wy), (256 - wx) * wy, wx * wy}
+	var alpha uint32
+	for i, s := range samples {
+		weights[i] *= s[3]
+		alpha += weights[i]
+	}
+	if alpha == 0 {
+		return c
+	}
+	for i := 0; i < 3; i++ {
+		var sum uint64
+		for j, s := range samples {
+			sum += uint64(weights[j]) * uint64(s[i])
+		}
+		c[i] = uint32(sum / uint64(alpha))
+	}
+	c[3] = alpha >> 16
+	return c
+}
+
+// blitLinearGeneric handles every combination of formats and flags with
+// linear filtering. Color keyed samples are transparent black.
+func blitLinearGeneric(info *blitInfo) {
+	sf := info.srcFormat
+	rgbMask, key := info.keyMask()
+	colorKey := info.flags&copyColorKey > 0
+	sample := func(row []byte, x int) [4]uint32 {
+		sp := readPixel(row, x, sf)
+		if colorKey && sp&rgbMask == key {
+			return [4]uint32{}
+		}
+		r, g, b, a := GetRGBA(sp, sf)
+		return [4]uint32{uint32(r), uint32(g), uint32(b), uint32(a)}
+	}
+
+	filter := bilinear
+	if colorKey {
+		filter = bilinearKeyed
+	}
+
+	cols := linearTaps(info.xs, info.srcClip.X, info.srcClip.X+info.srcClip.W)
+	rows := linearTaps(info.ys, info.srcClip.Y, info.srcClip.Y+info.srcClip.H)
+	for y, ty := range rows {
+		src0 := info.src[ty.p0*info.srcPitch:]
+		src1 := info.src[ty.p1*info.srcPitch:]
+		dst := info.dst[y*info.dstPitch:]
+		for x, tx := range cols {
+			c := filter(sample(src0, tx.p0), sample(src0, tx.p1), sample(src1, tx.p0), sample(src1, tx.p1), tx.w, ty.w)
+			if colorKey && c[3] == 0 {
+				continue
+			}
+			info.put(dst, info.dstX+x, uint8(c[0]), uint8(c[1]), uint8(c[2]), uint8(c[3]))
+		}
+	}
+}
+
+// blitLinear8888 is blitLinearGeneric between two 8888 formats.
+func blitLinear8888(info *blitInfo) {
+	sc, dc := newChannels8888(info.srcFormat), newChannels8888(info.dstFormat)
+	rgbMask, key := info.keyMask()
+	colorKey := info.flags&copyColorKey > 0
+	sample := func(row []byte, x int) [4]uint32 {
+		sp := load32(row[x*4:])
+		if colorKey && sp&rgbMask == key {
+			return [4]uint32{}
+		}
+		r, g, b, a := sc.unpack(sp)
+		return [4]uint32{r, g, b, a}
+	}
+
+	filter := bilinear
+	if colorKey {
+		filter = bilinearKeyed
+	}
+
+	cols := linearTaps(info.xs, info.srcClip.X, info.srcClip.X+info.srcClip.W)
+	rows := linearTaps(info.ys, info.srcClip.Y, info.srcClip.Y+info.srcClip.H)
+	for y, ty := range rows {
+		src0 := info.src[ty.p0*info.srcPitch:]
+		src1 := info.src[ty.p1*info.srcPitch:]
+		dst := info.dst[y*info.dstPitch+info.dstX*4:]
+		for x, tx := range cols {
+			c := filter(sample(src0, tx.p0), sample(src0, tx.p1), sample(src1, tx.p0), sample(src1, tx.p1), tx.w, ty.w)
+			if colorKey && c[3] == 0 {
+				continue
+			}
+			info.put8888(dst[x*4:], dc, c[0], c[1], c[2], c[3])
+		}
+	}
+}
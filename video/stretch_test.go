@@ -0,0 +1,237 @@
+package video
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlitScaledNearest(t *testing.T) {
+	src, err := CreateRGBSurfaceWithFormat(0, 2, 2, 0, PixelFormatIndex8)
+	require.NoError(t, err)
+	copy(src.pixels, []byte{1, 2, 0, 0, 3, 4})
+	dst, err := CreateRGBSurfaceWithFormat(0, 4, 4, 0, PixelFormatIndex8)
+	require.NoError(t, err)
+
+	require.NoError(t, BlitScaled(src, nil, dst, nil))
+	assert.Equal(t, []byte{
+		1, 1, 2, 2,
+		1, 1, 2, 2,
+		3, 3, 4, 4,
+		3, 3, 4, 4,
+	}, dst.pixels)
+
+	// the same size is a normal blit
+	dr := Rect{X: 3, Y: 3, W: 2, H: 2}
+	require.NoError(t, BlitScaled(src, nil, dst, &dr))
+	assert.Equal(t, Rect{X: 3, Y: 3, W: 1, H: 1}, dr)
+	assert.Equal(t, byte(1), dst.pixels[15])
+}
+
+func TestBlitScaledLinear(t *testing.T) {
+	src, err := CreateRGBSurfaceWithFormat(0, 2, 1, 0, PixelFormatXRGB8888)
+	require.NoError(t, err)
+	src.setPixel(1, 0, 0xFFFFFF)
+	require.NoError(t, src.SetScaleMode(ScaleModeLinear))
+	assert.Equal(t, ScaleModeLinear, src.GetScaleMode())
+	assert.Error(t, src.SetScaleMode(3))
+
+	for _, format := range []uint32{PixelFormatXRGB8888, PixelFormatRGB24} {
+		dst, err := CreateRGBSurfaceWithFormat(0, 4, 1, 0, format)
+		require.NoError(t, err)
+		require.NoError(t, BlitScaled(src, nil, dst, nil))
+		var got []uint8
+		for x := 0; x < 4; x++ {
+			r, _, _ := GetRGB(dst.getPixel(x, 0), dst.format)
+			got = append(got, r)
+		}
+		assert.Equal(t, []uint8{0, 63, 191, 255}, got, GetPixelFormatName(format))
+	}
+}
+
+func TestBlitScaledClipping(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	src := newRandomSurface(t, rng, 7, 5, PixelFormatARGB8888)
+	for _, mode := range []int{ScaleModeNearest, ScaleModeLinear} {
+		require.NoError(t, src.SetScaleMode(mode))
+		full, err := CreateRGBSurfaceWithFormat(0, 32, 32, 0, PixelFormatXRGB8888)
+		require.NoError(t, err)
+		dr := Rect{X: 3, Y: 2, W: 23, H: 17}
+		require.NoError(t, BlitScaled(src, nil, full, &dr))
+		assert.Equal(t, Rect{X: 3, Y: 2, W: 23, H: 17}, dr)
+
+		clipped, err := CreateRGBSurfaceWithFormat(0, 32, 32, 0, PixelFormatXRGB8888)
+		require.NoError(t, err)
+		clip := Rect{X: 8, Y: 7, W: 11, H: 5}
+		clipped.SetClipRect(&clip)
+		dr = Rect{X: 3, Y: 2, W: 23, H: 17}
+		require.NoError(t, BlitScaled(src, nil, clipped, &dr))
+		assert.Equal(t, clip, dr)
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				want := uint32(0)
+				if clip.Contains(Point{X: x, Y: y}) {
+					want = full.getPixel(x, y)
+				}
+				require.Equal(t, want, clipped.getPixel(x, y), "mode %d pixel (%d, %d)", mode, x, y)
+			}
+		}
+	}
+
+	// a source rect hanging off the surface scales the same way, the
+	// destination only covers the part that exists
+	full, err := CreateRGBSurfaceWithFormat(0, 40, 40, 0, PixelFormatXRGB8888)
+	require.NoError(t, err)
+	src.SetScaleMode(ScaleModeNearest)
+	dr := Rect{X: 0, Y: 0, W: 27, H: 18}
+	require.NoError(t, BlitScaled(src, &Rect{X: -2, Y: 0, W: 9, H: 6}, full, &dr))
+	assert.Equal(t, Rect{X: 6, Y: 0, W: 21, H: 15}, dr)
+	assert.Equal(t, full.getPixel(6, 0), full.getPixel(8, 2))
+	assert.NotEqual(t, uint32(0), full.getPixel(6, 0))
+
+	dr = Rect{X: 40, Y: 0, W: 10, H: 10}
+	require.NoError(t, BlitScaled(src, nil, full, &dr))
+	assert.Equal(t, 0, dr.W)
+}
+
+func TestBlitScaledFlags(t *testing.T) {
+	src, err := CreateRGBSurfaceWithFormat(0, 2, 1, 0, PixelFormatARGB8888)
+	require.NoError(t, err)
+	key := MapRGB(src.format, 0xFF, 0, 0xFF)
+	src.setPixel(0, 0, key)
+	src.setPixel(1, 0, MapRGBA(src.format, 0xFF, 0xFF, 0xFF, 0xFF))
+	require.NoError(t, src.SetColorKey(true, key))
+	require.NoError(t, src.SetBlendMode(BlendModeNone))
+	src.SetAlphaMod(0x80)
+
+	dst, err := CreateRGBSurfaceWithFormat(0, 4, 1, 0, PixelFormatARGB8888)
+	require.NoError(t, err)
+	require.NoError(t, BlitScaled(src, nil, dst, nil))
+	assert.Equal(t, uint32(0), dst.getPixel(0, 0))
+	assert.Equal(t, uint32(0), dst.getPixel(1, 0))
+	assert.Equal(t, MapRGBA(dst.format, 0xFF, 0xFF, 0xFF, 0x80), dst.getPixel(2, 0))
+
+	require.NoError(t, src.SetScaleMode(ScaleModeLinear))
+	require.NoError(t, dst.FillRect(nil, 0))
+	require.NoError(t, BlitScaled(src, nil, dst, nil))
+	assert.Equal(t, uint32(0), dst.getPixel(0, 0))
+	_, _, _, a := GetRGBA(dst.getPixel(1, 0), dst.format)
+	assert.Equal(t, uint8(0x80*0x3F/0xFF), a)
+}
+
+func TestBlitScaledLinearKeyedEdge(t *testing.T) {
+	for _, format := range []uint32{PixelFormatARGB8888, PixelFormatRGB565} {
+		src, err := CreateRGBSurfaceWithFormat(0, 4, 1, 0, format)
+		require.NoError(t, err)
+		key := MapRGB(src.format, 0xFF, 0, 0xFF)
+		white := MapRGB(src.format, 0xFF, 0xFF, 0xFF)
+		for x, p := range []uint32{key, white, white, key} {
+			src.setPixel(x, 0, p)
+		}
+		require.NoError(t, src.SetColorKey(true, key))
+		require.NoError(t, src.SetScaleMode(ScaleModeLinear))
+
+		// the edges of the white pixels stay white over white, the key
+		// color doesn't bleed into them
+		dst, err := CreateRGBSurfaceWithFormat(0, 8, 1, 0, PixelFormatARGB8888)
+		require.NoError(t, err)
+		require.NoError(t, dst.FillRect(nil, 0xFFFFFFFF))
+		require.NoError(t, BlitScaled(src, nil, dst, nil))
+		assert.Equal(t, [][]uint32{{
+			0xFFFFFFFF, 0xFFFFFFFF, 0xFFFFFFFF, 0xFFFFFFFF, 0xFFFFFFFF, 0xFFFFFFFF, 0xFFFFFFFF, 0xFFFFFFFF,
+		}}, surfacePixels(dst, Rect{W: 8, H: 1}), GetPixelFormatName(format))
+	}
+}
+
+func TestSoftStretch(t *testing.T) {
+	src, err := CreateRGBSurfaceWithFormat(0, 2, 1, 0, PixelFormatIndex4MSB)
+	require.NoError(t, err)
+	src.setPixel(0, 0, 3)
+	src.setPixel(1, 0, 9)
+	dst, err := CreateRGBSurfaceWithFormat(0, 5, 1, 0, PixelFormatIndex4MSB)
+	require.NoError(t, err)
+	dst.SetClipRect(&Rect{W: 1, H: 1})
+
+	require.NoError(t, SoftStretch(src, nil, dst, &Rect{X: 1, W: 4, H: 1}))
+	assert.Equal(t, []uint32{0, 3, 3, 9, 9}, []uint32{dst.getPixel(0, 0), dst.getPixel(1, 0), dst.getPixel(2, 0), dst.getPixel(3, 0), dst.getPixel(4, 0)})
+	assert.Error(t, SoftStretchLinear(src, nil, dst, nil))
+
+	other, err := CreateRGBSurfaceWithFormat(0, 5, 1, 0, PixelFormatIndex8)
+	require.NoError(t, err)
+	assert.Error(t, SoftStretch(src, nil, other, nil))
+}
+
+// TestScaledFastPaths checks every scaled blit function against the generic
+// ones.
+func TestScaledFastPaths(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for _, pair := range blitPairs {
+		for _, setup := range blitSetups {
+			for _, linear := range []bool{false, true} {
+				name := fmt.Sprintf("%s->%s/%s/linear=%t", GetPixelFormatName(pair[0]), GetPixelFormatName(pair[1]), setup.name, linear)
+				t.Run(name, func(t *testing.T) {
+					src := newRandomSurface(t, rng, 13, 7, pair[0])
+					setup.set(src)
+					if linear {
+						src.SetScaleMode(ScaleModeLinear)
+					}
+					dst := newRandomSurface(t, rng, 37, 19, pair[1])
+					want := append([]byte(nil), dst.pixels...)
+					dr := Rect{X: -3, Y: 2, W: 29, H: 15}
+					require.NoError(t, BlitScaled(src, &Rect{X: 1, Y: 1, W: 11, H: 5}, dst, &dr))
+
+					generic, err := CreateRGBSurfaceWithFormatFrom(want, dst.w, dst.h, 0, dst.pitch, pair[1])
+					require.NoError(t, err)
+					generic.format.palette = dst.format.palette
+					m := src.blitMap
+					require.NoError(t, m.mapSurface(src, generic))
+					info := m.info
+					info.flags = m.flags
+					if linear && info.flags&copyColorKey > 0 && info.flags&copyBlendMask == 0 {
+						info.flags |= copyBlend
+					}
+					f := blitScaledGeneric
+					if linear {
+						f = blitLinearGeneric
+					}
+					r := stretchBlit(&info, f, src, Rect{X: 1, Y: 1, W: 11, H: 5}, generic, Rect{X: -3, Y: 2, W: 29, H: 15}, generic.clipRect)
+					assert.Equal(t, dr, r)
+					assertSamePixels(t, generic, dst)
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkBlitScaled(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	for _, mode := range []int{ScaleModeNearest, ScaleModeLinear} {
+		for _, generic := range []bool{false, true} {
+			name := fmt.Sprintf("mode=%d/generic=%t", mode, generic)
+			b.Run(name, func(b *testing.B) {
+				// a 64x64 sprite scaled up to 300x300
+				src := newRandomSurface(b, rng, 64, 64, PixelFormatARGB8888)
+				src.SetScaleMode(mode)
+				dst := newRandomSurface(b, rng, 320, 320, PixelFormatXRGB8888)
+				require.NoError(b, src.blitMap.mapSurface(src, dst))
+				info := src.blitMap.info
+				info.flags = src.blitMap.flags
+				f := scaledBlitFunc(src.format, dst.format, info.flags, mode != ScaleModeNearest)
+				if generic {
+					f = blitScaledGeneric
+					if mode != ScaleModeNearest {
+						f = blitLinearGeneric
+					}
+				}
+				b.SetBytes(300 * 300 * 4)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					stretchBlit(&info, f, src, Rect{W: 64, H: 64}, dst, Rect{X: 10, Y: 10, W: 300, H: 300}, dst.clipRect)
+				}
+			})
+		}
+	}
+}
@@ -1,17 +1,361 @@
 package video
 
+import (
+	"image"
+	"image/color"
+
+	"github.com/pkg/errors"
+)
+
+// Surface flags
+const (
+	SurfacePrealloc = 1 << iota // the pixels are owned by the caller
+	SurfaceRLEAccel             // the surface is run length encoded
+	SurfaceDontFree             // the surface is referenced internally
+)
+
+// Surface is a block of pixels in main memory. Surfaces implement
+// image.Image and draw.Image so they can be used with the standard image
+// packages.
 type Surface struct {
-	flags uint32
+	flags  uint32
 	format *PixelFormat
-	w, h int
-	pitch int
+	w, h   int
+	pitch  int
 
-	pixels []byte
+	pixels   []byte
 	userdata interface{}
 
-	locked int
+	locked   int
 	lockData interface{}
 
-	clipRect Rect
-	blitMap *BlitMap
+	clipRect  Rect
+	blitMap   *BlitMap
+	scaleMode int
+}
+
+// minPitch returns the number of bytes used by a row of pixels.
+func minPitch(format uint32, width int) int {
+	if BitsPerPixel(format) >= 8 {
+		return width * int(BytesPerPixel(format))
+	}
+	return (width*int(BitsPerPixel(format)) + 7) / 8
+}
+
+// calculatePitch returns the row size of a surface, rows are 4 byte aligned.
+func calculatePitch(format uint32, width int) int {
+	return (minPitch(format, width) + 3) &^ 3
+}
+
+// calculateSize returns the pitch and pixel buffer size of a surface, or an
+// error when they don't fit in an int.
+func calculateSize(format uint32, width, height int) (int, int, error) {
+	const maxInt = int(^uint(0) >> 1)
+	// at most 8 bits a pixel, plus rounding and alignment
+	if width > (maxInt-10)/8 {
+		return 0, 0, errors.Errorf("surface width %d is too large", width)
+	}
+	pitch := calculatePitch(format, width)
+	if height > 0 && pitch > maxInt/height {
+		return 0, 0, errors.Errorf("surface size %dx%d is too large", width, height)
+	}
+	return pitch, pitch * height, nil
+}
+
+// CreateRGBSurface creates a surface with the format matching the depth and
+// masks. If all masks are zero a default format for the depth is used.
+func CreateRGBSurface(flags uint32, width, height, depth int, rMask, gMask, bMask, aMask uint32) (*Surface, error) {
+	format := MasksToPixelFormatEnum(depth, rMask, gMask, bMask, aMask)
+	if format == PixelFormatUnknown {
+		return nil, errors.New("unknown pixel format")
+	}
+	return CreateRGBSurfaceWithFormat(flags, width, height, depth, format)
+}
+
+// CreateRGBSurfaceWithFormat creates a surface with the given pixel format,
+// the depth is ignored. Indexed formats get a default palette.
+func CreateRGBSurfaceWithFormat(flags uint32, width, height, depth int, format uint32) (*Surface, error) {
+	if width < 0 || height < 0 {
+		return nil, errors.Errorf("invalid surface size %dx%d", width, height)
+	}
+	pitch, size, err := calculateSize(format, width, height)
+	if err != nil {
+		return nil, err
+	}
+	s, err := newSurface(width, height, format)
+	if err != nil {
+		return nil, err
+	}
+	s.pitch = pitch
+	s.pixels = make([]byte, size)
+	return s, nil
+}
+
+// CreateRGBSurfaceFrom creates a surface from existing pixels, the format
+// matches the depth and masks as in CreateRGBSurface.
+func CreateRGBSurfaceFrom(pixels []byte, width, height, depth, pitch int, rMask, gMask, bMask, aMask uint32) (*Surface, error) {
+	format := MasksToPixelFormatEnum(depth, rMask, gMask, bMask, aMask)
+	if format == PixelFormatUnknown {
+		return nil, errors.New("unknown pixel format")
+	}
+	return CreateRGBSurfaceWithFormatFrom(pixels, width, height, depth, pitch, format)
+}
+
+// CreateRGBSurfaceWithFormatFrom creates a surface from existing pixels. The
+// surface uses the slice directly, so changes to either are visible in both.
+func CreateRGBSurfaceWithFormatFrom(pixels []byte, width, height, depth, pitch int, format uint32) (*Surface, error) {
+	if width < 0 || height < 0 {
+		return nil, errors.Errorf("invalid surface size %dx%d", width, height)
+	}
+	rowSize := minPitch(format, width)
+	if pitch < rowSize {
+		return nil, errors.Errorf("pitch %d is too small for width %d", pitch, width)
+	}
+	if height > 0 && len(pixels) < pitch*(height-1)+rowSize {
+		return nil, errors.Errorf("pixel buffer of %d bytes is too small for %dx%d", len(pixels), width, height)
+	}
+	s, err := newSurface(width, height, format)
+	if err != nil {
+		return nil, err
+	}
+	s.flags |= SurfacePrealloc
+	s.pitch = pitch
+	s.pixels = pixels
+	return s, nil
+}
+
+func newSurface(width, height int, format uint32) (*Surface, error) {
+	if IsPixelFormatFourCC(format) {
+		return nil, errors.Errorf("%s is not supported by surfaces", GetPixelFormatName(format))
+	}
+	f, err := AllocFormat(format)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to allocate surface format")
+	}
+	if IsPixelFormatIndexed(format) {
+		p, err := newFormatPalette(f)
+		if err != nil {
+			FreeFormat(f)
+			return nil, errors.Wrap(err, "unable to allocate surface palette")
+		}
+		err = SetPixelFormatPalette(f, p)
+		FreePalette(p)
+		if err != nil {
+			FreeFormat(f)
+			return nil, err
+		}
+	}
+	s := &Surface{
+		format:  f,
+		w:       width,
+		h:       height,
+		blitMap: newBlitMap(),
+	}
+	s.SetClipRect(nil)
+	if f.aMask != 0 {
+		s.SetBlendMode(BlendModeBlend)
+	}
+	return s, nil
+}
+
+// FreeSurface releases the surface format, the pixels are not touched if
+// they were supplied by the caller.
+func FreeSurface(s *Surface) {
+	if s == nil || s.flags&SurfaceDontFree > 0 {
+		return
+	}
+	FreeFormat(s.format)
+	s.format = nil
+	s.pixels = nil
+	s.blitMap.invalidate()
+}
+
+func (s *Surface) W() int               { return s.w }
+func (s *Surface) H() int               { return s.h }
+func (s *Surface) Pitch() int           { return s.pitch }
+func (s *Surface) Flags() uint32        { return s.flags }
+func (s *Surface) Format() *PixelFormat { return s.format }
+
+// Pixels returns the pixel data, for surfaces that need locking it is only
+// valid between Lock and Unlock.
+func (s *Surface) Pixels() []byte { return s.pixels }
+
+// MustLock returns whether the surface has to be locked before the pixels
+// are accessed directly.
+func (s *Surface) MustLock() bool {
+	return s.flags&SurfaceRLEAccel > 0
+}
+
+// Lock makes the pixels available for direct access, locks nest so every
+// Lock must be matched by an Unlock.
+func (s *Surface) Lock() error {
+	if s.format == nil {
+		return errors.New("surface has been freed")
+	}
+	s.locked++
+	return nil
+}
+
+// Unlock releases a lock taken by Lock.
+func (s *Surface) Unlock() {
+	if s.locked == 0 {
+		return
+	}
+	s.locked--
+}
+
+// SetClipRect sets the rectangle that blits and fills are limited to, a nil
+// rect clips to the whole surface. It returns false if the clip rect doesn't
+// intersect the surface, in which case nothing will be drawn.
+func (s *Surface) SetClipRect(rect *Rect) bool {
+	full := Rect{W: s.w, H: s.h}
+	if rect == nil {
+		s.clipRect = full
+		return true
+	}
+	var ok bool
+	s.clipRect, ok = IntersectRect(*rect, full)
+	return ok
+}
+
+// GetClipRect returns the current clip rect.
+func (s *Surface) GetClipRect() Rect {
+	return s.clipRect
+}
+
+// FillRect fills a rectangle with a pixel value in the surface format, a
+// nil rect fills the whole clip rect.
+func (s *Surface) FillRect(rect *Rect, pixel uint32) error {
+	if rect == nil {
+		rect = &s.clipRect
+	}
+	return s.FillRects([]Rect{*rect}, pixel)
+}
+
+// FillRects fills each rectangle with a pixel value in the surface format.
+func (s *Surface) FillRects(rects []Rect, pixel uint32) error {
+	if s.format == nil {
+		return errors.New("surface has been freed")
+	}
+	for _, r := range rects {
+		clipped, ok := IntersectRect(r, s.clipRect)
+		if !ok {
+			continue
+		}
+		if err := s.fillRect(clipped, pixel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Surface) fillRect(r Rect, pixel uint32) error {
+	if r.Empty() {
+		return nil
+	}
+	bpp := int(s.format.bytesPerPixel)
+	if s.format.bitsPerPixel < 8 {
+		for y := r.Y; y < r.Y+r.H; y++ {
+			row := s.pixels[y*s.pitch:]
+			for x := r.X; x < r.X+r.W; x++ {
+				setIndex(row, x, s.format.format, uint8(pixel))
+			}
+		}
+		return nil
+	}
+
+	// build the first row and copy it to the others
+	first := s.pixels[r.Y*s.pitch+r.X*bpp : r.Y*s.pitch+(r.X+r.W)*bpp]
+	putPixel(first, bpp, pixel)
+	for n := bpp; n < len(first); n *= 2 {
+		copy(first[n:], first[:n])
+	}
+	for y := r.Y + 1; y < r.Y+r.H; y++ {
+		offset := y*s.pitch + r.X*bpp
+		copy(s.pixels[offset:offset+len(first)], first)
+	}
+	return nil
+}
+
+// getPixel returns the raw pixel value at x, y.
+func (s *Surface) getPixel(x, y int) uint32 {
+	row := s.pixels[y*s.pitch:]
+	if s.format.bitsPerPixel < 8 {
+		return uint32(getIndex(row, x, s.format.format))
+	}
+	bpp := int(s.format.bytesPerPixel)
+	return pixelAt(row[x*bpp:], bpp)
+}
+
+// setPixel stores a raw pixel value at x, y.
+func (s *Surface) setPixel(x, y int, pixel uint32) {
+	row := s.pixels[y*s.pitch:]
+	if s.format.bitsPerPixel < 8 {
+		setIndex(row, x, s.format.format, uint8(pixel))
+		return
+	}
+	bpp := int(s.format.bytesPerPixel)
+	putPixel(row[x*bpp:], bpp, pixel)
+}
+
+// pixelAt reads a pixel of bpp bytes in host byte order.
+func pixelAt(b []byte, bpp int) uint32 {
+	switch bpp {
+	case 1:
+		return uint32(b[0])
+	case 2:
+		return uint32(hostByteOrder.Uint16(b))
+	case 3:
+		if bigEndian {
+			return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+		}
+		return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+	}
+	return hostByteOrder.Uint32(b)
+}
+
+// putPixel writes a pixel of bpp bytes in host byte order.
+func putPixel(b []byte, bpp int, pixel uint32) {
+	switch bpp {
+	case 1:
+		b[0] = uint8(pixel)
+	case 2:
+		hostByteOrder.PutUint16(b, uint16(pixel))
+	case 3:
+		if bigEndian {
+			b[0], b[1], b[2] = uint8(pixel>>16), uint8(pixel>>8), uint8(pixel)
+		} else {
+			b[0], b[1], b[2] = uint8(pixel), uint8(pixel>>8), uint8(pixel>>16)
+		}
+	default:
+		hostByteOrder.PutUint32(b, pixel)
+	}
+}
+
+// ColorModel implements image.Image, surface colors are not premultiplied.
+func (s *Surface) ColorModel() color.Model {
+	return color.NRGBAModel
+}
+
+// Bounds implements image.Image.
+func (s *Surface) Bounds() image.Rectangle {
+	return image.Rect(0, 0, s.w, s.h)
+}
+
+// At implements image.Image.
+func (s *Surface) At(x, y int) color.Color {
+	if x < 0 || y < 0 || x >= s.w || y >= s.h {
+		return color.NRGBA{}
+	}
+	r, g, b, a := GetRGBA(s.getPixel(x, y), s.format)
+	return color.NRGBA{R: r, G: g, B: b, A: a}
+}
+
+// Set implements draw.Image, the color is mapped to the surface format.
+func (s *Surface) Set(x, y int, c color.Color) {
+	if x < 0 || y < 0 || x >= s.w || y >= s.h {
+		return
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	s.setPixel(x, y, MapRGBA(s.format, n.R, n.G, n.B, n.A))
 }
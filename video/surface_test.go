@@ -0,0 +1,176 @@
+package video
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ draw.Image = (*Surface)(nil)
+
+func TestCreateRGBSurface(t *testing.T) {
+	s, err := CreateRGBSurface(0, 5, 3, 32, 0x00FF0000, 0x0000FF00, 0x000000FF, 0xFF000000)
+	require.NoError(t, err)
+	defer FreeSurface(s)
+	assert.Equal(t, uint32(PixelFormatARGB8888), s.Format().Format())
+	assert.Equal(t, 20, s.Pitch())
+	assert.Len(t, s.Pixels(), 60)
+	assert.Equal(t, Rect{W: 5, H: 3}, s.GetClipRect())
+
+	s, err = CreateRGBSurface(0, 5, 3, 24, 0, 0, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 16, s.Pitch())
+
+	s, err = CreateRGBSurfaceWithFormat(0, 5, 3, 0, PixelFormatXRGB8888)
+	require.NoError(t, err)
+	assert.Equal(t, 20, s.Pitch())
+
+	s, err = CreateRGBSurfaceWithFormat(0, 9, 2, 0, PixelFormatIndex1MSB)
+	require.NoError(t, err)
+	assert.Equal(t, 4, s.Pitch())
+	require.NotNil(t, s.Format().Palette())
+	assert.Equal(t, 2, s.Format().Palette().NumColors())
+
+	_, err = CreateRGBSurface(0, 1, 1, 32, 0x1, 0x2, 0x4, 0)
+	assert.Error(t, err)
+	_, err = CreateRGBSurfaceWithFormat(0, -1, 1, 0, PixelFormatRGB565)
+	assert.Error(t, err)
+
+	// sizes that overflow are an error, not an empty surface
+	huge := 1 << 31
+	_, err = CreateRGBSurfaceWithFormat(0, huge, huge, 0, PixelFormatARGB8888)
+	assert.Error(t, err)
+	_, err = CreateRGBSurfaceWithFormat(0, int(^uint(0)>>1), 1, 0, PixelFormatIndex1LSB)
+	assert.Error(t, err)
+}
+
+func TestCreateRGBSurfaceFrom(t *testing.T) {
+	pixels := make([]byte, 2*8)
+	s, err := CreateRGBSurfaceWithFormatFrom(pixels, 3, 2, 16, 8, PixelFormatRGB565)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(SurfacePrealloc), s.Flags()&SurfacePrealloc)
+
+	require.NoError(t, s.FillRect(nil, 0xF800))
+	assert.Equal(t, byte(0xF8), pixels[hostIndex(1, 2)])
+	FreeSurface(s)
+	assert.Equal(t, byte(0xF8), pixels[hostIndex(1, 2)])
+
+	_, err = CreateRGBSurfaceWithFormatFrom(pixels, 5, 2, 16, 8, PixelFormatRGB565)
+	assert.Error(t, err)
+	_, err = CreateRGBSurfaceWithFormatFrom(pixels[:13], 3, 2, 16, 8, PixelFormatRGB565)
+	assert.Error(t, err)
+	_, err = CreateRGBSurfaceFrom(pixels, 4, 2, 16, 8, 0xF800, 0x07E0, 0x001F, 0)
+	assert.NoError(t, err)
+}
+
+// hostIndex returns the offset of the given byte of a value in host order.
+func hostIndex(b, size int) int {
+	if bigEndian {
+		return size - 1 - b
+	}
+	return b
+}
+
+func TestLockSurface(t *testing.T) {
+	s, err := CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatRGB24)
+	require.NoError(t, err)
+	assert.False(t, s.MustLock())
+	require.NoError(t, s.Lock())
+	require.NoError(t, s.Lock())
+	assert.Equal(t, 2, s.locked)
+	s.Unlock()
+	s.Unlock()
+	s.Unlock()
+	assert.Equal(t, 0, s.locked)
+
+	FreeSurface(s)
+	assert.Error(t, s.Lock())
+}
+
+func TestClipRect(t *testing.T) {
+	s, err := CreateRGBSurfaceWithFormat(0, 10, 10, 0, PixelFormatIndex8)
+	require.NoError(t, err)
+
+	assert.True(t, s.SetClipRect(&Rect{X: -5, Y: 5, W: 10, H: 10}))
+	assert.Equal(t, Rect{X: 0, Y: 5, W: 5, H: 5}, s.GetClipRect())
+	assert.False(t, s.SetClipRect(&Rect{X: 20, Y: 20, W: 2, H: 2}))
+	assert.True(t, s.GetClipRect().Empty())
+	assert.True(t, s.SetClipRect(nil))
+	assert.Equal(t, Rect{W: 10, H: 10}, s.GetClipRect())
+}
+
+func TestFillRects(t *testing.T) {
+	formats := []uint32{
+		PixelFormatIndex1LSB, PixelFormatIndex4MSB, PixelFormatIndex8,
+		PixelFormatRGB565, PixelFormatRGB24, PixelFormatARGB8888,
+	}
+	for _, format := range formats {
+		t.Run(GetPixelFormatName(format), func(t *testing.T) {
+			s, err := CreateRGBSurfaceWithFormat(0, 7, 5, 0, format)
+			require.NoError(t, err)
+			defer FreeSurface(s)
+
+			s.SetClipRect(&Rect{X: 1, Y: 1, W: 5, H: 3})
+			require.NoError(t, s.FillRects([]Rect{{X: 0, Y: 0, W: 3, H: 3}, {X: 4, Y: 3, W: 10, H: 10}}, 1))
+			for y := 0; y < s.H(); y++ {
+				for x := 0; x < s.W(); x++ {
+					inside := (x >= 1 && x < 3 && y >= 1 && y < 3) || (x >= 4 && x < 6 && y == 3)
+					want := uint32(0)
+					if inside {
+						want = 1
+					}
+					assert.Equal(t, want, s.getPixel(x, y), "pixel (%d, %d)", x, y)
+				}
+			}
+
+			require.NoError(t, s.FillRect(nil, 0))
+			s.SetClipRect(nil)
+			require.NoError(t, s.FillRect(nil, 1))
+			for y := 0; y < s.H(); y++ {
+				for x := 0; x < s.W(); x++ {
+					require.Equal(t, uint32(1), s.getPixel(x, y))
+				}
+			}
+
+			FreeSurface(s)
+			assert.Error(t, s.FillRect(nil, 0))
+			assert.Error(t, s.FillRect(&Rect{W: 1, H: 1}, 0))
+		})
+	}
+}
+
+func TestSurfaceImage(t *testing.T) {
+	s, err := CreateRGBSurfaceWithFormat(0, 4, 4, 0, PixelFormatABGR8888)
+	require.NoError(t, err)
+	defer FreeSurface(s)
+
+	assert.Equal(t, image.Rect(0, 0, 4, 4), s.Bounds())
+	red := color.NRGBA{R: 0xFF, A: 0x80}
+	draw.Draw(s, image.Rect(1, 1, 3, 3), image.NewUniform(red), image.Point{}, draw.Src)
+	assert.Equal(t, red, s.At(2, 2))
+	assert.Equal(t, color.NRGBA{}, s.At(0, 0))
+	assert.Equal(t, color.NRGBA{}, s.At(-1, 4))
+	assert.Equal(t, MapRGBA(s.Format(), 0xFF, 0, 0, 0x80), s.getPixel(1, 2))
+
+	// out of bounds writes are ignored
+	s.Set(4, 0, red)
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, s))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, red, color.NRGBAModel.Convert(img.At(1, 1)))
+
+	dst, err := CreateRGBSurfaceWithFormat(0, 4, 4, 0, PixelFormatIndex8)
+	require.NoError(t, err)
+	defer FreeSurface(dst)
+	require.NoError(t, SetPaletteColors(dst.Format().Palette(), []Color{{A: 0xFF}, {R: 0xFF, A: 0xFF}}, 0))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.NRGBA{R: 0xF0, A: 0xFF}), image.Point{}, draw.Src)
+	assert.Equal(t, uint32(1), dst.getPixel(3, 3))
+}
@@ -1,12 +1,25 @@
 package video
 
-type Point struct {
-	x, y int
-}
+import (
+	"strings"
 
-type Rect struct {
-	x, y, w, h int
-}
+	"github.com/elliotmr/gdl/hint"
+	"github.com/elliotmr/gdl/log"
+	"github.com/pkg/errors"
+)
+
+// Texture access modes
+const (
+	TextureAccessStatic    = iota // Changes rarely, not lockable
+	TextureAccessStreaming        // Changes frequently, lockable
+	TextureAccessTarget           // Can be used as a render target
+)
+
+// Texture modulation flags
+const (
+	textureModulateColor = 1 << iota
+	textureModulateAlpha
+)
 
 type Texture struct {
 	format     uint32
@@ -14,11 +27,14 @@ type Texture struct {
 	w, h       int
 	modMode    int
 	blendMode  uint32
+	scaleMode  int
 	r, g, b, a uint8
 	renderer   *Renderer
 
+	// Textures in formats the driver doesn't support are kept in native,
+	// streaming and YUV textures keep their own pixels
 	native     *Texture
-	pixels     interface{}
+	pixels     []byte
 	pitch      int
 	lockedRect Rect
 
@@ -27,3 +43,496 @@ type Texture struct {
 	prev *Texture
 	next *Texture
 }
+
+// CreateTexture creates a texture for the renderer, the format is one of
+// the texture formats of the renderer info or gets converted to one.
+func (r *Renderer) CreateTexture(format uint32, access, w, h int) (*Texture, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	if format == PixelFormatUnknown {
+		format = r.info.textureFormats[0]
+	}
+	if IsPixelFormatIndexed(format) {
+		return nil, errors.New("palettized textures are not supported")
+	}
+	if w <= 0 || h <= 0 {
+		return nil, errors.Errorf("invalid texture size %dx%d", w, h)
+	}
+	if (r.info.maxTextureWidth > 0 && w > r.info.maxTextureWidth) ||
+		(r.info.maxTextureHeight > 0 && h > r.info.maxTextureHeight) {
+		return nil, errors.Errorf("texture size %dx%d is larger than the maximum of %dx%d",
+			w, h, r.info.maxTextureWidth, r.info.maxTextureHeight)
+	}
+	switch access {
+	case TextureAccessStatic, TextureAccessStreaming:
+	case TextureAccessTarget:
+		if r.info.flags&RendererTargetRexture == 0 {
+			return nil, errors.New("renderer doesn't support render targets")
+		}
+	default:
+		return nil, errors.Errorf("invalid texture access %d", access)
+	}
+	if r.supportsFormat(format) {
+		return r.createTexture(format, access, w, h)
+	}
+	if IsPixelFormatFourCC(format) && !isYUVFormat(format) {
+		return nil, errors.Errorf("texture format %s not supported", GetPixelFormatName(format))
+	}
+
+	// the native texture is created first so it follows the texture in the
+	// list, and is destroyed with it
+	native, err := r.createTexture(r.closestFormat(format), access, w, h)
+	if err != nil {
+		return nil, err
+	}
+	t := r.newTexture(format, access, w, h)
+	t.native = native
+	switch {
+	case isYUVFormat(format):
+		// YUV textures keep the whole frame, chroma is shared between pixels
+		var size int
+		t.pitch, size = yuvSize(format, w, h)
+		t.pixels = make([]byte, size)
+	case access == TextureAccessStreaming:
+		t.pitch = calculatePitch(format, w)
+		t.pixels = make([]byte, t.pitch*h)
+	}
+	r.linkTexture(t)
+	return t, nil
+}
+
+func (r *Renderer) supportsFormat(format uint32) bool {
+	for _, f := range r.info.textureFormats[:r.info.numTextureFormats] {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// closestFormat returns the first texture format of the renderer that has
+// alpha if the format has alpha.
+func (r *Renderer) closestFormat(format uint32) uint32 {
+	alpha := IsPixelFormatAlpha(format)
+	for _, f := range r.info.textureFormats[:r.info.numTextureFormats] {
+		if !IsPixelFormatFourCC(f) && IsPixelFormatAlpha(f) == alpha {
+			return f
+		}
+	}
+	return r.info.textureFormats[0]
+}
+
+func (r *Renderer) newTexture(format uint32, access, w, h int) *Texture {
+	return &Texture{
+		format:    format,
+		access:    access,
+		w:         w,
+		h:         h,
+		blendMode: BlendModeNone,
+		scaleMode: textureScaleMode(),
+		r:         0xFF,
+		g:         0xFF,
+		b:         0xFF,
+		a:         0xFF,
+		renderer:  r,
+	}
+}
+
+// textureScaleMode returns the scale mode for new textures from
+// hint.RenderScaleQuality, which is "nearest", "linear" or "best", or the
+// scale mode number.
+func textureScaleMode() int {
+	switch strings.ToLower(hint.GetHint(hint.RenderScaleQuality)) {
+	case "1", "linear":
+		return ScaleModeLinear
+	case "2", "best":
+		return ScaleModeBest
+	}
+	return ScaleModeNearest
+}
+
+// linkTexture adds a texture to the front of the renderer texture list.
+func (r *Renderer) linkTexture(t *Texture) {
+	t.next = r.textures
+	if r.textures != nil {
+		r.textures.prev = t
+	}
+	r.textures = t
+}
+
+// createTexture creates a texture with storage from the renderer driver.
+func (r *Renderer) createTexture(format uint32, access, w, h int) (*Texture, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	if w <= 0 || h <= 0 {
+		return nil, errors.Errorf("invalid texture size %dx%d", w, h)
+	}
+	t := r.newTexture(format, access, w, h)
+	if err := r.driverData.createTexture(r, t); err != nil {
+		return nil, errors.Wrap(err, "unable to create texture")
+	}
+	r.linkTexture(t)
+	return t, nil
+}
+
+// CreateTextureFromSurface creates a static texture with a copy of the
+// surface, and the color modulation and blend mode of the surface. Surfaces
+// with a color key get a texture with alpha instead.
+func (r *Renderer) CreateTextureFromSurface(surface *Surface) (*Texture, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	if surface == nil || surface.format == nil {
+		return nil, errors.New("invalid surface")
+	}
+	sf := surface.format.format
+	needAlpha := surface.format.aMask != 0 || surface.HasColorKey()
+	format := r.info.textureFormats[0]
+	if r.supportsFormat(sf) && IsPixelFormatAlpha(sf) == needAlpha {
+		format = sf
+	} else {
+		for _, f := range r.info.textureFormats[:r.info.numTextureFormats] {
+			if !IsPixelFormatFourCC(f) && IsPixelFormatAlpha(f) == needAlpha {
+				format = f
+				break
+			}
+		}
+	}
+
+	t, err := r.CreateTexture(format, TextureAccessStatic, surface.w, surface.h)
+	if err != nil {
+		return nil, err
+	}
+	// a color key is turned into alpha by the conversion
+	src := surface
+	if format != sf || surface.HasColorKey() {
+		if src, err = ConvertSurfaceFormat(surface, format, 0); err != nil {
+			DestroyTexture(t)
+			return nil, errors.Wrap(err, "unable to convert surface")
+		}
+		defer FreeSurface(src)
+	}
+	if err := t.Update(nil, src.pixels, src.pitch); err != nil {
+		DestroyTexture(t)
+		return nil, err
+	}
+
+	t.SetColorMod(surface.GetColorMod())
+	t.SetAlphaMod(surface.GetAlphaMod())
+	blendMode := surface.GetBlendMode()
+	if surface.HasColorKey() {
+		blendMode = BlendModeBlend
+	}
+	t.SetBlendMode(blendMode)
+	return t, nil
+}
+
+func (t *Texture) check() error {
+	if t == nil || t.renderer == nil {
+		return errors.New("invalid texture")
+	}
+	return nil
+}
+
+// Query returns the format, access and size of the texture.
+func (t *Texture) Query() (format uint32, access, w, h int, err error) {
+	if err := t.check(); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return t.format, t.access, t.w, t.h, nil
+}
+
+// SetColorMod sets the color that the texture is multiplied with when it is
+// copied.
+func (t *Texture) SetColorMod(r, g, b uint8) {
+	t.r, t.g, t.b = r, g, b
+	if r&g&b != 0xFF {
+		t.modMode |= textureModulateColor
+	} else {
+		t.modMode &^= textureModulateColor
+	}
+	if t.native != nil {
+		t.native.SetColorMod(r, g, b)
+	}
+}
+
+func (t *Texture) GetColorMod() (r, g, b uint8) {
+	return t.r, t.g, t.b
+}
+
+// SetAlphaMod sets the value that the texture alpha is multiplied with when
+// it is copied.
+func (t *Texture) SetAlphaMod(a uint8) {
+	t.a = a
+	if a != 0xFF {
+		t.modMode |= textureModulateAlpha
+	} else {
+		t.modMode &^= textureModulateAlpha
+	}
+	if t.native != nil {
+		t.native.SetAlphaMod(a)
+	}
+}
+
+func (t *Texture) GetAlphaMod() uint8 {
+	return t.a
+}
+
+// SetBlendMode sets how the texture is combined with the target when it is
+// copied.
+func (t *Texture) SetBlendMode(blendMode uint32) error {
+	if _, ok := blendModeFlags[blendMode]; !ok {
+		return errors.Errorf("unsupported blend mode 0x%x", blendMode)
+	}
+	t.blendMode = blendMode
+	if t.native != nil {
+		return t.native.SetBlendMode(blendMode)
+	}
+	return nil
+}
+
+func (t *Texture) GetBlendMode() uint32 {
+	return t.blendMode
+}
+
+// Update replaces a rectangle of the texture, a nil rect is the whole
+// texture, with pixels in the texture format. The rect is clipped to the
+// texture and the pixels start at its clipped corner.
+func (t *Texture) Update(rect *Rect, pixels []byte, pitch int) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	area, ok := t.updateArea(rect)
+	if !ok {
+		return nil
+	}
+	if isYUVFormat(t.format) {
+		src, err := yuvPlanes(t.format, area.W, area.H, pixels, pitch)
+		if err != nil {
+			return err
+		}
+		return t.updateYUVPlanes(area, src)
+	}
+	rowSize := minPitch(t.format, area.W)
+	if pitch < rowSize {
+		return errors.Errorf("pitch %d is too small for width %d", pitch, area.W)
+	}
+	if len(pixels) < pitch*(area.H-1)+rowSize {
+		return errors.Errorf("pixel buffer of %d bytes is too small for %dx%d", len(pixels), area.W, area.H)
+	}
+	if t.native != nil {
+		return t.updateNative(area, pixels, pitch)
+	}
+	return t.renderer.driverData.updateTexture(t, area, pixels, pitch)
+}
+
+// updateNative converts pixels in the texture format to the native texture.
+func (t *Texture) updateNative(rect Rect, pixels []byte, pitch int) error {
+	native := t.native
+	if native.access == TextureAccessStreaming {
+		dst, dstPitch, err := native.Lock(&rect)
+		if err != nil {
+			return err
+		}
+		defer native.Unlock()
+		return ConvertPixels(rect.W, rect.H, t.format, pixels, pitch, native.format, dst, dstPitch)
+	}
+	dstPitch := calculatePitch(native.format, rect.W)
+	dst := make([]byte, dstPitch*rect.H)
+	if err := ConvertPixels(rect.W, rect.H, t.format, pixels, pitch, native.format, dst, dstPitch); err != nil {
+		return err
+	}
+	return native.Update(&rect, dst, dstPitch)
+}
+
+// UpdateYUV replaces a rectangle of a YV12 or IYUV texture, a nil rect is
+// the whole texture, with separate Y, U and V planes.
+func (t *Texture) UpdateYUV(rect *Rect, yPlane []byte, yPitch int, uPlane []byte, uPitch int, vPlane []byte, vPitch int) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	if t.format != PixelFormatYV12 && t.format != PixelFormatIYUV {
+		return errors.New("texture format must be YV12 or IYUV")
+	}
+	area, ok := t.updateArea(rect)
+	if !ok {
+		return nil
+	}
+	src, err := t.srcPlanes(area, [][]byte{yPlane, uPlane, vPlane}, []int{yPitch, uPitch, vPitch})
+	if err != nil {
+		return err
+	}
+	if t.format == PixelFormatYV12 {
+		src[1], src[2] = src[2], src[1]
+	}
+	return t.updateYUVPlanes(area, src)
+}
+
+// UpdateNV replaces a rectangle of an NV12 or NV21 texture, a nil rect is
+// the whole texture, with a Y plane and an interleaved UV plane.
+func (t *Texture) UpdateNV(rect *Rect, yPlane []byte, yPitch int, uvPlane []byte, uvPitch int) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	if t.format != PixelFormatNV12 && t.format != PixelFormatNV21 {
+		return errors.New("texture format must be NV12 or NV21")
+	}
+	area, ok := t.updateArea(rect)
+	if !ok {
+		return nil
+	}
+	src, err := t.srcPlanes(area, [][]byte{yPlane, uvPlane}, []int{yPitch, uvPitch})
+	if err != nil {
+		return err
+	}
+	return t.updateYUVPlanes(area, src)
+}
+
+// updateArea clips an update rect to the texture.
+func (t *Texture) updateArea(rect *Rect) (Rect, bool) {
+	area := Rect{W: t.w, H: t.h}
+	if rect == nil {
+		return area, true
+	}
+	return IntersectRect(*rect, area)
+}
+
+// srcPlanes checks separate planes against the layout of the texture format
+// in memory order.
+func (t *Texture) srcPlanes(area Rect, pixels [][]byte, pitches []int) ([]yuvPlane, error) {
+	planes, err := yuvPlanes(t.format, t.w, t.h, t.pixels, t.pitch)
+	if err != nil {
+		return nil, err
+	}
+	for i := range planes {
+		p := &planes[i]
+		p.pixels, p.pitch = pixels[i], pitches[i]
+		if p.pitch < p.rowSize(area.W) {
+			return nil, errors.Errorf("plane %d pitch %d is too small for width %d", i, p.pitch, area.W)
+		}
+		if len(p.pixels) < p.pitch*(p.rows(area.H)-1)+p.rowSize(area.W) {
+			return nil, errors.Errorf("plane %d of %d bytes is too small for %dx%d", i, len(p.pixels), area.W, area.H)
+		}
+	}
+	return planes, nil
+}
+
+// updateYUVPlanes copies the planes of a YUV area into the texture and
+// converts it to the native texture.
+func (t *Texture) updateYUVPlanes(area Rect, src []yuvPlane) error {
+	dst, err := yuvPlanes(t.format, t.w, t.h, t.pixels, t.pitch)
+	if err != nil {
+		return err
+	}
+	for i, p := range dst {
+		p.copyFrom(area.X, area.Y, area.W, area.H, src[i])
+	}
+	return t.updateNativeYUV(area)
+}
+
+// updateNativeYUV converts the rows of rect to RGB and updates the native
+// texture with them. The rect is rounded out to whole chroma samples, as the
+// pixels sharing chroma with it may have changed too.
+func (t *Texture) updateNativeYUV(rect Rect) error {
+	x0, x1 := rect.X&^1, min((rect.X+rect.W+1)&^1, t.w)
+	y0, y1 := rect.Y, rect.Y+rect.H
+	if isPlanarYUVFormat(t.format) {
+		y0, y1 = y0&^1, min((y1+1)&^1, t.h)
+	}
+	rect = Rect{X: x0, Y: y0, W: x1 - x0, H: y1 - y0}
+
+	img, err := newYUVImage(t.format, t.w, t.h, t.pixels, t.pitch)
+	if err != nil {
+		return err
+	}
+	native := t.native
+	pitch := calculatePitch(native.format, t.w)
+	rgb, err := wrapPixels(t.w, rect.H, native.format, make([]byte, pitch*rect.H), pitch)
+	if err != nil {
+		return err
+	}
+	defer FreeSurface(rgb)
+	m := yuvMatrixFor(t.w, t.h)
+	yuvToRGB(img.subRows(y0, y1), &m, rgb)
+	return native.Update(&rect, rgb.pixels[x0*int(BytesPerPixel(native.format)):], pitch)
+}
+
+// Lock gives write access to a rectangle of a streaming texture, a nil rect
+// is the whole texture. The pixels start at the corner of the rect and are
+// only valid until Unlock, which applies the changes.
+func (t *Texture) Lock(rect *Rect) (pixels []byte, pitch int, err error) {
+	if err := t.check(); err != nil {
+		return nil, 0, err
+	}
+	if t.access != TextureAccessStreaming {
+		return nil, 0, errors.New("texture is not streaming")
+	}
+	area := Rect{W: t.w, H: t.h}
+	if rect != nil {
+		if clipped, ok := IntersectRect(*rect, area); !ok || clipped != *rect {
+			return nil, 0, errors.Errorf("lock rect %v is outside the texture", *rect)
+		}
+		area = *rect
+	}
+	if isPlanarYUVFormat(t.format) && area != (Rect{W: t.w, H: t.h}) {
+		return nil, 0, errors.New("planar YUV textures only support full texture locks")
+	}
+	if t.native != nil {
+		t.lockedRect = area
+		offset := area.Y*t.pitch + area.X*int(BytesPerPixel(t.format))
+		return t.pixels[offset:], t.pitch, nil
+	}
+	return t.renderer.driverData.lockTexture(t, area)
+}
+
+// Unlock applies the changes made since Lock.
+func (t *Texture) Unlock() {
+	if t.check() != nil || t.access != TextureAccessStreaming {
+		return
+	}
+	if isYUVFormat(t.format) {
+		t.updateNativeYUV(t.lockedRect)
+		return
+	}
+	if t.native != nil {
+		rect := t.lockedRect
+		offset := rect.Y*t.pitch + rect.X*int(BytesPerPixel(t.format))
+		t.updateNative(rect, t.pixels[offset:], t.pitch)
+		return
+	}
+	t.renderer.driverData.unlockTexture(t)
+}
+
+// DestroyTexture releases the texture, it can't be used afterwards.
+func DestroyTexture(t *Texture) {
+	if t.check() != nil {
+		return
+	}
+	r := t.renderer
+	if r.target == t {
+		if err := r.SetRenderTarget(nil); err != nil {
+			log.Error(log.CategoryRender, "unable to reset render target: %v", err)
+		}
+	}
+	if t.next != nil {
+		t.next.prev = t.prev
+	}
+	if t.prev != nil {
+		t.prev.next = t.next
+	} else {
+		r.textures = t.next
+	}
+	t.prev, t.next = nil, nil
+
+	if t.native != nil {
+		DestroyTexture(t.native)
+		t.native = nil
+	}
+	if t.driverData != nil && r.driverData != nil {
+		r.driverData.destroyTexture(t)
+	}
+	t.pixels = nil
+	t.renderer = nil
+}
@@ -0,0 +1,228 @@
+package video
+
+import (
+	"testing"
+
+	"github.com/elliotmr/gdl/hint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// textureList returns the textures of the renderer in list order, checking
+// the back links on the way.
+func textureList(t *testing.T, r *Renderer) []*Texture {
+	var list []*Texture
+	var prev *Texture
+	for tex := r.textures; tex != nil; tex = tex.next {
+		require.Equal(t, prev, tex.prev)
+		list = append(list, tex)
+		prev = tex
+	}
+	return list
+}
+
+func TestCreateTexture(t *testing.T) {
+	r, _ := newTestRenderer(t, 4, 4)
+
+	a, err := r.CreateTexture(PixelFormatARGB8888, TextureAccessStatic, 3, 2)
+	require.NoError(t, err)
+	format, access, w, h, err := a.Query()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(PixelFormatARGB8888), format)
+	assert.Equal(t, []int{TextureAccessStatic, 3, 2}, []int{access, w, h})
+	assert.Nil(t, a.native)
+
+	b, err := r.CreateTexture(PixelFormatUnknown, TextureAccessTarget, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, r.info.textureFormats[0], b.format)
+
+	// unsupported formats go through a native texture of the closest format
+	c, err := r.CreateTexture(PixelFormatARGB4444, TextureAccessStreaming, 2, 2)
+	require.NoError(t, err)
+	require.NotNil(t, c.native)
+	assert.Equal(t, uint32(PixelFormatARGB8888), c.native.format)
+	assert.Equal(t, TextureAccessStreaming, c.native.access)
+	assert.Equal(t, []*Texture{c, c.native, b, a}, textureList(t, r))
+
+	DestroyTexture(b)
+	assert.Equal(t, []*Texture{c, c.native, a}, textureList(t, r))
+	native := c.native
+	DestroyTexture(c)
+	assert.Equal(t, []*Texture{a}, textureList(t, r))
+	assert.Nil(t, native.renderer)
+	DestroyTexture(c)
+	_, _, _, _, err = c.Query()
+	assert.Error(t, err)
+
+	for _, tc := range []struct {
+		format uint32
+		access int
+		w, h   int
+	}{
+		{PixelFormatIndex8, TextureAccessStatic, 1, 1},
+		{PixelFormatARGB8888, TextureAccessStatic, 0, 1},
+		{PixelFormatARGB8888, 3, 1, 1},
+	} {
+		_, err := r.CreateTexture(tc.format, tc.access, tc.w, tc.h)
+		assert.Error(t, err, "%+v", tc)
+	}
+
+	DestroyRenderer(r)
+	assert.Nil(t, r.textures)
+	assert.Nil(t, a.renderer)
+}
+
+func TestTextureUpdateAndLock(t *testing.T) {
+	r, s := newTestRenderer(t, 2, 2)
+	const a, b, c, d = 0xFF0000FF, 0xFF00FF00, 0xFFFF0000, 0xFFFFFFFF
+
+	static, err := r.CreateTexture(PixelFormatARGB8888, TextureAccessStatic, 2, 2)
+	require.NoError(t, err)
+	pixels := make([]byte, 16)
+	for i, p := range []uint32{a, b, c, d} {
+		putPixel(pixels[i*4:], 4, p)
+	}
+	require.NoError(t, static.Update(nil, pixels, 8))
+	require.NoError(t, static.Update(&Rect{X: 1, Y: 1, W: 5, H: 5}, pixels, 8))
+	require.NoError(t, r.Copy(static, nil, nil))
+	assert.Equal(t, [][]uint32{{a, b}, {c, a}}, surfacePixels(s, Rect{W: 2, H: 2}))
+	_, _, err = static.Lock(nil)
+	assert.Error(t, err)
+	assert.Error(t, static.Update(nil, pixels[:8], 8))
+
+	streaming, err := r.CreateTexture(PixelFormatARGB8888, TextureAccessStreaming, 2, 2)
+	require.NoError(t, err)
+	locked, pitch, err := streaming.Lock(&Rect{X: 1, Y: 0, W: 1, H: 2})
+	require.NoError(t, err)
+	putPixel(locked, 4, d)
+	putPixel(locked[pitch:], 4, c)
+	streaming.Unlock()
+	require.NoError(t, r.Copy(streaming, nil, nil))
+	assert.Equal(t, [][]uint32{{0, d}, {0, c}}, surfacePixels(s, Rect{W: 2, H: 2}))
+	_, _, err = streaming.Lock(&Rect{X: 1, W: 2, H: 1})
+	assert.Error(t, err)
+}
+
+func TestNativeTexture(t *testing.T) {
+	r, s := newTestRenderer(t, 2, 1)
+	f, err := AllocFormat(PixelFormatARGB4444)
+	require.NoError(t, err)
+	defer FreeFormat(f)
+
+	static, err := r.CreateTexture(PixelFormatARGB4444, TextureAccessStatic, 2, 1)
+	require.NoError(t, err)
+	pixels := make([]byte, 4)
+	putPixel(pixels, 2, MapRGBA(f, 0xFF, 0, 0, 0xFF))
+	putPixel(pixels[2:], 2, MapRGBA(f, 0, 0, 0xFF, 0xFF))
+	require.NoError(t, static.Update(nil, pixels, 4))
+	require.NoError(t, r.Copy(static, nil, nil))
+	assert.Equal(t, [][]uint32{{0xFFFF0000, 0xFF0000FF}}, surfacePixels(s, Rect{W: 2, H: 1}))
+
+	streaming, err := r.CreateTexture(PixelFormatARGB4444, TextureAccessStreaming, 2, 1)
+	require.NoError(t, err)
+	locked, _, err := streaming.Lock(&Rect{X: 1, W: 1, H: 1})
+	require.NoError(t, err)
+	putPixel(locked, 2, MapRGBA(f, 0, 0xFF, 0, 0xFF))
+	streaming.Unlock()
+	require.NoError(t, r.Copy(streaming, nil, nil))
+	assert.Equal(t, [][]uint32{{0, 0xFF00FF00}}, surfacePixels(s, Rect{W: 2, H: 1}))
+
+	// modulation and blending reach the native texture
+	streaming.SetColorMod(0x80, 0x80, 0x80)
+	streaming.SetAlphaMod(0x80)
+	require.NoError(t, streaming.SetBlendMode(BlendModeAdd))
+	assert.Equal(t, uint8(0x80), streaming.native.a)
+	assert.Equal(t, textureModulateColor|textureModulateAlpha, streaming.modMode)
+	assert.Equal(t, uint32(BlendModeAdd), streaming.native.GetBlendMode())
+	r.SetDrawColor(0, 0, 0, 0xFF)
+	require.NoError(t, r.Clear())
+	require.NoError(t, r.Copy(streaming, nil, nil))
+	assert.Equal(t, [][]uint32{{0xFF000000, 0xFF004000}}, surfacePixels(s, Rect{W: 2, H: 1}))
+	assert.Error(t, streaming.SetBlendMode(3))
+	streaming.SetColorMod(0xFF, 0xFF, 0xFF)
+	assert.Equal(t, textureModulateAlpha, streaming.modMode)
+}
+
+func TestCreateTextureFromSurface(t *testing.T) {
+	r, _ := newTestRenderer(t, 2, 2)
+
+	argb, err := CreateRGBSurfaceWithFormat(0, 2, 1, 0, PixelFormatARGB8888)
+	require.NoError(t, err)
+	argb.setPixel(1, 0, 0x80FF0000)
+	argb.SetAlphaMod(0x40)
+	require.NoError(t, argb.SetBlendMode(BlendModeMod))
+	tex, err := r.CreateTextureFromSurface(argb)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(PixelFormatARGB8888), tex.format)
+	assert.Equal(t, TextureAccessStatic, tex.access)
+	assert.Equal(t, uint8(0x40), tex.GetAlphaMod())
+	assert.Equal(t, uint32(BlendModeMod), tex.GetBlendMode())
+	assert.Equal(t, [][]uint32{{0, 0x80FF0000}}, surfacePixels(tex.driverData.(*Surface), Rect{W: 2, H: 1}))
+
+	// formats without alpha that the renderer lacks get the first opaque one
+	rgb555, err := CreateRGBSurfaceWithFormat(0, 1, 1, 0, PixelFormatRGB555)
+	require.NoError(t, err)
+	rgb555.setPixel(0, 0, 0x7C00)
+	rgb555.SetColorMod(1, 2, 3)
+	tex, err = r.CreateTextureFromSurface(rgb555)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(PixelFormatXRGB8888), tex.format)
+	red, green, blue := tex.GetColorMod()
+	assert.Equal(t, []uint8{1, 2, 3}, []uint8{red, green, blue})
+	assert.Equal(t, uint32(0xFF0000), tex.driverData.(*Surface).getPixel(0, 0)&0xFFFFFF)
+
+	// a color key becomes transparency
+	indexed, err := CreateRGBSurfaceWithFormat(0, 2, 1, 0, PixelFormatIndex8)
+	require.NoError(t, err)
+	indexed.setPixel(1, 0, 1)
+	require.NoError(t, indexed.SetColorKey(true, 0))
+	tex, err = r.CreateTextureFromSurface(indexed)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(PixelFormatARGB8888), tex.format)
+	assert.Equal(t, uint32(BlendModeBlend), tex.GetBlendMode())
+	ts := tex.driverData.(*Surface)
+	_, _, _, alpha := GetRGBA(ts.getPixel(0, 0), ts.format)
+	assert.Equal(t, uint8(0), alpha)
+	_, _, _, alpha = GetRGBA(ts.getPixel(1, 0), ts.format)
+	assert.Equal(t, uint8(0xFF), alpha)
+
+	// also when the surface already has a supported alpha format
+	keyed, err := CreateRGBSurfaceWithFormat(0, 2, 1, 0, PixelFormatARGB8888)
+	require.NoError(t, err)
+	keyed.setPixel(0, 0, 0xFFFF00FF)
+	keyed.setPixel(1, 0, 0xFF00FF00)
+	require.NoError(t, keyed.SetColorKey(true, 0xFFFF00FF))
+	tex, err = r.CreateTextureFromSurface(keyed)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(PixelFormatARGB8888), tex.format)
+	assert.Equal(t, [][]uint32{{0x00FF00FF, 0xFF00FF00}}, surfacePixels(tex.driverData.(*Surface), Rect{W: 2, H: 1}))
+	assert.True(t, keyed.HasColorKey())
+
+	_, err = r.CreateTextureFromSurface(nil)
+	assert.Error(t, err)
+}
+
+func TestTextureScaleQuality(t *testing.T) {
+	defer hint.ClearHints()
+	r, s := newTestRenderer(t, 4, 1)
+	for _, tc := range []struct {
+		quality string
+		mode    int
+	}{{"", ScaleModeNearest}, {"nearest", ScaleModeNearest}, {"Linear", ScaleModeLinear}, {"2", ScaleModeBest}} {
+		hint.SetHint(hint.RenderScaleQuality, tc.quality)
+		tex, err := r.CreateTexture(PixelFormatARGB8888, TextureAccessStatic, 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, tc.mode, tex.scaleMode, tc.quality)
+		assert.Equal(t, tc.mode, tex.driverData.(*Surface).GetScaleMode(), tc.quality)
+		DestroyTexture(tex)
+	}
+
+	// linear textures are filtered when stretched
+	const black, white = 0xFF000000, 0xFFFFFFFF
+	hint.SetHint(hint.RenderScaleQuality, "linear")
+	tex := newTestTexture(t, r, [][]uint32{{black, white}})
+	require.NoError(t, r.Copy(tex, nil, nil))
+	row := surfacePixels(s, Rect{W: 4, H: 1})[0]
+	assert.NotContains(t, row[1:3], uint32(black))
+	assert.NotContains(t, row[1:3], uint32(white))
+}
@@ -0,0 +1,117 @@
+package video
+
+import "math"
+
+// Triangles are rasterized in fixed point with subpixelBits of precision,
+// sampling each pixel at its center.
+const (
+	subpixelBits = 8
+	subpixelOne  = 1 << subpixelBits
+	subpixelHalf = subpixelOne / 2
+)
+
+// triangleVertex is a vertex in fixed point surface coordinates, with the
+// values interpolated across the triangle.
+type triangleVertex struct {
+	x, y       int64
+	r, g, b, a float64
+	u, v       float64
+}
+
+// newTriangleVertex moves a vertex from output pixels relative to the
+// viewport to fixed point surface coordinates, and modulates its color.
+func newTriangleVertex(vertex Vertex, viewport Rect, mod Color) triangleVertex {
+	x := (float64(vertex.Position.X) + float64(viewport.X)) * subpixelOne
+	y := (float64(vertex.Position.Y) + float64(viewport.Y)) * subpixelOne
+	c := vertex.Color
+	return triangleVertex{
+		x: int64(math.Round(x)),
+		y: int64(math.Round(y)),
+		r: float64(uint32(c.R) * uint32(mod.R) / 255),
+		g: float64(uint32(c.G) * uint32(mod.G) / 255),
+		b: float64(uint32(c.B) * uint32(mod.B) / 255),
+		a: float64(uint32(c.A) * uint32(mod.A) / 255),
+		u: float64(vertex.TexCoord.X),
+		v: float64(vertex.TexCoord.Y),
+	}
+}
+
+// edgeFunction returns twice the signed area of the triangle a, b, (x, y),
+// which is positive when the point is inside the edge from a to b.
+func edgeFunction(a, b triangleVertex, x, y int64) int64 {
+	return (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+}
+
+// isTopLeft reports whether pixels centered on the edge from a to b belong
+// to the triangle. With y pointing down and the inside to the right of the
+// edge, a top edge goes right and a left edge goes up.
+func isTopLeft(a, b triangleVertex) bool {
+	dy := b.y - a.y
+	return dy < 0 || dy == 0 && b.x > a.x
+}
+
+// fillTriangle calls plot for each pixel in clip with its center inside the
+// triangle, passing the weights of the vertices at the center. Pixels
+// centered on an edge are only plotted for top and left edges, so triangles
+// sharing an edge never both plot a pixel.
+func fillTriangle(clip Rect, v [3]triangleVertex, plot func(x, y int, w [3]float64)) {
+	area := edgeFunction(v[0], v[1], v[2].x, v[2].y)
+	if area == 0 {
+		return
+	}
+	// wind the triangle so the inside has positive edge functions, the
+	// weights are swapped back for plot
+	swapped := area < 0
+	if swapped {
+		v[1], v[2] = v[2], v[1]
+		area = -area
+	}
+
+	minX, maxX := min(v[0].x, v[1].x, v[2].x), max(v[0].x, v[1].x, v[2].x)
+	minY, maxY := min(v[0].y, v[1].y, v[2].y), max(v[0].y, v[1].y, v[2].y)
+	bounds := Rect{
+		X: int(math.Ceil(float64(minX-subpixelHalf) / subpixelOne)),
+		Y: int(math.Ceil(float64(minY-subpixelHalf) / subpixelOne)),
+	}
+	bounds.W = int(math.Floor(float64(maxX-subpixelHalf)/subpixelOne)) + 1 - bounds.X
+	bounds.H = int(math.Floor(float64(maxY-subpixelHalf)/subpixelOne)) + 1 - bounds.Y
+	bounds, ok := IntersectRect(bounds, clip)
+	if !ok {
+		return
+	}
+
+	// edge i is opposite vertex i, its function grows by stepX and stepY
+	// from one pixel to the next
+	var start, stepX, stepY, bias [3]int64
+	cx := int64(bounds.X)*subpixelOne + subpixelHalf
+	cy := int64(bounds.Y)*subpixelOne + subpixelHalf
+	for i := range v {
+		a, b := v[(i+1)%3], v[(i+2)%3]
+		start[i] = edgeFunction(a, b, cx, cy)
+		stepX[i] = -(b.y - a.y) * subpixelOne
+		stepY[i] = (b.x - a.x) * subpixelOne
+		if !isTopLeft(a, b) {
+			bias[i] = 1
+		}
+	}
+
+	fArea := float64(area)
+	for y := bounds.Y; y < bounds.Y+bounds.H; y++ {
+		e := start
+		for x := bounds.X; x < bounds.X+bounds.W; x++ {
+			if e[0] >= bias[0] && e[1] >= bias[1] && e[2] >= bias[2] {
+				w := [3]float64{float64(e[0]) / fArea, float64(e[1]) / fArea, float64(e[2]) / fArea}
+				if swapped {
+					w[1], w[2] = w[2], w[1]
+				}
+				plot(x, y, w)
+			}
+			for i := range e {
+				e[i] += stepX[i]
+			}
+		}
+		for i := range start {
+			start[i] += stepY[i]
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package video
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func triangleAt(points ...FPoint) [3]triangleVertex {
+	var v [3]triangleVertex
+	for i, p := range points {
+		v[i] = newTriangleVertex(Vertex{Position: p}, Rect{}, Color{})
+	}
+	return v
+}
+
+func TestFillTriangle(t *testing.T) {
+	clip := Rect{W: 10, H: 10}
+	plotted := func(v [3]triangleVertex) map[Point][3]float64 {
+		pixels := map[Point][3]float64{}
+		fillTriangle(clip, v, func(x, y int, w [3]float64) {
+			pixels[Point{X: x, Y: y}] = w
+		})
+		return pixels
+	}
+
+	// the diagonal is a bottom right edge, the pixels centered on it are out
+	v := triangleAt(FPoint{0, 0}, FPoint{4, 0}, FPoint{0, 4})
+	want := []Point{{0, 0}, {1, 0}, {2, 0}, {0, 1}, {1, 1}, {0, 2}}
+	pixels := plotted(v)
+	assert.ElementsMatch(t, want, keys(pixels))
+	w := pixels[Point{0, 0}]
+	assert.InDeltaSlice(t, []float64{0.75, 0.125, 0.125}, w[:], 1e-9)
+
+	// the winding doesn't matter and the weights follow the vertices
+	v[1], v[2] = v[2], v[1]
+	pixels = plotted(v)
+	assert.ElementsMatch(t, want, keys(pixels))
+	w = pixels[Point{2, 0}]
+	assert.InDeltaSlice(t, []float64{0.25, 0.125, 0.625}, w[:], 1e-9)
+
+	// flipped, the diagonal is a top left edge
+	v = triangleAt(FPoint{4, 4}, FPoint{0, 4}, FPoint{4, 0})
+	assert.ElementsMatch(t, []Point{{3, 0}, {2, 1}, {3, 1}, {1, 2}, {2, 2}, {3, 2}, {0, 3}, {1, 3}, {2, 3}, {3, 3}}, keys(plotted(v)))
+
+	assert.Empty(t, plotted(triangleAt(FPoint{0, 0}, FPoint{2, 2}, FPoint{4, 4})))
+	assert.Empty(t, plotted(triangleAt(FPoint{0, 0}, FPoint{0.4, 0}, FPoint{0, 0.4})))
+	clip = Rect{X: 1, W: 1, H: 10}
+	assert.ElementsMatch(t, []Point{{1, 0}, {1, 1}}, keys(plotted(triangleAt(FPoint{0, 0}, FPoint{4, 0}, FPoint{0, 4}))))
+}
+
+// TestFillTriangleSharedEdges fills a square with a fan of triangles, every
+// pixel has to be plotted exactly once.
+func TestFillTriangleSharedEdges(t *testing.T) {
+	for _, center := range []FPoint{{5.5, 5.5}, {4, 6}, {3.3, 7.1}} {
+		rim := []FPoint{{1, 1}, {4.5, 1}, {9, 1}, {9, 3.5}, {9, 9}, {6, 9}, {1, 9}, {1, 5.25}}
+		counts := map[Point]int{}
+		for i := range rim {
+			v := triangleAt(center, rim[i], rim[(i+1)%len(rim)])
+			fillTriangle(Rect{W: 10, H: 10}, v, func(x, y int, w [3]float64) {
+				counts[Point{X: x, Y: y}]++
+			})
+		}
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 10; x++ {
+				want := 0
+				if x >= 1 && x < 9 && y >= 1 && y < 9 {
+					want = 1
+				}
+				assert.Equal(t, want, counts[Point{X: x, Y: y}], "center %v pixel %d,%d", center, x, y)
+			}
+		}
+	}
+}
+
+func keys(pixels map[Point][3]float64) []Point {
+	var out []Point
+	for p := range pixels {
+		out = append(out, p)
+	}
+	return out
+}
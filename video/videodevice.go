@@ -1,7 +1,11 @@
 package video
 
 import (
+	"strings"
+
 	"github.com/elliotmr/gdl/event"
+	"github.com/elliotmr/gdl/hint"
+	"github.com/pkg/errors"
 )
 
 // I don't really like this, but it will make the porting much easier.
@@ -27,6 +31,7 @@ type videoDevice interface {
 	setWindowTitle(windows *Window)
 	setWindowIcon(window *Window, icon *Surface)
 	setWindowPosition(window *Window)
+	setWindowSize(window *Window)
 	setWindowMinimumSize(window *Window)
 	setWindowMaximumSize(window *Window)
 	getWindowBordersSize(window *Window) (int, int, int, int, error)
@@ -46,8 +51,8 @@ type videoDevice interface {
 	getWindowGammaRamp(window *Window, ramp []uint16)
 	setWindowGrab(window *Window, grabbed bool)
 	destroyWindow(window *Window)
-	createWindowFramebuffer(window *Window)
-	updateWindowFramebuffer(window *Window)
+	createWindowFramebuffer(window *Window) (format uint32, pixels []byte, pitch int, err error)
+	updateWindowFramebuffer(window *Window, rects []Rect) error
 	destroyWindowFramebuffer(window *Window)
 	onWindowEnter(window *Window)
 
@@ -65,6 +70,7 @@ type videoDevice interface {
 
 type videoDeviceData struct {
 	name               string
+	initialized        bool
 	suspendScreenSaver bool
 	displays []*videoDisplay
 	windows []*Window
@@ -72,4 +78,46 @@ type videoDeviceData struct {
 	windowMagic uint8
 	nextObjectID uint32
 	clipboardText string
-}
\ No newline at end of file
+}
+
+// VideoInit initializes the video subsystem with the named driver. If
+// driverName is empty the driver named by hint.VideoDriver is used, or the
+// platform driver if the hint isn't set. The driver reads its hints here, so
+// they must be set before the subsystem is initialized. Calling VideoInit
+// again shuts down the running subsystem first.
+func VideoInit(driverName string) error {
+	if this == nil {
+		return errors.New("no video driver available")
+	}
+	VideoQuit()
+	if driverName == "" {
+		driverName = hint.GetHint(hint.VideoDriver)
+	}
+	if driverName != "" && !strings.EqualFold(driverName, this.data().name) {
+		return errors.Errorf("%s not available", driverName)
+	}
+	this.init()
+	this.data().initialized = true
+	return nil
+}
+
+// VideoQuit destroys every window and shuts down the video subsystem.
+func VideoQuit() {
+	if this == nil || !this.data().initialized {
+		return
+	}
+	for len(this.data().windows) > 0 {
+		DestroyWindow(this.data().windows[0])
+	}
+	this.quit()
+	this.data().initialized = false
+}
+
+// GetCurrentVideoDriver returns the name of the initialized video driver, or
+// an empty string if the video subsystem isn't initialized.
+func GetCurrentVideoDriver() string {
+	if this == nil || !this.data().initialized {
+		return ""
+	}
+	return this.data().name
+}
@@ -1,3 +1,416 @@
 //+build linux
 
 package video
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/elliotmr/gdl/event"
+	"github.com/elliotmr/gdl/hint"
+	"github.com/elliotmr/gdl/log"
+	"github.com/pkg/errors"
+)
+
+// defaultOffscreenDisplays is used when hint.VideoOffscreenDisplays isn't set.
+const defaultOffscreenDisplays = "1920x1080@60,1280x720@60,800x600@60"
+
+// offscreenWindowKey is the window data key holding the offscreen window.
+const offscreenWindowKey = "_GDL_OffscreenWindow"
+
+func init() {
+	this = &offscreenVideoDevice{
+		deviceData: &videoDeviceData{name: "offscreen"},
+	}
+}
+
+// offscreenVideoDevice keeps windows in memory without showing them, so
+// window and rendering code runs headless. The displays and their modes are
+// read from hint.VideoOffscreenDisplays, a list of displays separated by ';'
+// with the modes of each separated by ',' and written as WxH or WxH@Hz. The
+// first mode of a display is its desktop mode.
+type offscreenVideoDevice struct {
+	deviceData *videoDeviceData
+	focus      *Window
+}
+
+// offscreenWindow holds the framebuffer of a window and the screen it is
+// presented to.
+type offscreenWindow struct {
+	framebuffer []byte
+	screen      []byte
+	pitch       int
+
+	// the windowed area to return to when a maximized window is restored
+	restored Rect
+	gamma    []uint16
+}
+
+// parseOffscreenDisplays creates displays from the format of
+// hint.VideoOffscreenDisplays.
+func parseOffscreenDisplays(spec string) ([]*videoDisplay, error) {
+	var displays []*videoDisplay
+	for i, modeList := range strings.Split(spec, ";") {
+		display := &videoDisplay{name: fmt.Sprintf("Offscreen %d", i)}
+		for _, m := range strings.Split(modeList, ",") {
+			mode, err := parseOffscreenMode(strings.TrimSpace(m))
+			if err != nil {
+				return nil, errors.Wrapf(err, "display %d", i)
+			}
+			display.modes = append(display.modes, mode)
+		}
+		display.numDisplayModes = len(display.modes)
+		display.maxDisplayModes = len(display.modes)
+		display.desktopMode = display.modes[0]
+		display.currentMode = display.modes[0]
+		displays = append(displays, display)
+	}
+	return displays, nil
+}
+
+// parseOffscreenMode parses a display mode written as WxH or WxH@Hz.
+func parseOffscreenMode(s string) (DisplayMode, error) {
+	mode := DisplayMode{format: PixelFormatXRGB8888}
+	size, rate, hasRate := strings.Cut(s, "@")
+	w, h, ok := strings.Cut(size, "x")
+	if !ok {
+		return mode, errors.Errorf("invalid display mode %q", s)
+	}
+	var err error
+	if mode.w, err = strconv.Atoi(w); err != nil || mode.w <= 0 {
+		return mode, errors.Errorf("invalid display mode width %q", s)
+	}
+	if mode.h, err = strconv.Atoi(h); err != nil || mode.h <= 0 {
+		return mode, errors.Errorf("invalid display mode height %q", s)
+	}
+	if hasRate {
+		if mode.refreshRate, err = strconv.Atoi(rate); err != nil || mode.refreshRate < 0 {
+			return mode, errors.Errorf("invalid display mode refresh rate %q", s)
+		}
+	}
+	return mode, nil
+}
+
+func (ovd *offscreenVideoDevice) data() *videoDeviceData {
+	return ovd.deviceData
+}
+
+func (ovd *offscreenVideoDevice) init() {
+	spec := hint.GetHint(hint.VideoOffscreenDisplays)
+	if spec == "" {
+		spec = defaultOffscreenDisplays
+	}
+	displays, err := parseOffscreenDisplays(spec)
+	if err != nil {
+		log.Error(log.CategoryVideo, "offscreen displays: %v", err)
+		displays, _ = parseOffscreenDisplays(defaultOffscreenDisplays)
+	}
+	for _, display := range displays {
+		display.device = ovd
+	}
+	ovd.deviceData.displays = displays
+}
+
+func (ovd *offscreenVideoDevice) quit() {
+	ovd.deviceData.displays = nil
+	ovd.focus = nil
+}
+
+// Pump does nothing, offscreen windows get no input.
+func (ovd *offscreenVideoDevice) Pump(q *event.Queue) {}
+
+func (ovd *offscreenVideoDevice) getDisplayBounds(display *videoDisplay) (Rect, error) {
+	return Rect{W: display.currentMode.w, H: display.currentMode.h}, nil
+}
+
+func (ovd *offscreenVideoDevice) getDisplayDPI(display *videoDisplay) (float32, float32, float32, error) {
+	return 96, 96, 96, nil
+}
+
+func (ovd *offscreenVideoDevice) getDisplayUsableBounds(display *videoDisplay) (Rect, error) {
+	index := displayIndex(display)
+	if index < 0 {
+		return Rect{}, errors.New("invalid display")
+	}
+	return getDisplayBounds(index)
+}
+
+// getDisplayModes does nothing, the modes come from the hint.
+func (ovd *offscreenVideoDevice) getDisplayModes(display *videoDisplay) {}
+
+func (ovd *offscreenVideoDevice) setDisplayMode(display *videoDisplay, mode *DisplayMode) {
+	display.currentMode = *mode
+}
+
+func offscreenWindowOf(window *Window) *offscreenWindow {
+	ow, _ := window.data[offscreenWindowKey].(*offscreenWindow)
+	return ow
+}
+
+func (ovd *offscreenVideoDevice) createWindow(window *Window) error {
+	if window.flags&WindowOpenGL > 0 {
+		return errors.New("OpenGL is not supported by the offscreen driver")
+	}
+	window.data[offscreenWindowKey] = &offscreenWindow{}
+	return nil
+}
+
+func (ovd *offscreenVideoDevice) createWindowFrom(window *Window, data interface{}) error {
+	return errors.New("foreign windows are not supported by the offscreen driver")
+}
+
+func (ovd *offscreenVideoDevice) setWindowTitle(windows *Window) {}
+
+func (ovd *offscreenVideoDevice) setWindowIcon(window *Window, icon *Surface) {}
+
+func (ovd *offscreenVideoDevice) setWindowPosition(window *Window) {
+	window.SendEvent(event.WindowMoved, window.windowed.X, window.windowed.Y)
+}
+
+func (ovd *offscreenVideoDevice) setWindowSize(window *Window) {
+	resizeWindow(window, window.windowed.W, window.windowed.H)
+}
+
+// resizeWindow reports a new window size, followed by the size change.
+func resizeWindow(window *Window, w, h int) {
+	if w == window.w && h == window.h {
+		return
+	}
+	window.SendEvent(event.WindowResized, w, h)
+	window.SendEvent(event.WindowSizeChanged, w, h)
+}
+
+// placeWindow moves and resizes the window to an area.
+func placeWindow(window *Window, area Rect) {
+	window.SendEvent(event.WindowMoved, area.X, area.Y)
+	resizeWindow(window, area.W, area.H)
+}
+
+func (ovd *offscreenVideoDevice) setWindowMinimumSize(window *Window) {}
+
+func (ovd *offscreenVideoDevice) setWindowMaximumSize(window *Window) {}
+
+func (ovd *offscreenVideoDevice) getWindowBordersSize(window *Window) (int, int, int, int, error) {
+	return 0, 0, 0, 0, nil
+}
+
+func (ovd *offscreenVideoDevice) setWindowOpacity(window *Window) {}
+
+func (ovd *offscreenVideoDevice) setWindowModalFor(window *Window) {}
+
+// setWindowInputFocus moves the input focus to the window, or takes it away
+// from all windows for nil.
+func (ovd *offscreenVideoDevice) setWindowInputFocus(window *Window) {
+	if ovd.focus == window {
+		return
+	}
+	if ovd.focus != nil {
+		ovd.focus.SendEvent(event.WindowFocusLost, 0, 0)
+	}
+	ovd.focus = window
+	if window != nil {
+		window.SendEvent(event.WindowFocusGained, 0, 0)
+	}
+}
+
+func (ovd *offscreenVideoDevice) showWindow(window *Window) {
+	window.SendEvent(event.WindowShown, 0, 0)
+	if window.flags&WindowMinimized == 0 {
+		ovd.setWindowInputFocus(window)
+	}
+}
+
+func (ovd *offscreenVideoDevice) hideWindow(window *Window) {
+	window.SendEvent(event.WindowHidden, 0, 0)
+	if ovd.focus == window {
+		ovd.setWindowInputFocus(nil)
+	}
+}
+
+func (ovd *offscreenVideoDevice) raiseWindow(window *Window) {
+	ovd.setWindowInputFocus(window)
+}
+
+func (ovd *offscreenVideoDevice) maximizeWindow(window *Window) {
+	display := windowDisplay(window)
+	if display == nil {
+		return
+	}
+	bounds, err := ovd.getDisplayUsableBounds(display)
+	if err != nil {
+		return
+	}
+	if ow := offscreenWindowOf(window); ow != nil && window.flags&WindowMaximized == 0 {
+		ow.restored = Rect{X: window.x, Y: window.y, W: window.w, H: window.h}
+	}
+	window.SendEvent(event.WindowMaximized, 0, 0)
+	if window.flags&WindowFullscreen == 0 {
+		placeWindow(window, bounds)
+	}
+}
+
+func (ovd *offscreenVideoDevice) minimizeWindow(window *Window) {
+	window.SendEvent(event.WindowMinimized, 0, 0)
+	if ovd.focus == window {
+		ovd.setWindowInputFocus(nil)
+	}
+}
+
+func (ovd *offscreenVideoDevice) restoreWindow(window *Window) {
+	maximized := window.flags&WindowMaximized > 0
+	window.SendEvent(event.WindowRestored, 0, 0)
+	if ow := offscreenWindowOf(window); ow != nil && maximized && window.flags&WindowFullscreen == 0 {
+		placeWindow(window, ow.restored)
+	}
+	if window.flags&WindowShown > 0 {
+		ovd.setWindowInputFocus(window)
+	}
+}
+
+func (ovd *offscreenVideoDevice) setWindowBordered(window *Window, bordered bool) {}
+
+func (ovd *offscreenVideoDevice) setWindowResizable(window *Window, resizeable bool) {}
+
+// setWindowFullscreen covers the display with the window, changing the
+// display mode to the closest one for WindowFullscreen, and puts the window
+// back and the desktop mode back when it leaves fullscreen.
+func (ovd *offscreenVideoDevice) setWindowFullscreen(window *Window, display *videoDisplay, fullscreen bool) {
+	if !fullscreen {
+		if display.fullscreenWindow == window {
+			display.fullscreenWindow = nil
+			ovd.setDisplayMode(display, &display.desktopMode)
+		}
+		placeWindow(window, window.windowed)
+		return
+	}
+
+	if display.fullscreenWindow != nil && display.fullscreenWindow != window {
+		SetWindowFullscreen(display.fullscreenWindow, 0)
+	}
+	mode := display.desktopMode
+	if window.flags&WindowFullscreenDesktop != WindowFullscreenDesktop {
+		mode = closestDisplayMode(display, window.windowed.W, window.windowed.H)
+	}
+	ovd.setDisplayMode(display, &mode)
+	display.fullscreenWindow = window
+	bounds, err := getDisplayBounds(displayIndex(display))
+	if err != nil {
+		return
+	}
+	placeWindow(window, bounds)
+}
+
+// closestDisplayMode returns the smallest mode of the display that fits the
+// size, or the largest mode if none does.
+func closestDisplayMode(display *videoDisplay, w, h int) DisplayMode {
+	best, largest := -1, 0
+	for i, mode := range display.modes {
+		if mode.w*mode.h > display.modes[largest].w*display.modes[largest].h {
+			largest = i
+		}
+		if mode.w < w || mode.h < h {
+			continue
+		}
+		if best < 0 || mode.w*mode.h < display.modes[best].w*display.modes[best].h {
+			best = i
+		}
+	}
+	if best < 0 {
+		best = largest
+	}
+	return display.modes[best]
+}
+
+func (ovd *offscreenVideoDevice) setWindowGammaRamp(window *Window, ramp []uint16) {
+	if ow := offscreenWindowOf(window); ow != nil {
+		ow.gamma = append(ow.gamma[:0], ramp...)
+	}
+}
+
+// getWindowGammaRamp returns the ramp that was set, or an identity ramp.
+func (ovd *offscreenVideoDevice) getWindowGammaRamp(window *Window, ramp []uint16) {
+	if ow := offscreenWindowOf(window); ow != nil && len(ow.gamma) == len(ramp) {
+		copy(ramp, ow.gamma)
+		return
+	}
+	for i := range ramp {
+		ramp[i] = uint16(i%256) * 257
+	}
+}
+
+func (ovd *offscreenVideoDevice) setWindowGrab(window *Window, grabbed bool) {}
+
+func (ovd *offscreenVideoDevice) destroyWindow(window *Window) {
+	if ovd.focus == window {
+		ovd.focus = nil
+	}
+	for _, display := range ovd.deviceData.displays {
+		if display.fullscreenWindow == window {
+			display.fullscreenWindow = nil
+			ovd.setDisplayMode(display, &display.desktopMode)
+		}
+	}
+	delete(window.data, offscreenWindowKey)
+}
+
+// createWindowFramebuffer allocates the pixels the window surface draws to,
+// along with the screen they are presented to.
+func (ovd *offscreenVideoDevice) createWindowFramebuffer(window *Window) (uint32, []byte, int, error) {
+	ow := offscreenWindowOf(window)
+	if ow == nil {
+		return 0, nil, 0, errors.New("window was not created by the offscreen driver")
+	}
+	ow.pitch = calculatePitch(PixelFormatXRGB8888, window.w)
+	ow.framebuffer = make([]byte, ow.pitch*window.h)
+	ow.screen = make([]byte, ow.pitch*window.h)
+	return PixelFormatXRGB8888, ow.framebuffer, ow.pitch, nil
+}
+
+// updateWindowFramebuffer copies areas of the framebuffer to the screen.
+func (ovd *offscreenVideoDevice) updateWindowFramebuffer(window *Window, rects []Rect) error {
+	ow := offscreenWindowOf(window)
+	if ow == nil || ow.framebuffer == nil {
+		return errors.New("window has no framebuffer")
+	}
+	bounds := Rect{W: window.w, H: window.h}
+	for _, rect := range rects {
+		rect, ok := IntersectRect(rect, bounds)
+		if !ok {
+			continue
+		}
+		for y := rect.Y; y < rect.Y+rect.H; y++ {
+			start, end := y*ow.pitch+rect.X*4, y*ow.pitch+(rect.X+rect.W)*4
+			copy(ow.screen[start:end], ow.framebuffer[start:end])
+		}
+	}
+	return nil
+}
+
+func (ovd *offscreenVideoDevice) destroyWindowFramebuffer(window *Window) {
+	if ow := offscreenWindowOf(window); ow != nil {
+		ow.framebuffer, ow.screen, ow.pitch = nil, nil, 0
+	}
+}
+
+func (ovd *offscreenVideoDevice) onWindowEnter(window *Window) {}
+
+// Offscreen windows have no OpenGL support.
+
+func (ovd *offscreenVideoDevice) glLoadLibrary(path string) {}
+
+func (ovd *offscreenVideoDevice) glGetProcAddress(proc string) {}
+
+func (ovd *offscreenVideoDevice) glUnloadLibrary() {}
+
+func (ovd *offscreenVideoDevice) glCreateContext() {}
+
+func (ovd *offscreenVideoDevice) glMakeCurrent() {}
+
+func (ovd *offscreenVideoDevice) glSetSwapInterval(interval int) {}
+
+func (ovd *offscreenVideoDevice) glGetSwapInterval() {}
+
+func (ovd *offscreenVideoDevice) glSwapWindow(window *Window) {}
+
+func (ovd *offscreenVideoDevice) glDeleteContext() {}
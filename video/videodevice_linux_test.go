@@ -0,0 +1,258 @@
+package video
+
+import (
+	"testing"
+
+	"github.com/elliotmr/gdl/event"
+	"github.com/elliotmr/gdl/hint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// windowEvents returns the window events queued since the last call, as
+// window event id and data.
+func windowEvents(t *testing.T, window *Window) [][3]int {
+	events := make([]event.Event, 64)
+	n, err := event.Q.Peep(events, event.Get, event.WindowStateChange, event.WindowStateChange)
+	require.NoError(t, err)
+	var out [][3]int
+	for _, ev := range events[:n] {
+		we := event.Window(ev.(event.Data))
+		if we.WindowID() == window.id {
+			out = append(out, [3]int{int(we.Event()), int(we.Data1()), int(we.Data2())})
+		}
+	}
+	return out
+}
+
+// useOffscreenDisplays reinitializes video with the offscreen displays from
+// a hint value, restoring the default displays when the test ends.
+func useOffscreenDisplays(t *testing.T, spec string) {
+	hint.SetHint(hint.VideoOffscreenDisplays, spec)
+	require.NoError(t, VideoInit(""))
+	t.Cleanup(func() {
+		hint.ClearHints()
+		require.NoError(t, VideoInit(""))
+	})
+}
+
+func TestVideoInit(t *testing.T) {
+	defer func() {
+		hint.ClearHints()
+		require.NoError(t, VideoInit(""))
+	}()
+	window, err := CreateWindow("init", 0, 0, 10, 10, 0)
+	require.NoError(t, err)
+
+	VideoQuit()
+	assert.Equal(t, "", GetCurrentVideoDriver())
+	assert.Empty(t, this.data().windows)
+	assert.Error(t, checkWindow(window))
+	_, err = CreateWindow("init", 0, 0, 10, 10, 0)
+	assert.Error(t, err)
+
+	hint.SetHint(hint.VideoDriver, "x11")
+	assert.Error(t, VideoInit(""))
+	hint.SetHint(hint.VideoDriver, "OFFSCREEN")
+	hint.SetHint(hint.VideoOffscreenDisplays, "640x480")
+	require.NoError(t, VideoInit(""))
+	assert.Equal(t, "offscreen", GetCurrentVideoDriver())
+	require.Len(t, this.data().displays, 1)
+	assert.Equal(t, 640, this.data().displays[0].desktopMode.w)
+	assert.Error(t, VideoInit("x11"))
+}
+
+func TestParseOffscreenDisplays(t *testing.T) {
+	displays, err := parseOffscreenDisplays("640x480@75, 320x200;1024x768")
+	require.NoError(t, err)
+	require.Len(t, displays, 2)
+	assert.Equal(t, []DisplayMode{
+		{format: PixelFormatXRGB8888, w: 640, h: 480, refreshRate: 75},
+		{format: PixelFormatXRGB8888, w: 320, h: 200},
+	}, displays[0].modes)
+	assert.Equal(t, displays[0].modes[0], displays[0].desktopMode)
+	assert.Equal(t, 1024, displays[1].currentMode.w)
+
+	for _, spec := range []string{"", "640", "640x", "x480", "0x480", "640x480@", "640x480;", "640x480,,320x200"} {
+		_, err := parseOffscreenDisplays(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestOffscreenDisplays(t *testing.T) {
+	useOffscreenDisplays(t, "800x600,640x480;1024x768;320x240")
+	require.Len(t, this.data().displays, 3)
+	var bounds []Rect
+	for i := range this.data().displays {
+		b, err := getDisplayBounds(i)
+		require.NoError(t, err)
+		bounds = append(bounds, b)
+	}
+	assert.Equal(t, []Rect{{W: 800, H: 600}, {X: 800, W: 1024, H: 768}, {X: 1824, W: 320, H: 240}}, bounds)
+	usable, err := this.getDisplayUsableBounds(this.data().displays[1])
+	require.NoError(t, err)
+	assert.Equal(t, bounds[1], usable)
+
+	// a broken hint falls back to the default displays
+	useOffscreenDisplays(t, "big")
+	require.Len(t, this.data().displays, 1)
+	assert.Equal(t, 1920, this.data().displays[0].desktopMode.w)
+}
+
+func TestOffscreenWindow(t *testing.T) {
+	useOffscreenDisplays(t, "800x600")
+	require.NoError(t, event.Q.FlushType(event.WindowStateChange))
+
+	window, err := CreateWindow("offscreen", WindowPosCentered, 10, 200, 100, WindowResizable)
+	require.NoError(t, err)
+	defer DestroyWindow(window)
+	assert.Equal(t, uint32(WindowResizable|WindowShown|WindowInputFocus), GetWindowFlags(window))
+	x, y := GetWindowPosition(window)
+	assert.Equal(t, []int{300, 10}, []int{x, y})
+	assert.Equal(t, [][3]int{{event.WindowShown, 0, 0}, {event.WindowFocusGained, 0, 0}}, windowEvents(t, window))
+
+	require.NoError(t, SetWindowPosition(window, 20, 30))
+	require.NoError(t, SetWindowSize(window, 300, 200))
+	require.NoError(t, SetWindowSize(window, 300, 200))
+	w, h := GetWindowSize(window)
+	assert.Equal(t, []int{300, 200}, []int{w, h})
+	assert.Equal(t, [][3]int{
+		{event.WindowMoved, 20, 30},
+		{event.WindowResized, 300, 200},
+		{event.WindowSizeChanged, 300, 200},
+	}, windowEvents(t, window))
+	assert.Error(t, SetWindowSize(window, 0, 10))
+
+	require.NoError(t, HideWindow(window))
+	require.NoError(t, ShowWindow(window))
+	assert.Equal(t, [][3]int{
+		{event.WindowHidden, 0, 0},
+		{event.WindowFocusLost, 0, 0},
+		{event.WindowShown, 0, 0},
+		{event.WindowFocusGained, 0, 0},
+	}, windowEvents(t, window))
+
+	// maximizing fills the display until the window is restored
+	require.NoError(t, MaximizeWindow(window))
+	assert.Equal(t, uint32(WindowMaximized), GetWindowFlags(window)&WindowMaximized)
+	w, h = GetWindowSize(window)
+	assert.Equal(t, []int{800, 600}, []int{w, h})
+	require.NoError(t, RestoreWindow(window))
+	x, y = GetWindowPosition(window)
+	w, h = GetWindowSize(window)
+	assert.Equal(t, []int{20, 30, 300, 200}, []int{x, y, w, h})
+	assert.Equal(t, [][3]int{
+		{event.WindowMaximized, 0, 0},
+		{event.WindowMoved, 0, 0},
+		{event.WindowResized, 800, 600},
+		{event.WindowSizeChanged, 800, 600},
+		{event.WindowRestored, 0, 0},
+		{event.WindowMoved, 20, 30},
+		{event.WindowResized, 300, 200},
+		{event.WindowSizeChanged, 300, 200},
+	}, windowEvents(t, window))
+
+	require.NoError(t, MinimizeWindow(window))
+	assert.Equal(t, uint32(WindowMinimized), GetWindowFlags(window)&(WindowMinimized|WindowInputFocus))
+	require.NoError(t, RestoreWindow(window))
+	assert.Equal(t, uint32(WindowInputFocus), GetWindowFlags(window)&(WindowMinimized|WindowInputFocus))
+	assert.Equal(t, [][3]int{
+		{event.WindowMinimized, 0, 0},
+		{event.WindowFocusLost, 0, 0},
+		{event.WindowRestored, 0, 0},
+		{event.WindowFocusGained, 0, 0},
+	}, windowEvents(t, window))
+
+	DestroyWindow(window)
+	assert.NotContains(t, this.data().windows, window)
+	assert.Nil(t, offscreenWindowOf(window))
+	assert.Equal(t, [][3]int{{event.WindowHidden, 0, 0}, {event.WindowFocusLost, 0, 0}}, windowEvents(t, window))
+
+	_, err = CreateWindow("gl", 0, 0, 10, 10, WindowOpenGL)
+	assert.Error(t, err)
+}
+
+func TestOffscreenFullscreen(t *testing.T) {
+	useOffscreenDisplays(t, "1024x768,800x600,640x480;1280x720")
+	window, err := CreateWindow("fullscreen", 1100, 100, 700, 500, WindowHidden)
+	require.NoError(t, err)
+	defer DestroyWindow(window)
+	assert.Equal(t, uint32(WindowHidden), GetWindowFlags(window))
+	second := this.data().displays[1]
+
+	// the desktop mode stays and the window covers the display it is on
+	require.NoError(t, SetWindowFullscreen(window, WindowFullscreenDesktop))
+	x, y := GetWindowPosition(window)
+	w, h := GetWindowSize(window)
+	assert.Equal(t, []int{1024, 0, 1280, 720}, []int{x, y, w, h})
+	assert.Equal(t, window, second.fullscreenWindow)
+
+	require.NoError(t, SetWindowFullscreen(window, 0))
+	x, y = GetWindowPosition(window)
+	w, h = GetWindowSize(window)
+	assert.Equal(t, []int{1100, 100, 700, 500}, []int{x, y, w, h})
+	assert.Nil(t, second.fullscreenWindow)
+
+	// real fullscreen picks the smallest mode that fits the window
+	require.NoError(t, SetWindowPosition(window, 10, 10))
+	first := this.data().displays[0]
+	require.NoError(t, SetWindowFullscreen(window, WindowFullscreen))
+	assert.Equal(t, 800, first.currentMode.w)
+	w, h = GetWindowSize(window)
+	assert.Equal(t, []int{800, 600}, []int{w, h})
+
+	// windowed changes wait until fullscreen ends
+	require.NoError(t, SetWindowSize(window, 100, 50))
+	w, h = GetWindowSize(window)
+	assert.Equal(t, []int{800, 600}, []int{w, h})
+	require.NoError(t, SetWindowFullscreen(window, 0))
+	assert.Equal(t, first.desktopMode, first.currentMode)
+	x, y = GetWindowPosition(window)
+	w, h = GetWindowSize(window)
+	assert.Equal(t, []int{10, 10, 100, 50}, []int{x, y, w, h})
+
+	require.NoError(t, SetWindowFullscreen(window, WindowFullscreen))
+	DestroyWindow(window)
+	assert.Equal(t, first.desktopMode, first.currentMode)
+	assert.Nil(t, first.fullscreenWindow)
+}
+
+func TestOffscreenFramebuffer(t *testing.T) {
+	useOffscreenDisplays(t, "640x480")
+	hint.SetHint(hint.RenderVSync, "1")
+	window, err := CreateWindow("framebuffer", 0, 0, 4, 2, 0)
+	require.NoError(t, err)
+	defer DestroyWindow(window)
+	// the software renderer is still used when vsync is asked for
+	r, err := CreateRenderer(window, -1, 0)
+	require.NoError(t, err)
+
+	r.SetDrawColor(0xFF, 0, 0, 0xFF)
+	require.NoError(t, r.Clear())
+	ow := offscreenWindowOf(window)
+	assert.Equal(t, make([]byte, 32), ow.screen)
+	require.NoError(t, r.Present())
+	screen, err := CreateRGBSurfaceWithFormatFrom(ow.screen, 4, 2, 0, ow.pitch, PixelFormatXRGB8888)
+	require.NoError(t, err)
+	assert.Equal(t, [][]uint32{{0xFF0000, 0xFF0000, 0xFF0000, 0xFF0000}, {0xFF0000, 0xFF0000, 0xFF0000, 0xFF0000}},
+		surfacePixels(screen, Rect{W: 4, H: 2}))
+
+	// resizing gives the window a new framebuffer that the renderer follows
+	require.NoError(t, SetWindowSize(window, 3, 3))
+	assert.Equal(t, Rect{W: 3, H: 3}, r.GetViewport())
+	r.SetDrawColor(0, 0xFF, 0, 0xFF)
+	require.NoError(t, r.Clear())
+	require.NoError(t, r.Present())
+	assert.Len(t, ow.screen, 36)
+	assert.Equal(t, []byte{0, 0xFF, 0, 0}, ow.screen[32:])
+
+	// a hidden window isn't drawn to
+	require.NoError(t, MinimizeWindow(window))
+	assert.True(t, r.hidden)
+	require.NoError(t, RestoreWindow(window))
+	assert.False(t, r.hidden)
+
+	DestroyWindow(window)
+	assert.Nil(t, GetRenderer(window))
+	assert.Nil(t, window.surface)
+}
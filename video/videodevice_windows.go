@@ -7,14 +7,12 @@ import (
 	"github.com/elliotmr/gdl/w32/types/ws"
 	"github.com/elliotmr/gdl/event"
 	"github.com/pkg/errors"
-	"fmt"
+	"github.com/elliotmr/gdl/log"
 )
 
 func init() {
 	this = &winVideoDevice{
-		deviceData: &videoDeviceData{
-
-		},
+		deviceData: &videoDeviceData{name: "windows"},
 	}
 	registerApp("", 0)
 }
@@ -71,7 +69,7 @@ func (wvd *winVideoDevice) createWindow(window *Window) error {
 		Style: style | ws.Visible,
 	}
 	handler := &eventHandler{window: window}
-	fmt.Println("creating window")
+	log.Debug(log.CategoryVideo, "creating window")
 	w, err := GDLAppClass.New(handler, props)
 	go func() {
 		err = w.Run()
@@ -102,6 +100,10 @@ func (wvd *winVideoDevice) setWindowPosition(window *Window) {
 	panic("implement me")
 }
 
+func (wvd *winVideoDevice) setWindowSize(window *Window) {
+	panic("implement me")
+}
+
 func (wvd *winVideoDevice) setWindowMinimumSize(window *Window) {
 	panic("implement me")
 }
@@ -126,12 +128,16 @@ func (wvd *winVideoDevice) setWindowInputFocus(window *Window) {
 	panic("implement me")
 }
 
+// showWindow only updates the window state, native windows are created
+// visible.
 func (wvd *winVideoDevice) showWindow(window *Window) {
-	panic("implement me")
+	window.SendEvent(event.WindowShown, 0, 0)
 }
 
+// hideWindow only updates the window state, the native window stays until
+// it is destroyed.
 func (wvd *winVideoDevice) hideWindow(window *Window) {
-	panic("implement me")
+	window.SendEvent(event.WindowHidden, 0, 0)
 }
 
 func (wvd *winVideoDevice) raiseWindow(window *Window) {
@@ -175,14 +181,17 @@ func (wvd *winVideoDevice) setWindowGrab(window *Window, grabbed bool) {
 }
 
 func (wvd *winVideoDevice) destroyWindow(window *Window) {
-	panic("implement me")
+	if w, ok := window.data["native"].(*w32.Window); ok {
+		w.Close()
+		delete(window.data, "native")
+	}
 }
 
-func (wvd *winVideoDevice) createWindowFramebuffer(window *Window) {
+func (wvd *winVideoDevice) createWindowFramebuffer(window *Window) (uint32, []byte, int, error) {
 	panic("implement me")
 }
 
-func (wvd *winVideoDevice) updateWindowFramebuffer(window *Window) {
+func (wvd *winVideoDevice) updateWindowFramebuffer(window *Window, rects []Rect) error {
 	panic("implement me")
 }
 
@@ -234,13 +243,10 @@ func (wvd *winVideoDevice) data() *videoDeviceData {
 	return wvd.deviceData
 }
 
-func (wvd *winVideoDevice) init() {
-	panic("implement me")
-}
+// init does nothing yet, the displays aren't enumerated.
+func (wvd *winVideoDevice) init() {}
 
-func (wvd *winVideoDevice) quit() {
-	panic("implement me")
-}
+func (wvd *winVideoDevice) quit() {}
 
 func (wvd *winVideoDevice) getDisplayBounds(display *videoDisplay) (Rect, error) {
 	panic("implement me")
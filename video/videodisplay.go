@@ -29,16 +29,42 @@ func getDisplayBounds(displayIndex int) (Rect, error) {
 	}
 
 	if displayIndex == 0 {
-		rect.x = 0
-		rect.y = 0
+		rect.X = 0
+		rect.Y = 0
 	} else {
 		r, err := getDisplayBounds(displayIndex-1)
 		if err != nil {
 			return rect, err  // don't wrap recursive call
 		}
-		rect.x += r.w
+		rect.X += r.X + r.W
 	}
-	rect.w = display.currentMode.w
-	rect.h = display.currentMode.h
+	rect.W = display.currentMode.w
+	rect.H = display.currentMode.h
 	return rect, nil
-}
\ No newline at end of file
+}
+// displayIndex returns the index of the display, or -1.
+func displayIndex(display *videoDisplay) int {
+	for i, d := range this.data().displays {
+		if d == display {
+			return i
+		}
+	}
+	return -1
+}
+
+// windowDisplay returns the display holding the center of the window, or
+// the first display. It is nil when there are no displays.
+func windowDisplay(window *Window) *videoDisplay {
+	displays := this.data().displays
+	if len(displays) == 0 {
+		return nil
+	}
+	center := Point{X: window.x + window.w/2, Y: window.y + window.h/2}
+	for i, display := range displays {
+		bounds, err := getDisplayBounds(i)
+		if err == nil && bounds.Contains(center) {
+			return display
+		}
+	}
+	return displays[0]
+}
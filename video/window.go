@@ -4,7 +4,8 @@ import (
 	"github.com/pkg/errors"
 	"sync/atomic"
 	"github.com/elliotmr/gdl/event"
-	"fmt"
+	"github.com/elliotmr/gdl/hint"
+	"github.com/elliotmr/gdl/log"
 )
 
 const (
@@ -30,15 +31,21 @@ const (
 	WindowPopupMenu         // window should be treated as a popup menu
 )
 
+// createFlags are the window flags CreateWindow keeps as they are.
+const createFlags = WindowOpenGL | WindowBorderless | WindowResizable | WindowAllowHighDPI |
+	WindowAlwaysOnTop | WindowSkipTaskbar | WindowUtility | WindowTooltip | WindowPopupMenu
+
+// Window positions can be undefined or centered, on the display in the low
+// 16 bits.
 const WindowPosUndefined = 0x1FFF0000
 const WindowPosCentered = 0x2FFF0000
 
 func WindowPosIsUndefined(x int) bool {
-	return x | WindowPosUndefined == WindowPosUndefined
+	return x&^0xFFFF == WindowPosUndefined
 }
 
 func WindowPosIsCentered(x int) bool {
-	return x | WindowPosCentered == WindowPosCentered
+	return x&^0xFFFF == WindowPosCentered
 }
 
 type DisplayMode struct {
@@ -98,6 +105,9 @@ type Window struct {
 }
 
 func CreateWindow(title string, x, y, w, h int, flags uint32) (*Window, error) {
+	if this == nil || !this.data().initialized {
+		return nil, errors.New("video subsystem has not been initialized")
+	}
 	if w < 1 {
 		w = 1
 	}
@@ -110,38 +120,277 @@ func CreateWindow(title string, x, y, w, h int, flags uint32) (*Window, error) {
 	}
 
 	// TODO(mde): check for OpengGL support with flag WindowOpenGL
-	// TODO(mde): disable high DPI if hint high spi disabled is set
+	if hint.GetHintBoolean(hint.VideoHighDPIDisabled, false) {
+		flags &^= WindowAllowHighDPI
+	}
+	x, y = resolveWindowPosition(x, y, w, h)
 	window := &Window{
 		magic: this.data().windowMagic,
 		x:     x,
 		y:     y,
 		w:     w,
 		h:     h,
+		flags: flags&createFlags | WindowHidden,
 		data:  make(map[string]interface{}),
 	}
 
 	window.id = atomic.AddUint32(&(this.data().nextObjectID), 1)
 
-	window.windowed.x = window.x
-	window.windowed.y = window.y
-	window.windowed.w = window.w
-	window.windowed.h = window.h
-
-	// TODO(mde): Lots more
-
-	if flags & WindowFullscreen > 0 {
-
-	}
+	window.windowed.X = window.x
+	window.windowed.Y = window.y
+	window.windowed.W = window.w
+	window.windowed.H = window.h
 
 	this.data().windows = append(this.data().windows, window)
 	err := this.createWindow(window)
 	if err != nil {
-		// TODO: Destroy Window
+		removeWindow(window)
 		return nil, errors.Wrap(err, "could not create window")
 	}
 
+	// the window is created hidden and then brought into the requested state
+	if flags&WindowMinimized > 0 {
+		this.minimizeWindow(window)
+	}
+	if flags&WindowMaximized > 0 {
+		this.maximizeWindow(window)
+	}
+	if flags&WindowFullscreen > 0 {
+		if err := SetWindowFullscreen(window, flags); err != nil {
+			DestroyWindow(window)
+			return nil, err
+		}
+	}
+	if flags&WindowHidden == 0 {
+		this.showWindow(window)
+	}
 	return window, nil
+}
+
+// resolveWindowPosition places undefined and centered positions on their
+// display.
+func resolveWindowPosition(x, y, w, h int) (int, int) {
+	special := func(v int) bool { return WindowPosIsUndefined(v) || WindowPosIsCentered(v) }
+	if !special(x) && !special(y) {
+		return x, y
+	}
+	index := y & 0xFFFF
+	if special(x) {
+		index = x & 0xFFFF
+	}
+	if index >= len(this.data().displays) {
+		index = 0
+	}
+	bounds, err := getDisplayBounds(index)
+	if err != nil {
+		bounds = Rect{}
+	}
+	switch {
+	case WindowPosIsCentered(x):
+		x = bounds.X + (bounds.W-w)/2
+	case WindowPosIsUndefined(x):
+		x = bounds.X
+	}
+	switch {
+	case WindowPosIsCentered(y):
+		y = bounds.Y + (bounds.H-h)/2
+	case WindowPosIsUndefined(y):
+		y = bounds.Y
+	}
+	return x, y
+}
+
+// removeWindow takes the window out of the window list.
+func removeWindow(window *Window) {
+	windows := this.data().windows
+	for i, w := range windows {
+		if w == window {
+			this.data().windows = append(windows[:i], windows[i+1:]...)
+			return
+		}
+	}
+}
+
+// DestroyWindow hides the window and releases it along with its renderer and
+// surface.
+func DestroyWindow(window *Window) {
+	if checkWindow(window) != nil || window.isDestroying {
+		return
+	}
+	window.isDestroying = true
+	HideWindow(window)
+	if r := GetRenderer(window); r != nil {
+		DestroyRenderer(r)
+	}
+	if window.surface != nil {
+		window.surface.flags &^= SurfaceDontFree
+		FreeSurface(window.surface)
+		window.surface = nil
+		window.surfaceValid = false
+		this.destroyWindowFramebuffer(window)
+	}
+	this.destroyWindow(window)
+	removeWindow(window)
+}
+
+func checkWindow(window *Window) error {
+	if window == nil {
+		return errors.New("invalid window")
+	}
+	if this == nil || !this.data().initialized {
+		return errors.New("video subsystem has not been initialized")
+	}
+	return nil
+}
+
+func GetWindowID(window *Window) uint32 {
+	if window == nil {
+		return 0
+	}
+	return window.id
+}
+
+func GetWindowFlags(window *Window) uint32 {
+	if window == nil {
+		return 0
+	}
+	return window.flags
+}
+
+// ShowWindow makes the window visible.
+func ShowWindow(window *Window) error {
+	if err := checkWindow(window); err != nil {
+		return err
+	}
+	if window.flags&WindowShown == 0 {
+		this.showWindow(window)
+	}
+	return nil
+}
+
+// HideWindow makes the window invisible.
+func HideWindow(window *Window) error {
+	if err := checkWindow(window); err != nil {
+		return err
+	}
+	if window.flags&WindowShown > 0 {
+		window.isHiding = true
+		this.hideWindow(window)
+		window.isHiding = false
+	}
+	return nil
+}
+
+// RaiseWindow puts the window above the others and gives it input focus.
+func RaiseWindow(window *Window) error {
+	if err := checkWindow(window); err != nil {
+		return err
+	}
+	if window.flags&WindowShown > 0 {
+		this.raiseWindow(window)
+	}
+	return nil
+}
+
+// SetWindowPosition moves the window, the position may be undefined or
+// centered. A fullscreen window moves when it leaves fullscreen.
+func SetWindowPosition(window *Window, x, y int) error {
+	if err := checkWindow(window); err != nil {
+		return err
+	}
+	window.windowed.X, window.windowed.Y = resolveWindowPosition(x, y, window.windowed.W, window.windowed.H)
+	if window.flags&WindowFullscreen == 0 {
+		this.setWindowPosition(window)
+	}
+	return nil
+}
+
+func GetWindowPosition(window *Window) (x, y int) {
+	if window == nil {
+		return 0, 0
+	}
+	return window.x, window.y
+}
+
+// SetWindowSize resizes the window within its minimum and maximum size. A
+// fullscreen window is resized when it leaves fullscreen.
+func SetWindowSize(window *Window, w, h int) error {
+	if err := checkWindow(window); err != nil {
+		return err
+	}
+	if w <= 0 || h <= 0 {
+		return errors.Errorf("invalid window size %dx%d", w, h)
+	}
+	if window.maxW > 0 && window.maxH > 0 {
+		w, h = min(w, window.maxW), min(h, window.maxH)
+	}
+	w, h = max(w, window.minW), max(h, window.minH)
+	window.windowed.W, window.windowed.H = w, h
+	if window.flags&WindowFullscreen == 0 {
+		this.setWindowSize(window)
+	}
+	return nil
+}
+
+func GetWindowSize(window *Window) (w, h int) {
+	if window == nil {
+		return 0, 0
+	}
+	return window.w, window.h
+}
+
+// SetWindowFullscreen switches the window to fullscreen with
+// WindowFullscreen, to a fullscreen window at the desktop resolution with
+// WindowFullscreenDesktop, or back to windowed mode with 0.
+func SetWindowFullscreen(window *Window, flags uint32) error {
+	if err := checkWindow(window); err != nil {
+		return err
+	}
+	flags &= WindowFullscreenDesktop
+	if window.flags&WindowFullscreenDesktop == flags {
+		return nil
+	}
+	display := windowDisplay(window)
+	if display == nil {
+		return errors.New("window is not on a display")
+	}
+	window.flags = window.flags&^WindowFullscreenDesktop | flags
+	this.setWindowFullscreen(window, display, flags != 0)
+	return nil
+}
+
+// MinimizeWindow turns the window into an icon.
+func MinimizeWindow(window *Window) error {
+	if err := checkWindow(window); err != nil {
+		return err
+	}
+	if window.flags&WindowMinimized == 0 {
+		this.minimizeWindow(window)
+	}
+	return nil
+}
 
+// MaximizeWindow makes the window as large as its display allows.
+func MaximizeWindow(window *Window) error {
+	if err := checkWindow(window); err != nil {
+		return err
+	}
+	if window.flags&WindowMaximized == 0 {
+		this.maximizeWindow(window)
+	}
+	return nil
+}
+
+// RestoreWindow returns a minimized or maximized window to its size and
+// position.
+func RestoreWindow(window *Window) error {
+	if err := checkWindow(window); err != nil {
+		return err
+	}
+	if window.flags&(WindowMinimized|WindowMaximized) > 0 {
+		this.restoreWindow(window)
+	}
+	return nil
 }
 
 func (w *Window) SendEvent(windowevent uint8, data1, data2 int) {
@@ -149,9 +398,9 @@ func (w *Window) SendEvent(windowevent uint8, data1, data2 int) {
 		return
 	}
 	// TODO: add callbacks? see SDL_windowevents.c
+	log.Debug(log.CategoryVideo, "window %d: event %d (%d, %d)", w.id, windowevent, data1, data2)
 	switch windowevent {
 	case event.WindowShown:
-		fmt.Println("event.WindowShown")
 		if w.flags & WindowShown > 0 {
 			return
 		}
@@ -159,17 +408,15 @@ func (w *Window) SendEvent(windowevent uint8, data1, data2 int) {
 		w.flags |= WindowShown
 
 	case event.WindowHidden:
-		fmt.Println("event.WindowHidden")
 		if w.flags & WindowShown == 0 {
 			return
 		}
 		w.flags |= WindowHidden
 		w.flags &^= WindowShown
 	case event.WindowMoved:
-		fmt.Println("event.WindowMoved")
 		if w.flags & WindowFullscreen == 0 {
-			w.windowed.x = data1
-			w.windowed.y = data2
+			w.windowed.X = data1
+			w.windowed.Y = data2
 		}
 		if data1 == w.x && data2 == w.y {
 			return
@@ -177,56 +424,49 @@ func (w *Window) SendEvent(windowevent uint8, data1, data2 int) {
 		w.x = data1
 		w.y = data2
 	case event.WindowResized:
-		fmt.Println("event.WindowResized")
 		if w.flags & WindowFullscreen == 0 {
-			w.windowed.w = data1
-			w.windowed.h = data2
+			w.windowed.W = data1
+			w.windowed.H = data2
 		}
 		if data1 == w.w && data2 == w.h {
 			return
 		}
 		w.w = data1
 		w.h = data2
+		w.surfaceValid = false
 	case event.WindowMinimized:
-		fmt.Println("event.WindowMinimized")
 		if w.flags & WindowMinimized > 0 {
 			return
 		}
 		w.flags &^= WindowMaximized
 		w.flags |= WindowMinimized
 	case event.WindowMaximized:
-		fmt.Println("event.WindowMaximized")
 		if w.flags & WindowMaximized > 0 {
 			return
 		}
 		w.flags &^= WindowMinimized
 		w.flags |= WindowMaximized
 	case event.WindowRestored:
-		fmt.Println("event.WindowRestored")
 		if w.flags & (WindowMinimized | WindowMaximized) == 0 {
 			return
 		}
 		w.flags &^= WindowMinimized | WindowMaximized
 	case event.WindowEnter:
-		fmt.Println("event.WindowEnter")
 		if w.flags & WindowMouseFocus > 0 {
 			return
 		}
 		w.flags |= WindowMouseFocus
 	case event.WindowLeave:
-		fmt.Println("event.WindowLeave")
 		if w.flags & WindowMouseFocus == 0 {
 			return
 		}
 		w.flags &^= WindowMouseFocus
 	case event.WindowFocusGained:
-		fmt.Println("event.WindowFocusGained")
 		if w.flags & WindowInputFocus > 0 {
 			return
 		}
 		w.flags |= WindowInputFocus
 	case event.WindowFocusLost:
-		fmt.Println("event.WindowFocusLost")
 		if w.flags & WindowInputFocus == 0 {
 			return
 		}
@@ -237,4 +477,55 @@ func (w *Window) SendEvent(windowevent uint8, data1, data2 int) {
 		// TODO: filter pending resize, size changed, moved, and exposed events.
 		event.Q.Push(event.NewWindowEvent(w.id, windowevent, data1, data2))
 	}
-}
\ No newline at end of file
+}
+
+// GetWindowSurface returns a surface for drawing to the window, it is
+// created on first use and again after the window is resized. The surface
+// is owned by the window and must not be freed.
+func GetWindowSurface(window *Window) (*Surface, error) {
+	if window == nil {
+		return nil, errors.New("invalid window")
+	}
+	if window.surfaceValid {
+		return window.surface, nil
+	}
+	if this == nil {
+		return nil, errors.New("video subsystem has not been initialized")
+	}
+	if window.surface != nil {
+		window.surface.flags &^= SurfaceDontFree
+		FreeSurface(window.surface)
+		window.surface = nil
+	}
+	format, pixels, pitch, err := this.createWindowFramebuffer(window)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create window framebuffer")
+	}
+	s, err := CreateRGBSurfaceWithFormatFrom(pixels, window.w, window.h, 0, pitch, format)
+	if err != nil {
+		return nil, err
+	}
+	s.flags |= SurfaceDontFree
+	window.surface = s
+	window.surfaceValid = true
+	return s, nil
+}
+
+// UpdateWindowSurface copies the window surface to the screen.
+func UpdateWindowSurface(window *Window) error {
+	if window == nil {
+		return errors.New("invalid window")
+	}
+	return UpdateWindowSurfaceRects(window, []Rect{{W: window.w, H: window.h}})
+}
+
+// UpdateWindowSurfaceRects copies areas of the window surface to the screen.
+func UpdateWindowSurfaceRects(window *Window, rects []Rect) error {
+	if window == nil {
+		return errors.New("invalid window")
+	}
+	if !window.surfaceValid {
+		return errors.New("window surface is invalid, call GetWindowSurface to get a new surface")
+	}
+	return this.updateWindowFramebuffer(window, rects)
+}
@@ -0,0 +1,334 @@
+package video
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// YUV conversion modes
+const (
+	YUVConversionJPEG      = iota // Full range JPEG
+	YUVConversionBT601            // BT.601 (the default)
+	YUVConversionBT709            // BT.709
+	YUVConversionAutomatic        // BT.601 for SD content, BT.709 for HD content
+)
+
+// yuvSDThreshold is the highest resolution that automatic conversion treats
+// as SD content.
+const yuvSDThreshold = 576
+
+var yuvConversionMode = YUVConversionBT601
+
+// SetYUVConversionMode sets the YUV conversion mode used by ConvertPixels
+// and YUV textures.
+func SetYUVConversionMode(mode int) {
+	yuvConversionMode = mode
+}
+
+func GetYUVConversionMode() int {
+	return yuvConversionMode
+}
+
+// GetYUVConversionModeForResolution returns the conversion mode used for an
+// image of the given size, which resolves YUVConversionAutomatic.
+func GetYUVConversionModeForResolution(width, height int) int {
+	mode := GetYUVConversionMode()
+	if mode == YUVConversionAutomatic {
+		if height <= yuvSDThreshold {
+			return YUVConversionBT601
+		}
+		return YUVConversionBT709
+	}
+	return mode
+}
+
+// yuvMatrix holds the 16.16 fixed point coefficients of a YUV to RGB
+// conversion.
+type yuvMatrix struct {
+	yOffset           int32
+	y, rv, gu, gv, bu int32
+}
+
+// newYUVMatrix derives the conversion from the red and blue luma weights,
+// limited range YUV has luma in 16-235 and chroma in 16-240.
+func newYUVMatrix(kr, kb float64, limited bool) yuvMatrix {
+	kg := 1 - kr - kb
+	ys, cs, offset := 1.0, 1.0, int32(0)
+	if limited {
+		ys, cs, offset = 255.0/219, 255.0/224, 16
+	}
+	fixed := func(f float64) int32 { return int32(math.Round(f * 65536)) }
+	return yuvMatrix{
+		yOffset: offset,
+		y:       fixed(ys),
+		rv:      fixed(cs * 2 * (1 - kr)),
+		gu:      fixed(cs * 2 * kb * (1 - kb) / kg),
+		gv:      fixed(cs * 2 * kr * (1 - kr) / kg),
+		bu:      fixed(cs * 2 * (1 - kb)),
+	}
+}
+
+var yuvMatrices = map[int]yuvMatrix{
+	YUVConversionJPEG:  newYUVMatrix(0.299, 0.114, false),
+	YUVConversionBT601: newYUVMatrix(0.299, 0.114, true),
+	YUVConversionBT709: newYUVMatrix(0.2126, 0.0722, true),
+}
+
+func clampFixed(v int32) uint32 {
+	v >>= 16
+	if v < 0 {
+		return 0
+	}
+	if v > 0xFF {
+		return 0xFF
+	}
+	return uint32(v)
+}
+
+// rgb converts a YUV sample to RGB.
+func (m *yuvMatrix) rgb(y, u, v uint8) (r, g, b uint32) {
+	yy := (int32(y)-m.yOffset)*m.y + 1<<15
+	cu, cv := int32(u)-128, int32(v)-128
+	return clampFixed(yy + m.rv*cv), clampFixed(yy - m.gu*cu - m.gv*cv), clampFixed(yy + m.bu*cu)
+}
+
+func isYUVFormat(format uint32) bool {
+	switch format {
+	case PixelFormatYV12, PixelFormatIYUV, PixelFormatYUY2, PixelFormatUYVY, PixelFormatYVYU,
+		PixelFormatNV12, PixelFormatNV21:
+		return true
+	}
+	return false
+}
+
+func isPlanarYUVFormat(format uint32) bool {
+	switch format {
+	case PixelFormatYV12, PixelFormatIYUV, PixelFormatNV12, PixelFormatNV21:
+		return true
+	}
+	return false
+}
+
+// yuvPlane is one plane of a YUV image in memory order, each group of
+// sampleSize bytes covers xDiv by yDiv pixels.
+type yuvPlane struct {
+	pixels     []byte
+	pitch      int
+	xDiv, yDiv int
+	sampleSize int
+}
+
+func (p yuvPlane) rowSize(w int) int {
+	return (w + p.xDiv - 1) / p.xDiv * p.sampleSize
+}
+
+func (p yuvPlane) rows(h int) int {
+	return (h + p.yDiv - 1) / p.yDiv
+}
+
+// offset returns the position of the samples of pixel x, y.
+func (p yuvPlane) offset(x, y int) int {
+	return y/p.yDiv*p.pitch + x/p.xDiv*p.sampleSize
+}
+
+// copyFrom copies a w by h pixel area of src into the plane at x, y.
+func (p yuvPlane) copyFrom(x, y, w, h int, src yuvPlane) {
+	dst := p.pixels[p.offset(x, y):]
+	n := p.rowSize(w)
+	for row := 0; row < p.rows(h); row++ {
+		copy(dst[row*p.pitch:row*p.pitch+n], src.pixels[row*src.pitch:])
+	}
+}
+
+// yuvPlanes splits the pixels of a w by h YUV image into its planes. The
+// chroma planes of planar formats have half the pitch of the luma plane.
+func yuvPlanes(format uint32, w, h int, pixels []byte, pitch int) ([]yuvPlane, error) {
+	var planes []yuvPlane
+	switch format {
+	case PixelFormatYV12, PixelFormatIYUV:
+		planes = []yuvPlane{
+			{pitch: pitch, xDiv: 1, yDiv: 1, sampleSize: 1},
+			{pitch: (pitch + 1) / 2, xDiv: 2, yDiv: 2, sampleSize: 1},
+			{pitch: (pitch + 1) / 2, xDiv: 2, yDiv: 2, sampleSize: 1},
+		}
+	case PixelFormatNV12, PixelFormatNV21:
+		planes = []yuvPlane{
+			{pitch: pitch, xDiv: 1, yDiv: 1, sampleSize: 1},
+			{pitch: (pitch + 1) / 2 * 2, xDiv: 2, yDiv: 2, sampleSize: 2},
+		}
+	case PixelFormatYUY2, PixelFormatUYVY, PixelFormatYVYU:
+		planes = []yuvPlane{{pitch: pitch, xDiv: 2, yDiv: 1, sampleSize: 4}}
+	default:
+		return nil, errors.Errorf("%s is not a YUV format", GetPixelFormatName(format))
+	}
+	offset := 0
+	for i := range planes {
+		p := &planes[i]
+		if p.pitch < p.rowSize(w) {
+			return nil, errors.Errorf("pitch %d is too small for width %d", pitch, w)
+		}
+		size := p.pitch*(p.rows(h)-1) + p.rowSize(w)
+		if offset+size > len(pixels) {
+			return nil, errors.Errorf("pixel buffer of %d bytes is too small for %dx%d", len(pixels), w, h)
+		}
+		p.pixels = pixels[offset:]
+		offset += p.pitch * p.rows(h)
+	}
+	return planes, nil
+}
+
+// yuvSize returns the pitch and size of a tightly packed YUV image.
+func yuvSize(format uint32, w, h int) (pitch, size int) {
+	pitch = w
+	switch format {
+	case PixelFormatYUY2, PixelFormatUYVY, PixelFormatYVYU:
+		pitch = (w + 1) / 2 * 4
+		return pitch, pitch * h
+	case PixelFormatYV12, PixelFormatIYUV, PixelFormatNV12, PixelFormatNV21:
+		return pitch, pitch*h + 2*((w+1)/2)*((h+1)/2)
+	}
+	return 0, 0
+}
+
+// yuvImage gives access to the samples of a YUV image. Chroma samples are
+// shared by two pixels across, and by two rows for the 4:2:0 formats.
+type yuvImage struct {
+	w, h            int
+	y, u, v         []byte
+	yPitch, uvPitch int
+	yStep, uvStep   int
+	uvRowShift      uint
+}
+
+func newYUVImage(format uint32, w, h int, pixels []byte, pitch int) (*yuvImage, error) {
+	planes, err := yuvPlanes(format, w, h, pixels, pitch)
+	if err != nil {
+		return nil, err
+	}
+	img := &yuvImage{w: w, h: h, y: planes[0].pixels, yPitch: pitch, yStep: 1}
+	switch format {
+	case PixelFormatYV12:
+		img.v, img.u = planes[1].pixels, planes[2].pixels
+		img.uvPitch, img.uvStep, img.uvRowShift = planes[1].pitch, 1, 1
+	case PixelFormatIYUV:
+		img.u, img.v = planes[1].pixels, planes[2].pixels
+		img.uvPitch, img.uvStep, img.uvRowShift = planes[1].pitch, 1, 1
+	case PixelFormatNV12:
+		img.u, img.v = planes[1].pixels, planes[1].pixels[1:]
+		img.uvPitch, img.uvStep, img.uvRowShift = planes[1].pitch, 2, 1
+	case PixelFormatNV21:
+		img.v, img.u = planes[1].pixels, planes[1].pixels[1:]
+		img.uvPitch, img.uvStep, img.uvRowShift = planes[1].pitch, 2, 1
+	case PixelFormatYUY2:
+		img.u, img.v = pixels[1:], pixels[3:]
+	case PixelFormatUYVY:
+		img.y, img.u, img.v = pixels[1:], pixels, pixels[2:]
+	case PixelFormatYVYU:
+		img.v, img.u = pixels[1:], pixels[3:]
+	}
+	if planes[0].sampleSize == 4 {
+		img.yStep, img.uvPitch, img.uvStep = 2, pitch, 4
+	}
+	return img, nil
+}
+
+// at returns the samples of pixel x, y.
+func (img *yuvImage) at(x, y int) (yy, u, v uint8) {
+	c := y>>img.uvRowShift*img.uvPitch + x>>1*img.uvStep
+	return img.y[y*img.yPitch+x*img.yStep], img.u[c], img.v[c]
+}
+
+// subRows returns the image of rows y0 up to y1, y0 has to be the first row
+// of a chroma row.
+func (img *yuvImage) subRows(y0, y1 int) *yuvImage {
+	sub := *img
+	c := y0 >> img.uvRowShift * img.uvPitch
+	sub.y = img.y[y0*img.yPitch:]
+	sub.u, sub.v = img.u[c:], img.v[c:]
+	sub.h = y1 - y0
+	return &sub
+}
+
+// yuvToRGB converts a YUV image into an RGB surface of the same size.
+func yuvToRGB(img *yuvImage, m *yuvMatrix, dst *Surface) {
+	if is8888(dst.format) {
+		yuvToRGB8888(img, m, dst)
+		return
+	}
+	yuvToRGBGeneric(img, m, dst)
+}
+
+// yuvToRGBGeneric converts one pixel at a time to any RGB format.
+func yuvToRGBGeneric(img *yuvImage, m *yuvMatrix, dst *Surface) {
+	for y := 0; y < img.h; y++ {
+		row := dst.pixels[y*dst.pitch:]
+		for x := 0; x < img.w; x++ {
+			r, g, b := m.rgb(img.at(x, y))
+			writePixel(row, x, dst.format, MapRGB(dst.format, uint8(r), uint8(g), uint8(b)))
+		}
+	}
+}
+
+// yuvToRGB8888 converts a row at a time to an 8888 format, computing the
+// chroma terms once for each pair of pixels that shares them.
+func yuvToRGB8888(img *yuvImage, m *yuvMatrix, dst *Surface) {
+	dc := newChannels8888(dst.format)
+	for y := 0; y < img.h; y++ {
+		out := dst.pixels[y*dst.pitch:]
+		luma := img.y[y*img.yPitch:]
+		c := y >> img.uvRowShift * img.uvPitch
+		us, vs := img.u[c:], img.v[c:]
+		for x := 0; x < img.w; x += 2 {
+			cu, cv := int32(us[x>>1*img.uvStep])-128, int32(vs[x>>1*img.uvStep])-128
+			rc, gc, bc := m.rv*cv, -m.gu*cu-m.gv*cv, m.bu*cu
+			for i := x; i < x+2 && i < img.w; i++ {
+				yy := (int32(luma[i*img.yStep])-m.yOffset)*m.y + 1<<15
+				store32(out[i*4:], dc.pack(clampFixed(yy+rc), clampFixed(yy+gc), clampFixed(yy+bc), 0xFF))
+			}
+		}
+	}
+}
+
+// convertYUV is ConvertPixels for YUV images, which can be converted to RGB
+// or copied to the same YUV format.
+func convertYUV(width, height int, srcFormat uint32, src []byte, srcPitch int, dstFormat uint32, dst []byte, dstPitch int) error {
+	if srcFormat == dstFormat {
+		srcPlanes, err := yuvPlanes(srcFormat, width, height, src, srcPitch)
+		if err != nil {
+			return errors.Wrap(err, "invalid source")
+		}
+		dstPlanes, err := yuvPlanes(dstFormat, width, height, dst, dstPitch)
+		if err != nil {
+			return errors.Wrap(err, "invalid destination")
+		}
+		for i, p := range dstPlanes {
+			p.copyFrom(0, 0, width, height, srcPlanes[i])
+		}
+		return nil
+	}
+	if IsPixelFormatFourCC(dstFormat) {
+		return errors.Errorf("conversion to %s is not supported", GetPixelFormatName(dstFormat))
+	}
+	img, err := newYUVImage(srcFormat, width, height, src, srcPitch)
+	if err != nil {
+		return errors.Wrap(err, "invalid source")
+	}
+	d, err := wrapPixels(width, height, dstFormat, dst, dstPitch)
+	if err != nil {
+		return errors.Wrap(err, "invalid destination")
+	}
+	defer FreeSurface(d)
+	m := yuvMatrixFor(width, height)
+	yuvToRGB(img, &m, d)
+	return nil
+}
+
+// yuvMatrixFor returns the conversion matrix for an image size.
+func yuvMatrixFor(width, height int) yuvMatrix {
+	m, ok := yuvMatrices[GetYUVConversionModeForResolution(width, height)]
+	if !ok {
+		m = yuvMatrices[YUVConversionBT601]
+	}
+	return m
+}
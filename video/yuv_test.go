@@ -0,0 +1,371 @@
+package video
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var yuvFormats = []uint32{
+	PixelFormatYV12, PixelFormatIYUV, PixelFormatNV12, PixelFormatNV21,
+	PixelFormatYUY2, PixelFormatUYVY, PixelFormatYVYU,
+}
+
+// yuvFrame is a test frame with a Y sample for each pixel, and U and V
+// samples for each chroma block.
+type yuvFrame struct {
+	w, h    int
+	y, u, v [][]uint8
+}
+
+func newYUVFrame(w, h int, seed int64) *yuvFrame {
+	rnd := rand.New(rand.NewSource(seed))
+	plane := func(w, h int) [][]uint8 {
+		rows := make([][]uint8, h)
+		for i := range rows {
+			rows[i] = make([]uint8, w)
+			rnd.Read(rows[i])
+		}
+		return rows
+	}
+	return &yuvFrame{w: w, h: h, y: plane(w, h), u: plane((w+1)/2, h), v: plane((w+1)/2, h)}
+}
+
+// samples returns the samples of pixel x, y for a format.
+func (f *yuvFrame) samples(format uint32, x, y int) (uint8, uint8, uint8) {
+	cy := y
+	if format != PixelFormatYUY2 && format != PixelFormatUYVY && format != PixelFormatYVYU {
+		cy = y / 2
+	}
+	return f.y[y][x], f.u[cy][x/2], f.v[cy][x/2]
+}
+
+// pack lays the frame out in a YUV format, writing each layout by hand.
+func (f *yuvFrame) pack(format uint32) ([]byte, int) {
+	cw, ch := (f.w+1)/2, (f.h+1)/2
+	var buf []byte
+	lumaPlane := func() {
+		for _, row := range f.y {
+			buf = append(buf, row...)
+		}
+	}
+	chromaPlane := func(c [][]uint8) {
+		for y := 0; y < ch; y++ {
+			buf = append(buf, c[y]...)
+		}
+	}
+	switch format {
+	case PixelFormatYV12:
+		lumaPlane()
+		chromaPlane(f.v)
+		chromaPlane(f.u)
+		return buf, f.w
+	case PixelFormatIYUV:
+		lumaPlane()
+		chromaPlane(f.u)
+		chromaPlane(f.v)
+		return buf, f.w
+	case PixelFormatNV12, PixelFormatNV21:
+		lumaPlane()
+		for y := 0; y < ch; y++ {
+			for x := 0; x < cw; x++ {
+				if format == PixelFormatNV12 {
+					buf = append(buf, f.u[y][x], f.v[y][x])
+				} else {
+					buf = append(buf, f.v[y][x], f.u[y][x])
+				}
+			}
+		}
+		return buf, f.w
+	}
+	for y := 0; y < f.h; y++ {
+		for x := 0; x < cw; x++ {
+			y0, y1, u, v := f.y[y][2*x], uint8(0), f.u[y][x], f.v[y][x]
+			if 2*x+1 < f.w {
+				y1 = f.y[y][2*x+1]
+			}
+			switch format {
+			case PixelFormatYUY2:
+				buf = append(buf, y0, u, y1, v)
+			case PixelFormatUYVY:
+				buf = append(buf, u, y0, v, y1)
+			case PixelFormatYVYU:
+				buf = append(buf, y0, v, y1, u)
+			}
+		}
+	}
+	return buf, cw * 4
+}
+
+// referenceRGB converts with the published floating point coefficients.
+func referenceRGB(mode int, y, u, v uint8) [3]uint8 {
+	yf, uf, vf := float64(y), float64(u)-128, float64(v)-128
+	var r, g, b float64
+	switch mode {
+	case YUVConversionJPEG:
+		r = yf + 1.402*vf
+		g = yf - 0.344136*uf - 0.714136*vf
+		b = yf + 1.772*uf
+	case YUVConversionBT601:
+		yf = 1.164383 * (yf - 16)
+		r = yf + 1.596027*vf
+		g = yf - 0.391762*uf - 0.812968*vf
+		b = yf + 2.017232*uf
+	case YUVConversionBT709:
+		yf = 1.164383 * (yf - 16)
+		r = yf + 1.792741*vf
+		g = yf - 0.213249*uf - 0.532909*vf
+		b = yf + 2.112402*uf
+	}
+	clamp := func(c float64) uint8 { return uint8(math.Max(0, math.Min(255, math.Round(c)))) }
+	return [3]uint8{clamp(r), clamp(g), clamp(b)}
+}
+
+// requireNearReference checks every pixel of an RGB surface against the
+// reference conversion of the frame.
+func requireNearReference(t *testing.T, f *yuvFrame, format uint32, mode int, s *Surface, name string) {
+	for y := 0; y < f.h; y++ {
+		for x := 0; x < f.w; x++ {
+			yy, u, v := f.samples(format, x, y)
+			want := referenceRGB(mode, yy, u, v)
+			r, g, b := GetRGB(s.getPixel(x, y), s.format)
+			for i, c := range []uint8{r, g, b} {
+				diff := int(c) - int(want[i])
+				require.True(t, diff >= -1 && diff <= 1,
+					"%s %s mode %d pixel (%d, %d): got %v want %v", name,
+					GetPixelFormatName(format), mode, x, y, []uint8{r, g, b}, want)
+			}
+		}
+	}
+}
+
+func TestYUVToRGBReference(t *testing.T) {
+	defer SetYUVConversionMode(YUVConversionBT601)
+	const w, h = 7, 5
+	for i, format := range yuvFormats {
+		f := newYUVFrame(w, h, int64(i))
+		src, pitch := f.pack(format)
+		for _, mode := range []int{YUVConversionJPEG, YUVConversionBT601, YUVConversionBT709} {
+			SetYUVConversionMode(mode)
+
+			// ConvertPixels takes the row path for 8888 and the generic one otherwise
+			for _, dstFormat := range []uint32{PixelFormatARGB8888, PixelFormatBGR24} {
+				dst, err := CreateRGBSurfaceWithFormat(0, w, h, 0, dstFormat)
+				require.NoError(t, err)
+				require.NoError(t, ConvertPixels(w, h, format, src, pitch, dstFormat, dst.pixels, dst.pitch))
+				requireNearReference(t, f, format, mode, dst, GetPixelFormatName(dstFormat))
+			}
+
+			// both paths agree exactly
+			img, err := newYUVImage(format, w, h, src, pitch)
+			require.NoError(t, err)
+			m := yuvMatrices[mode]
+			row, err := CreateRGBSurfaceWithFormat(0, w, h, 0, PixelFormatXBGR8888)
+			require.NoError(t, err)
+			generic, err := CreateRGBSurfaceWithFormat(0, w, h, 0, PixelFormatXBGR8888)
+			require.NoError(t, err)
+			yuvToRGB8888(img, &m, row)
+			yuvToRGBGeneric(img, &m, generic)
+			require.Equal(t, generic.pixels, row.pixels, "%s mode %d", GetPixelFormatName(format), mode)
+		}
+	}
+}
+
+func TestYUVToRGBKnownColors(t *testing.T) {
+	for _, tc := range []struct {
+		mode    int
+		y, u, v uint8
+		want    [3]uint32
+	}{
+		{YUVConversionBT601, 16, 128, 128, [3]uint32{0, 0, 0}},
+		{YUVConversionBT601, 235, 128, 128, [3]uint32{255, 255, 255}},
+		{YUVConversionBT601, 81, 90, 240, [3]uint32{255, 0, 0}},
+		{YUVConversionBT601, 145, 54, 34, [3]uint32{0, 255, 0}},
+		{YUVConversionBT709, 63, 102, 240, [3]uint32{255, 0, 0}},
+		{YUVConversionBT709, 32, 240, 118, [3]uint32{0, 0, 255}},
+		{YUVConversionJPEG, 0, 128, 128, [3]uint32{0, 0, 0}},
+		{YUVConversionJPEG, 255, 128, 128, [3]uint32{255, 255, 255}},
+		{YUVConversionJPEG, 128, 128, 128, [3]uint32{128, 128, 128}},
+	} {
+		m := yuvMatrices[tc.mode]
+		r, g, b := m.rgb(tc.y, tc.u, tc.v)
+		for i, c := range []uint32{r, g, b} {
+			assert.InDelta(t, tc.want[i], c, 1, "mode %d %v", tc.mode, []uint8{tc.y, tc.u, tc.v})
+		}
+	}
+}
+
+func TestYUVConversionMode(t *testing.T) {
+	defer SetYUVConversionMode(YUVConversionBT601)
+	assert.Equal(t, YUVConversionBT601, GetYUVConversionMode())
+	SetYUVConversionMode(YUVConversionAutomatic)
+	assert.Equal(t, YUVConversionBT601, GetYUVConversionModeForResolution(720, 576))
+	assert.Equal(t, YUVConversionBT709, GetYUVConversionModeForResolution(1280, 720))
+	SetYUVConversionMode(YUVConversionJPEG)
+	assert.Equal(t, YUVConversionJPEG, GetYUVConversionModeForResolution(1280, 720))
+}
+
+func TestConvertYUV(t *testing.T) {
+	f := newYUVFrame(5, 3, 1)
+	for _, format := range yuvFormats {
+		src, pitch := f.pack(format)
+		dst := make([]byte, len(src)+pitch)
+		require.NoError(t, ConvertPixels(5, 3, format, src, pitch, format, dst, pitch))
+		assert.Equal(t, src, dst[:len(src)], GetPixelFormatName(format))
+		_, size := yuvSize(format, 5, 3)
+		assert.Equal(t, len(src), size, GetPixelFormatName(format))
+
+		assert.Error(t, ConvertPixels(5, 3, format, src[:len(src)-1], pitch, PixelFormatARGB8888, make([]byte, 60), 20))
+	}
+	src, pitch := f.pack(PixelFormatYV12)
+	assert.Error(t, ConvertPixels(5, 3, PixelFormatYV12, src, pitch, PixelFormatNV12, make([]byte, len(src)), pitch))
+	assert.Error(t, ConvertPixels(5, 3, PixelFormatARGB8888, make([]byte, 60), 20, PixelFormatYV12, src, pitch))
+	assert.Equal(t, uint32(2), BytesPerPixel(PixelFormatUYVY))
+	assert.Equal(t, uint32(1), BytesPerPixel(PixelFormatNV21))
+	assert.Equal(t, "GDL_PIXELFORMAT_IYUV", GetPixelFormatName(PixelFormatIYUV))
+}
+
+// expectedRGB converts a packed frame with ConvertPixels.
+func expectedRGB(t *testing.T, format uint32, src []byte, pitch, w, h int) [][]uint32 {
+	s, err := CreateRGBSurfaceWithFormat(0, w, h, 0, PixelFormatARGB8888)
+	require.NoError(t, err)
+	require.NoError(t, ConvertPixels(w, h, format, src, pitch, PixelFormatARGB8888, s.pixels, s.pitch))
+	return surfacePixels(s, Rect{W: w, H: h})
+}
+
+func TestYUVTexture(t *testing.T) {
+	const w, h = 4, 4
+	r, s := newTestRenderer(t, w, h)
+	first, second := newYUVFrame(w, h, 1), newYUVFrame(w, h, 2)
+
+	for _, format := range yuvFormats {
+		name := GetPixelFormatName(format)
+		tex, err := r.CreateTexture(format, TextureAccessStreaming, w, h)
+		require.NoError(t, err, name)
+		require.NotNil(t, tex.native)
+		assert.Equal(t, uint32(PixelFormatXRGB8888), tex.native.format)
+
+		src, pitch := first.pack(format)
+		require.NoError(t, tex.Update(nil, src, pitch), name)
+		require.NoError(t, r.Copy(tex, nil, nil))
+		want := expectedRGB(t, format, src, pitch, w, h)
+		assert.Equal(t, want, surfacePixels(s, Rect{W: w, H: h}), name)
+
+		// a partial update only changes its rect
+		sub := &yuvFrame{w: 2, h: 2}
+		for y := 2; y < 4; y++ {
+			sub.y = append(sub.y, second.y[y][2:4])
+		}
+		for y := 1; y < 4; y++ {
+			sub.u = append(sub.u, second.u[y][1:2])
+			sub.v = append(sub.v, second.v[y][1:2])
+		}
+		if isPlanarYUVFormat(format) {
+			sub.u, sub.v = sub.u[:1], sub.v[:1]
+		} else {
+			sub.u, sub.v = sub.u[1:], sub.v[1:]
+		}
+		subSrc, subPitch := sub.pack(format)
+		require.NoError(t, tex.Update(&Rect{X: 2, Y: 2, W: 2, H: 2}, subSrc, subPitch), name)
+		require.NoError(t, r.Copy(tex, nil, nil))
+		for y := 2; y < 4; y++ {
+			copy(first.y[y][2:4], second.y[y][2:4])
+		}
+		chromaRows := []int{2, 3}
+		if isPlanarYUVFormat(format) {
+			chromaRows = []int{1}
+		}
+		for _, y := range chromaRows {
+			first.u[y][1], first.v[y][1] = second.u[y][1], second.v[y][1]
+		}
+		src, pitch = first.pack(format)
+		assert.Equal(t, expectedRGB(t, format, src, pitch, w, h), surfacePixels(s, Rect{W: w, H: h}), name)
+
+		// locking
+		_, _, err = tex.Lock(&Rect{W: 2, H: 2})
+		if isPlanarYUVFormat(format) {
+			assert.Error(t, err, name)
+		} else {
+			assert.NoError(t, err, name)
+			tex.Unlock()
+		}
+		pixels, lockPitch, err := tex.Lock(nil)
+		require.NoError(t, err)
+		assert.Equal(t, pitch, lockPitch)
+		other, _ := second.pack(format)
+		copy(pixels, other)
+		tex.Unlock()
+		require.NoError(t, r.Copy(tex, nil, nil))
+		assert.Equal(t, expectedRGB(t, format, other, pitch, w, h), surfacePixels(s, Rect{W: w, H: h}), name)
+		DestroyTexture(tex)
+	}
+}
+
+// TestYUVTextureUpdateRows checks that an update converts only its rows,
+// rounded out to the chroma samples they share.
+func TestYUVTextureUpdateRows(t *testing.T) {
+	const w, h = 4, 4
+	r, _ := newTestRenderer(t, w, h)
+	f := newYUVFrame(w, h, 4)
+	for _, tc := range []struct {
+		format    uint32
+		converted Rect
+	}{
+		{PixelFormatNV12, Rect{W: 2, H: 2}},
+		{PixelFormatYUY2, Rect{Y: 1, W: 2, H: 1}},
+	} {
+		name := GetPixelFormatName(tc.format)
+		tex, err := r.CreateTexture(tc.format, TextureAccessStreaming, w, h)
+		require.NoError(t, err, name)
+		src, pitch := f.pack(tc.format)
+		require.NoError(t, tex.Update(nil, src, pitch), name)
+		want := expectedRGB(t, tc.format, src, pitch, w, h)
+
+		const marker = 0x123456
+		native := tex.native.driverData.(*Surface)
+		require.NoError(t, native.FillRect(nil, marker))
+		require.NoError(t, tex.updateNativeYUV(Rect{X: 1, Y: 1, W: 1, H: 1}), name)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				got := native.getPixel(x, y) & 0xFFFFFF
+				if tc.converted.Contains(Point{X: x, Y: y}) {
+					assert.Equal(t, want[y][x]&0xFFFFFF, got, "%s pixel (%d, %d)", name, x, y)
+				} else {
+					assert.Equal(t, uint32(marker), got, "%s pixel (%d, %d)", name, x, y)
+				}
+			}
+		}
+		DestroyTexture(tex)
+	}
+}
+
+func TestUpdateYUVAndNV(t *testing.T) {
+	const w, h = 4, 2
+	r, s := newTestRenderer(t, w, h)
+	f := newYUVFrame(w, h, 3)
+
+	for _, format := range []uint32{PixelFormatYV12, PixelFormatIYUV} {
+		tex, err := r.CreateTexture(format, TextureAccessStatic, w, h)
+		require.NoError(t, err)
+		y := append(append([]byte{}, f.y[0]...), f.y[1]...)
+		require.NoError(t, tex.UpdateYUV(nil, y, w, f.u[0], 2, f.v[0], 2))
+		require.NoError(t, r.Copy(tex, nil, nil))
+		src, pitch := f.pack(format)
+		assert.Equal(t, expectedRGB(t, format, src, pitch, w, h), surfacePixels(s, Rect{W: w, H: h}))
+		assert.Error(t, tex.UpdateNV(nil, y, w, f.u[0], 4))
+		assert.Error(t, tex.UpdateYUV(nil, y, w, f.u[0][:1], 2, f.v[0], 2))
+	}
+
+	for _, format := range []uint32{PixelFormatNV12, PixelFormatNV21} {
+		tex, err := r.CreateTexture(format, TextureAccessStatic, w, h)
+		require.NoError(t, err)
+		src, pitch := f.pack(format)
+		require.NoError(t, tex.UpdateNV(nil, src[:w*h], w, src[w*h:], w))
+		require.NoError(t, r.Copy(tex, nil, nil))
+		assert.Equal(t, expectedRGB(t, format, src, pitch, w, h), surfacePixels(s, Rect{W: w, H: h}))
+		assert.Error(t, tex.UpdateYUV(nil, src, w, src, 2, src, 2))
+	}
+}
@@ -8,9 +8,9 @@ import (
 	"sync"
 	"syscall"
 
+	"github.com/elliotmr/gdl/log"
 	"github.com/elliotmr/gdl/wl/wlp"
 	"github.com/pkg/errors"
-	"fmt"
 )
 
 
@@ -47,7 +47,7 @@ func (c *Client) Ping(serial uint32) {
 
 // Implements Shm Listener
 func (c *Client) Format(format uint32) {
-	fmt.Println("Valid Format: ", format)
+	log.Debug(log.CategoryWayland, "Valid Format: %d", format)
 }
 
 
@@ -1,9 +1,9 @@
 package wl
 
 import (
+	"github.com/elliotmr/gdl/log"
 	"github.com/elliotmr/gdl/wl/wlp"
 	"sync"
-	"fmt"
 )
 
 type Screen struct {
@@ -25,7 +25,7 @@ type Screen struct {
 }
 
 func (s *Screen) Geometry(x int32, y int32, physicalWidth int32, physicalHeight int32, subpixel int32, make string, model string, transform int32) {
-	fmt.Printf("Geometry(%d, %d, %d, %d, %d, %s, %s, %d), called\n", x, y, physicalWidth, physicalHeight, subpixel, make, model, transform)
+	log.Debug(log.CategoryWayland, "Geometry(%d, %d, %d, %d, %d, %s, %s, %d), called", x, y, physicalWidth, physicalHeight, subpixel, make, model, transform)
 	s.Mu.Lock()
 	defer s.Mu.Unlock()
 	s.X = x
@@ -39,7 +39,7 @@ func (s *Screen) Geometry(x int32, y int32, physicalWidth int32, physicalHeight
 }
 
 func (s *Screen) Mode(flags uint32, width int32, height int32, refresh int32) {
-	fmt.Println("Mode called")
+	log.Debug(log.CategoryWayland, "Mode(%d, %d, %d, %d), called", flags, width, height, refresh)
 	s.Mu.Lock()
 	defer s.Mu.Unlock()
 	s.Flags = flags
@@ -1,8 +1,8 @@
 package wl
 
 import (
+	"github.com/elliotmr/gdl/log"
 	"github.com/elliotmr/gdl/wl/wlp"
-	"fmt"
 	"github.com/pkg/errors"
 )
 
@@ -11,7 +11,7 @@ type surfaceCb struct {
 }
 
 func (scb *surfaceCb) Configure(serial uint32) {
-	fmt.Printf("Configure(serial: %X) -> ACK\n", serial)
+	log.Debug(log.CategoryWayland, "Configure(serial: %X) -> ACK", serial)
 	scb.w.AckConfigure(serial)
 }
 
@@ -68,7 +68,7 @@ func (w *Window) Release() {
 }
 
 func (w *Window) Configure(width int32, height int32, states []byte) {
-	fmt.Printf("Configuring Window (%d, %d, %v)\n", width, height, states)
+	log.Debug(log.CategoryWayland, "Configuring Window (%d, %d, %v)", width, height, states)
 	if w.buffers[0].bound {
 		w.buffers[0].Destroy()
 	}
@@ -98,9 +98,9 @@ func (w *Window) Close() {
 }
 
 func (w *Window) Enter(output uint32) {
-	fmt.Println("entering window")
+	log.Debug(log.CategoryWayland, "entering window")
 }
 
 func (w *Window) Leave(output uint32) {
-	fmt.Println("leaving window")
+	log.Debug(log.CategoryWayland, "leaving window")
 }
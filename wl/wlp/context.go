@@ -3,13 +3,13 @@ package wlp
 import (
 	"bytes"
 	"encoding/hex"
-	"fmt"
 	"net"
 	"os"
 	"sync"
 	"sync/atomic"
 	"syscall"
 
+	"github.com/elliotmr/gdl/log"
 	"github.com/pkg/errors"
 )
 
@@ -94,17 +94,17 @@ outer:
 		n, oobn, _, _, err := c.c.ReadMsgUnix(buf[j:], oobBuf)
 		n += j
 		if err != nil {
-			fmt.Printf("readloop error: %v\n", err)
+			log.Error(log.CategoryWayland, "readloop error: %v", err)
 			return
 		}
 		file, err := c.decodeFD(oobn, oobBuf)
 		if err != nil {
-			fmt.Printf("readloop error: %v\n", err)
+			log.Error(log.CategoryWayland, "readloop error: %v", err)
 			return
 		}
-		// TODO: remove after debugging
-		fmt.Println("Received:")
-		fmt.Println(hex.Dump(buf[:n]))
+		if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+			log.Verbose(log.CategoryWayland, "Received:\n%s", hex.Dump(buf[:n]))
+		}
 
 		i := 0
 		for i < n {
@@ -120,7 +120,7 @@ outer:
 				continue outer
 			}
 			i += m + 8
-			fmt.Printf("Event: %d, %d, %d, %v\n", id, size, opcode, payload)
+			log.Verbose(log.CategoryWayland, "Event: %d, %d, %d, %v", id, size, opcode, payload)
 			c.obj[id].dispatch(opcode, payload, file)
 			if c.Err != nil {
 				// trigger pending callbacks
@@ -171,7 +171,7 @@ func (c *Context) Global(name uint32, iface string, version uint32) {
 		c.glbByString[iface],
 		glb,
 	)
-	fmt.Println("Added global: ", iface)
+	log.Debug(log.CategoryWayland, "Added global: %s", iface)
 }
 
 // GlobalRemove is an implementation of the RegistryListener interface for removing
@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/hex"
-	"fmt"
 	"os"
 
+	"github.com/elliotmr/gdl/log"
 	"github.com/pkg/errors"
 )
 
@@ -97,9 +97,9 @@ func (this *Display) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeDisplayError:
 		if this.l == nil {
-			fmt.Println("ignoring Error event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Display -> Error event: no listener")
 		} else {
-			fmt.Println("Received Display -> Error: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Display -> Error: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			objectID := hostByteOrder.Uint32(buf.Next(4))
@@ -114,9 +114,9 @@ func (this *Display) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeDisplayDeleteID:
 		if this.l == nil {
-			fmt.Println("ignoring DeleteID event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Display -> DeleteID event: no listener")
 		} else {
-			fmt.Println("Received Display -> DeleteID: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Display -> DeleteID: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			id := hostByteOrder.Uint32(buf.Next(4))
@@ -161,8 +161,9 @@ func (this *Display) Sync(l CallbackListener) (*Callback, error) {
 	binary.Write(this.c.buf, hostByteOrder, uint32(ret.i))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDisplaySync)
 	ret.l = l
-	fmt.Println("Sending Display -> Sync")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Display -> Sync\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -193,8 +194,9 @@ func (this *Display) GetRegistry(l RegistryListener) (*Registry, error) {
 	binary.Write(this.c.buf, hostByteOrder, uint32(ret.i))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDisplayGetRegistry)
 	ret.l = l
-	fmt.Println("Sending Display -> GetRegistry")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Display -> GetRegistry\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -300,9 +302,9 @@ func (this *Registry) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeRegistryGlobal:
 		if this.l == nil {
-			fmt.Println("ignoring Global event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Registry -> Global event: no listener")
 		} else {
-			fmt.Println("Received Registry -> Global: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Registry -> Global: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			name := hostByteOrder.Uint32(buf.Next(4))
@@ -317,9 +319,9 @@ func (this *Registry) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeRegistryGlobalRemove:
 		if this.l == nil {
-			fmt.Println("ignoring GlobalRemove event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Registry -> GlobalRemove event: no listener")
 		} else {
-			fmt.Println("Received Registry -> GlobalRemove: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Registry -> GlobalRemove: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			name := hostByteOrder.Uint32(buf.Next(4))
@@ -362,8 +364,9 @@ func (this *Registry) Bind(name uint32, iface string, version uint32, id uint32)
 	binary.Write(this.c.buf, hostByteOrder, uint32(id))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeRegistryBind)
 
-	fmt.Println("Sending Registry -> Bind")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Registry -> Bind\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -429,9 +432,9 @@ func (this *Callback) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeCallbackDone:
 		if this.l == nil {
-			fmt.Println("ignoring Done event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Callback -> Done event: no listener")
 		} else {
-			fmt.Println("Received Callback -> Done: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Callback -> Done: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			callbackData := hostByteOrder.Uint32(buf.Next(4))
@@ -527,8 +530,9 @@ func (this *Compositor) CreateSurface(l SurfaceListener) (*Surface, error) {
 	binary.Write(this.c.buf, hostByteOrder, uint32(ret.i))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeCompositorCreateSurface)
 	ret.l = l
-	fmt.Println("Sending Compositor -> CreateSurface")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Compositor -> CreateSurface\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -557,8 +561,9 @@ func (this *Compositor) CreateRegion(l RegionListener) (*Region, error) {
 	binary.Write(this.c.buf, hostByteOrder, uint32(ret.i))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeCompositorCreateRegion)
 	ret.l = l
-	fmt.Println("Sending Compositor -> CreateRegion")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Compositor -> CreateRegion\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -668,8 +673,9 @@ func (this *ShmPool) CreateBuffer(l BufferListener, offset int32, width int32, h
 	binary.Write(this.c.buf, hostByteOrder, uint32(format))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShmPoolCreateBuffer)
 	ret.l = l
-	fmt.Println("Sending ShmPool -> CreateBuffer")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShmPool -> CreateBuffer\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -700,8 +706,9 @@ func (this *ShmPool) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShmPoolDestroy)
 
-	fmt.Println("Sending ShmPool -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShmPool -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -732,8 +739,9 @@ func (this *ShmPool) Resize(size int32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(size))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShmPoolResize)
 
-	fmt.Println("Sending ShmPool -> Resize")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShmPool -> Resize\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -879,9 +887,9 @@ func (this *Shm) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeShmFormat:
 		if this.l == nil {
-			fmt.Println("ignoring Format event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Shm -> Format event: no listener")
 		} else {
-			fmt.Println("Received Shm -> Format: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Shm -> Format: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			format := hostByteOrder.Uint32(buf.Next(4))
@@ -922,8 +930,9 @@ func (this *Shm) CreatePool(l ShmPoolListener, fd *os.File, size int32) (*ShmPoo
 	binary.Write(this.c.buf, hostByteOrder, uint32(size))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShmCreatePool)
 	ret.l = l
-	fmt.Println("Sending Shm -> CreatePool")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Shm -> CreatePool\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -1007,9 +1016,9 @@ func (this *Buffer) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeBufferRelease:
 		if this.l == nil {
-			fmt.Println("ignoring Release event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Buffer -> Release event: no listener")
 		} else {
-			fmt.Println("Received Buffer -> Release: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Buffer -> Release: Dispatching")
 
 			this.l.Release()
 		}
@@ -1042,8 +1051,9 @@ func (this *Buffer) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeBufferDestroy)
 
-	fmt.Println("Sending Buffer -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Buffer -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1175,9 +1185,9 @@ func (this *DataOffer) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeDataOfferOffer:
 		if this.l == nil {
-			fmt.Println("ignoring Offer event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataOffer -> Offer event: no listener")
 		} else {
-			fmt.Println("Received DataOffer -> Offer: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataOffer -> Offer: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			len = int(hostByteOrder.Uint32(buf.Next(4)))
@@ -1190,9 +1200,9 @@ func (this *DataOffer) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeDataOfferSourceActions:
 		if this.l == nil {
-			fmt.Println("ignoring SourceActions event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataOffer -> SourceActions event: no listener")
 		} else {
-			fmt.Println("Received DataOffer -> SourceActions: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataOffer -> SourceActions: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			sourceActions := hostByteOrder.Uint32(buf.Next(4))
@@ -1201,9 +1211,9 @@ func (this *DataOffer) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeDataOfferAction:
 		if this.l == nil {
-			fmt.Println("ignoring Action event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataOffer -> Action event: no listener")
 		} else {
-			fmt.Println("Received DataOffer -> Action: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataOffer -> Action: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			dndAction := hostByteOrder.Uint32(buf.Next(4))
@@ -1256,8 +1266,9 @@ func (this *DataOffer) Accept(serial uint32, mimeType string) error {
 	}
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataOfferAccept)
 
-	fmt.Println("Sending DataOffer -> Accept")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataOffer -> Accept\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1305,8 +1316,9 @@ func (this *DataOffer) Receive(mimeType string, fd *os.File) error {
 	oob = this.c.encodeFD(fd)
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataOfferReceive)
 
-	fmt.Println("Sending DataOffer -> Receive")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataOffer -> Receive\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1333,8 +1345,9 @@ func (this *DataOffer) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataOfferDestroy)
 
-	fmt.Println("Sending DataOffer -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataOffer -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1371,8 +1384,9 @@ func (this *DataOffer) Finish() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataOfferFinish)
 
-	fmt.Println("Sending DataOffer -> Finish")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataOffer -> Finish\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1431,8 +1445,9 @@ func (this *DataOffer) SetActions(dndActions uint32, preferredAction uint32) err
 	binary.Write(this.c.buf, hostByteOrder, uint32(preferredAction))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataOfferSetActions)
 
-	fmt.Println("Sending DataOffer -> SetActions")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataOffer -> SetActions\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1597,9 +1612,9 @@ func (this *DataSource) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeDataSourceTarget:
 		if this.l == nil {
-			fmt.Println("ignoring Target event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataSource -> Target event: no listener")
 		} else {
-			fmt.Println("Received DataSource -> Target: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataSource -> Target: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			len = int(hostByteOrder.Uint32(buf.Next(4)))
@@ -1612,9 +1627,9 @@ func (this *DataSource) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeDataSourceSend:
 		if this.l == nil {
-			fmt.Println("ignoring Send event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataSource -> Send event: no listener")
 		} else {
-			fmt.Println("Received DataSource -> Send: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataSource -> Send: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			len = int(hostByteOrder.Uint32(buf.Next(4)))
@@ -1628,33 +1643,33 @@ func (this *DataSource) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeDataSourceCancelled:
 		if this.l == nil {
-			fmt.Println("ignoring Cancelled event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataSource -> Cancelled event: no listener")
 		} else {
-			fmt.Println("Received DataSource -> Cancelled: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataSource -> Cancelled: Dispatching")
 
 			this.l.Cancelled()
 		}
 	case opCodeDataSourceDndDropPerformed:
 		if this.l == nil {
-			fmt.Println("ignoring DndDropPerformed event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataSource -> DndDropPerformed event: no listener")
 		} else {
-			fmt.Println("Received DataSource -> DndDropPerformed: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataSource -> DndDropPerformed: Dispatching")
 
 			this.l.DndDropPerformed()
 		}
 	case opCodeDataSourceDndFinished:
 		if this.l == nil {
-			fmt.Println("ignoring DndFinished event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataSource -> DndFinished event: no listener")
 		} else {
-			fmt.Println("Received DataSource -> DndFinished: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataSource -> DndFinished: Dispatching")
 
 			this.l.DndFinished()
 		}
 	case opCodeDataSourceAction:
 		if this.l == nil {
-			fmt.Println("ignoring Action event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataSource -> Action event: no listener")
 		} else {
-			fmt.Println("Received DataSource -> Action: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataSource -> Action: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			dndAction := hostByteOrder.Uint32(buf.Next(4))
@@ -1695,8 +1710,9 @@ func (this *DataSource) Offer(mimeType string) error {
 	}
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataSourceOffer)
 
-	fmt.Println("Sending DataSource -> Offer")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataSource -> Offer\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1723,8 +1739,9 @@ func (this *DataSource) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataSourceDestroy)
 
-	fmt.Println("Sending DataSource -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataSource -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1764,8 +1781,9 @@ func (this *DataSource) SetActions(dndActions uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(dndActions))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataSourceSetActions)
 
-	fmt.Println("Sending DataSource -> SetActions")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataSource -> SetActions\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1905,9 +1923,9 @@ func (this *DataDevice) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeDataDeviceDataOffer:
 		if this.l == nil {
-			fmt.Println("ignoring DataOffer event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataDevice -> DataOffer event: no listener")
 		} else {
-			fmt.Println("Received DataDevice -> DataOffer: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataDevice -> DataOffer: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 
@@ -1915,9 +1933,9 @@ func (this *DataDevice) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeDataDeviceEnter:
 		if this.l == nil {
-			fmt.Println("ignoring Enter event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataDevice -> Enter event: no listener")
 		} else {
-			fmt.Println("Received DataDevice -> Enter: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataDevice -> Enter: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -1930,17 +1948,17 @@ func (this *DataDevice) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeDataDeviceLeave:
 		if this.l == nil {
-			fmt.Println("ignoring Leave event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataDevice -> Leave event: no listener")
 		} else {
-			fmt.Println("Received DataDevice -> Leave: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataDevice -> Leave: Dispatching")
 
 			this.l.Leave()
 		}
 	case opCodeDataDeviceMotion:
 		if this.l == nil {
-			fmt.Println("ignoring Motion event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataDevice -> Motion event: no listener")
 		} else {
-			fmt.Println("Received DataDevice -> Motion: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataDevice -> Motion: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			time := hostByteOrder.Uint32(buf.Next(4))
@@ -1951,17 +1969,17 @@ func (this *DataDevice) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeDataDeviceDrop:
 		if this.l == nil {
-			fmt.Println("ignoring Drop event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataDevice -> Drop event: no listener")
 		} else {
-			fmt.Println("Received DataDevice -> Drop: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataDevice -> Drop: Dispatching")
 
 			this.l.Drop()
 		}
 	case opCodeDataDeviceSelection:
 		if this.l == nil {
-			fmt.Println("ignoring Selection event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring DataDevice -> Selection event: no listener")
 		} else {
-			fmt.Println("Received DataDevice -> Selection: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received DataDevice -> Selection: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			id := hostByteOrder.Uint32(buf.Next(4))
@@ -2024,8 +2042,9 @@ func (this *DataDevice) StartDrag(source uint32, origin uint32, icon uint32, ser
 	binary.Write(this.c.buf, hostByteOrder, uint32(serial))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataDeviceStartDrag)
 
-	fmt.Println("Sending DataDevice -> StartDrag")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataDevice -> StartDrag\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -2057,8 +2076,9 @@ func (this *DataDevice) SetSelection(source uint32, serial uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(serial))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataDeviceSetSelection)
 
-	fmt.Println("Sending DataDevice -> SetSelection")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataDevice -> SetSelection\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -2085,8 +2105,9 @@ func (this *DataDevice) Release() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataDeviceRelease)
 
-	fmt.Println("Sending DataDevice -> Release")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataDevice -> Release\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -2190,8 +2211,9 @@ func (this *DataDeviceManager) CreateDataSource(l DataSourceListener) (*DataSour
 	binary.Write(this.c.buf, hostByteOrder, uint32(ret.i))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataDeviceManagerCreateDataSource)
 	ret.l = l
-	fmt.Println("Sending DataDeviceManager -> CreateDataSource")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataDeviceManager -> CreateDataSource\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -2221,8 +2243,9 @@ func (this *DataDeviceManager) GetDataDevice(l DataDeviceListener, seat uint32)
 	binary.Write(this.c.buf, hostByteOrder, uint32(seat))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeDataDeviceManagerGetDataDevice)
 	ret.l = l
-	fmt.Println("Sending DataDeviceManager -> GetDataDevice")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending DataDeviceManager -> GetDataDevice\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -2322,8 +2345,9 @@ func (this *Shell) GetShellSurface(l ShellSurfaceListener, surface uint32) (*She
 	binary.Write(this.c.buf, hostByteOrder, uint32(surface))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShellGetShellSurface)
 	ret.l = l
-	fmt.Println("Sending Shell -> GetShellSurface")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Shell -> GetShellSurface\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -2463,9 +2487,9 @@ func (this *ShellSurface) dispatch(opCode uint16, payload []byte, file *os.File)
 	switch opCode {
 	case opCodeShellSurfacePing:
 		if this.l == nil {
-			fmt.Println("ignoring Ping event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring ShellSurface -> Ping event: no listener")
 		} else {
-			fmt.Println("Received ShellSurface -> Ping: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received ShellSurface -> Ping: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -2474,9 +2498,9 @@ func (this *ShellSurface) dispatch(opCode uint16, payload []byte, file *os.File)
 		}
 	case opCodeShellSurfaceConfigure:
 		if this.l == nil {
-			fmt.Println("ignoring Configure event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring ShellSurface -> Configure event: no listener")
 		} else {
-			fmt.Println("Received ShellSurface -> Configure: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received ShellSurface -> Configure: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			edges := hostByteOrder.Uint32(buf.Next(4))
@@ -2487,9 +2511,9 @@ func (this *ShellSurface) dispatch(opCode uint16, payload []byte, file *os.File)
 		}
 	case opCodeShellSurfacePopupDone:
 		if this.l == nil {
-			fmt.Println("ignoring PopupDone event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring ShellSurface -> PopupDone event: no listener")
 		} else {
-			fmt.Println("Received ShellSurface -> PopupDone: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received ShellSurface -> PopupDone: Dispatching")
 
 			this.l.PopupDone()
 		}
@@ -2521,8 +2545,9 @@ func (this *ShellSurface) Pong(serial uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(serial))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShellSurfacePong)
 
-	fmt.Println("Sending ShellSurface -> Pong")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShellSurface -> Pong\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -2555,8 +2580,9 @@ func (this *ShellSurface) Move(seat uint32, serial uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(serial))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShellSurfaceMove)
 
-	fmt.Println("Sending ShellSurface -> Move")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShellSurface -> Move\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -2590,8 +2616,9 @@ func (this *ShellSurface) Resize(seat uint32, serial uint32, edges uint32) error
 	binary.Write(this.c.buf, hostByteOrder, uint32(edges))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShellSurfaceResize)
 
-	fmt.Println("Sending ShellSurface -> Resize")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShellSurface -> Resize\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -2620,8 +2647,9 @@ func (this *ShellSurface) SetToplevel() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShellSurfaceSetToplevel)
 
-	fmt.Println("Sending ShellSurface -> SetToplevel")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShellSurface -> SetToplevel\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -2658,8 +2686,9 @@ func (this *ShellSurface) SetTransient(parent uint32, x int32, y int32, flags ui
 	binary.Write(this.c.buf, hostByteOrder, uint32(flags))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShellSurfaceSetTransient)
 
-	fmt.Println("Sending ShellSurface -> SetTransient")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShellSurface -> SetTransient\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -2721,8 +2750,9 @@ func (this *ShellSurface) SetFullscreen(method uint32, framerate uint32, output
 	binary.Write(this.c.buf, hostByteOrder, uint32(output))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShellSurfaceSetFullscreen)
 
-	fmt.Println("Sending ShellSurface -> SetFullscreen")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShellSurface -> SetFullscreen\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -2773,8 +2803,9 @@ func (this *ShellSurface) SetPopup(seat uint32, serial uint32, parent uint32, x
 	binary.Write(this.c.buf, hostByteOrder, uint32(flags))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShellSurfaceSetPopup)
 
-	fmt.Println("Sending ShellSurface -> SetPopup")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShellSurface -> SetPopup\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -2819,8 +2850,9 @@ func (this *ShellSurface) SetMaximized(output uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(output))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShellSurfaceSetMaximized)
 
-	fmt.Println("Sending ShellSurface -> SetMaximized")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShellSurface -> SetMaximized\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -2859,8 +2891,9 @@ func (this *ShellSurface) SetTitle(title string) error {
 	}
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShellSurfaceSetTitle)
 
-	fmt.Println("Sending ShellSurface -> SetTitle")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShellSurface -> SetTitle\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -2898,8 +2931,9 @@ func (this *ShellSurface) SetClass(class string) error {
 	}
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeShellSurfaceSetClass)
 
-	fmt.Println("Sending ShellSurface -> SetClass")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ShellSurface -> SetClass\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -3031,9 +3065,9 @@ func (this *Surface) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeSurfaceEnter:
 		if this.l == nil {
-			fmt.Println("ignoring Enter event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Surface -> Enter event: no listener")
 		} else {
-			fmt.Println("Received Surface -> Enter: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Surface -> Enter: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			output := hostByteOrder.Uint32(buf.Next(4))
@@ -3042,9 +3076,9 @@ func (this *Surface) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeSurfaceLeave:
 		if this.l == nil {
-			fmt.Println("ignoring Leave event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Surface -> Leave event: no listener")
 		} else {
-			fmt.Println("Received Surface -> Leave: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Surface -> Leave: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			output := hostByteOrder.Uint32(buf.Next(4))
@@ -3077,8 +3111,9 @@ func (this *Surface) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSurfaceDestroy)
 
-	fmt.Println("Sending Surface -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Surface -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -3146,8 +3181,9 @@ func (this *Surface) Attach(buffer uint32, x int32, y int32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(y))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSurfaceAttach)
 
-	fmt.Println("Sending Surface -> Attach")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Surface -> Attach\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -3198,8 +3234,9 @@ func (this *Surface) Damage(x int32, y int32, width int32, height int32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(height))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSurfaceDamage)
 
-	fmt.Println("Sending Surface -> Damage")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Surface -> Damage\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -3259,8 +3296,9 @@ func (this *Surface) Frame(l CallbackListener) (*Callback, error) {
 	binary.Write(this.c.buf, hostByteOrder, uint32(ret.i))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSurfaceFrame)
 	ret.l = l
-	fmt.Println("Sending Surface -> Frame")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Surface -> Frame\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -3311,8 +3349,9 @@ func (this *Surface) SetOpaqueRegion(region uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(region))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSurfaceSetOpaqueRegion)
 
-	fmt.Println("Sending Surface -> SetOpaqueRegion")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Surface -> SetOpaqueRegion\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -3361,8 +3400,9 @@ func (this *Surface) SetInputRegion(region uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(region))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSurfaceSetInputRegion)
 
-	fmt.Println("Sending Surface -> SetInputRegion")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Surface -> SetInputRegion\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -3405,8 +3445,9 @@ func (this *Surface) Commit() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSurfaceCommit)
 
-	fmt.Println("Sending Surface -> Commit")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Surface -> Commit\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -3462,8 +3503,9 @@ func (this *Surface) SetBufferTransform(transform int32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(transform))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSurfaceSetBufferTransform)
 
-	fmt.Println("Sending Surface -> SetBufferTransform")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Surface -> SetBufferTransform\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -3513,8 +3555,9 @@ func (this *Surface) SetBufferScale(scale int32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(scale))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSurfaceSetBufferScale)
 
-	fmt.Println("Sending Surface -> SetBufferScale")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Surface -> SetBufferScale\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -3576,8 +3619,9 @@ func (this *Surface) DamageBuffer(x int32, y int32, width int32, height int32) e
 	binary.Write(this.c.buf, hostByteOrder, uint32(height))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSurfaceDamageBuffer)
 
-	fmt.Println("Sending Surface -> DamageBuffer")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Surface -> DamageBuffer\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -3688,9 +3732,9 @@ func (this *Seat) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeSeatCapabilities:
 		if this.l == nil {
-			fmt.Println("ignoring Capabilities event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Seat -> Capabilities event: no listener")
 		} else {
-			fmt.Println("Received Seat -> Capabilities: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Seat -> Capabilities: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			capabilities := hostByteOrder.Uint32(buf.Next(4))
@@ -3699,9 +3743,9 @@ func (this *Seat) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeSeatName:
 		if this.l == nil {
-			fmt.Println("ignoring Name event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Seat -> Name event: no listener")
 		} else {
-			fmt.Println("Received Seat -> Name: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Seat -> Name: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			len = int(hostByteOrder.Uint32(buf.Next(4)))
@@ -3746,8 +3790,9 @@ func (this *Seat) GetPointer(l PointerListener) (*Pointer, error) {
 	binary.Write(this.c.buf, hostByteOrder, uint32(ret.i))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSeatGetPointer)
 	ret.l = l
-	fmt.Println("Sending Seat -> GetPointer")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Seat -> GetPointer\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -3782,8 +3827,9 @@ func (this *Seat) GetKeyboard(l KeyboardListener) (*Keyboard, error) {
 	binary.Write(this.c.buf, hostByteOrder, uint32(ret.i))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSeatGetKeyboard)
 	ret.l = l
-	fmt.Println("Sending Seat -> GetKeyboard")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Seat -> GetKeyboard\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -3818,8 +3864,9 @@ func (this *Seat) GetTouch(l TouchListener) (*Touch, error) {
 	binary.Write(this.c.buf, hostByteOrder, uint32(ret.i))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSeatGetTouch)
 	ret.l = l
-	fmt.Println("Sending Seat -> GetTouch")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Seat -> GetTouch\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -3847,8 +3894,9 @@ func (this *Seat) Release() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSeatRelease)
 
-	fmt.Println("Sending Seat -> Release")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Seat -> Release\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -4120,9 +4168,9 @@ func (this *Pointer) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodePointerEnter:
 		if this.l == nil {
-			fmt.Println("ignoring Enter event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Pointer -> Enter event: no listener")
 		} else {
-			fmt.Println("Received Pointer -> Enter: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Pointer -> Enter: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -4134,9 +4182,9 @@ func (this *Pointer) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodePointerLeave:
 		if this.l == nil {
-			fmt.Println("ignoring Leave event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Pointer -> Leave event: no listener")
 		} else {
-			fmt.Println("Received Pointer -> Leave: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Pointer -> Leave: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -4146,9 +4194,9 @@ func (this *Pointer) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodePointerMotion:
 		if this.l == nil {
-			fmt.Println("ignoring Motion event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Pointer -> Motion event: no listener")
 		} else {
-			fmt.Println("Received Pointer -> Motion: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Pointer -> Motion: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			time := hostByteOrder.Uint32(buf.Next(4))
@@ -4159,9 +4207,9 @@ func (this *Pointer) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodePointerButton:
 		if this.l == nil {
-			fmt.Println("ignoring Button event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Pointer -> Button event: no listener")
 		} else {
-			fmt.Println("Received Pointer -> Button: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Pointer -> Button: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -4173,9 +4221,9 @@ func (this *Pointer) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodePointerAxis:
 		if this.l == nil {
-			fmt.Println("ignoring Axis event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Pointer -> Axis event: no listener")
 		} else {
-			fmt.Println("Received Pointer -> Axis: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Pointer -> Axis: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			time := hostByteOrder.Uint32(buf.Next(4))
@@ -4186,17 +4234,17 @@ func (this *Pointer) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodePointerFrame:
 		if this.l == nil {
-			fmt.Println("ignoring Frame event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Pointer -> Frame event: no listener")
 		} else {
-			fmt.Println("Received Pointer -> Frame: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Pointer -> Frame: Dispatching")
 
 			this.l.Frame()
 		}
 	case opCodePointerAxisSource:
 		if this.l == nil {
-			fmt.Println("ignoring AxisSource event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Pointer -> AxisSource event: no listener")
 		} else {
-			fmt.Println("Received Pointer -> AxisSource: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Pointer -> AxisSource: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			axisSource := hostByteOrder.Uint32(buf.Next(4))
@@ -4205,9 +4253,9 @@ func (this *Pointer) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodePointerAxisStop:
 		if this.l == nil {
-			fmt.Println("ignoring AxisStop event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Pointer -> AxisStop event: no listener")
 		} else {
-			fmt.Println("Received Pointer -> AxisStop: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Pointer -> AxisStop: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			time := hostByteOrder.Uint32(buf.Next(4))
@@ -4217,9 +4265,9 @@ func (this *Pointer) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodePointerAxisDiscrete:
 		if this.l == nil {
-			fmt.Println("ignoring AxisDiscrete event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Pointer -> AxisDiscrete event: no listener")
 		} else {
-			fmt.Println("Received Pointer -> AxisDiscrete: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Pointer -> AxisDiscrete: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			axis := hostByteOrder.Uint32(buf.Next(4))
@@ -4287,8 +4335,9 @@ func (this *Pointer) SetCursor(serial uint32, surface uint32, hotspotX int32, ho
 	binary.Write(this.c.buf, hostByteOrder, uint32(hotspotY))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodePointerSetCursor)
 
-	fmt.Println("Sending Pointer -> SetCursor")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Pointer -> SetCursor\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -4319,8 +4368,9 @@ func (this *Pointer) Release() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodePointerRelease)
 
-	fmt.Println("Sending Pointer -> Release")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Pointer -> Release\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -4445,9 +4495,9 @@ func (this *Keyboard) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeKeyboardKeymap:
 		if this.l == nil {
-			fmt.Println("ignoring Keymap event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Keyboard -> Keymap event: no listener")
 		} else {
-			fmt.Println("Received Keyboard -> Keymap: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Keyboard -> Keymap: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			format := hostByteOrder.Uint32(buf.Next(4))
@@ -4458,9 +4508,9 @@ func (this *Keyboard) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeKeyboardEnter:
 		if this.l == nil {
-			fmt.Println("ignoring Enter event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Keyboard -> Enter event: no listener")
 		} else {
-			fmt.Println("Received Keyboard -> Enter: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Keyboard -> Enter: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -4476,9 +4526,9 @@ func (this *Keyboard) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeKeyboardLeave:
 		if this.l == nil {
-			fmt.Println("ignoring Leave event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Keyboard -> Leave event: no listener")
 		} else {
-			fmt.Println("Received Keyboard -> Leave: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Keyboard -> Leave: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -4488,9 +4538,9 @@ func (this *Keyboard) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeKeyboardKey:
 		if this.l == nil {
-			fmt.Println("ignoring Key event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Keyboard -> Key event: no listener")
 		} else {
-			fmt.Println("Received Keyboard -> Key: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Keyboard -> Key: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -4502,9 +4552,9 @@ func (this *Keyboard) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeKeyboardModifiers:
 		if this.l == nil {
-			fmt.Println("ignoring Modifiers event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Keyboard -> Modifiers event: no listener")
 		} else {
-			fmt.Println("Received Keyboard -> Modifiers: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Keyboard -> Modifiers: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -4517,9 +4567,9 @@ func (this *Keyboard) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeKeyboardRepeatInfo:
 		if this.l == nil {
-			fmt.Println("ignoring RepeatInfo event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Keyboard -> RepeatInfo event: no listener")
 		} else {
-			fmt.Println("Received Keyboard -> RepeatInfo: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Keyboard -> RepeatInfo: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			rate := int32(hostByteOrder.Uint32(buf.Next(4)))
@@ -4552,8 +4602,9 @@ func (this *Keyboard) Release() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeKeyboardRelease)
 
-	fmt.Println("Sending Keyboard -> Release")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Keyboard -> Release\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -4722,9 +4773,9 @@ func (this *Touch) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeTouchDown:
 		if this.l == nil {
-			fmt.Println("ignoring Down event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Touch -> Down event: no listener")
 		} else {
-			fmt.Println("Received Touch -> Down: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Touch -> Down: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -4738,9 +4789,9 @@ func (this *Touch) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeTouchUp:
 		if this.l == nil {
-			fmt.Println("ignoring Up event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Touch -> Up event: no listener")
 		} else {
-			fmt.Println("Received Touch -> Up: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Touch -> Up: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -4751,9 +4802,9 @@ func (this *Touch) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeTouchMotion:
 		if this.l == nil {
-			fmt.Println("ignoring Motion event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Touch -> Motion event: no listener")
 		} else {
-			fmt.Println("Received Touch -> Motion: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Touch -> Motion: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			time := hostByteOrder.Uint32(buf.Next(4))
@@ -4765,25 +4816,25 @@ func (this *Touch) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeTouchFrame:
 		if this.l == nil {
-			fmt.Println("ignoring Frame event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Touch -> Frame event: no listener")
 		} else {
-			fmt.Println("Received Touch -> Frame: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Touch -> Frame: Dispatching")
 
 			this.l.Frame()
 		}
 	case opCodeTouchCancel:
 		if this.l == nil {
-			fmt.Println("ignoring Cancel event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Touch -> Cancel event: no listener")
 		} else {
-			fmt.Println("Received Touch -> Cancel: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Touch -> Cancel: Dispatching")
 
 			this.l.Cancel()
 		}
 	case opCodeTouchShape:
 		if this.l == nil {
-			fmt.Println("ignoring Shape event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Touch -> Shape event: no listener")
 		} else {
-			fmt.Println("Received Touch -> Shape: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Touch -> Shape: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			id := int32(hostByteOrder.Uint32(buf.Next(4)))
@@ -4794,9 +4845,9 @@ func (this *Touch) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeTouchOrientation:
 		if this.l == nil {
-			fmt.Println("ignoring Orientation event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Touch -> Orientation event: no listener")
 		} else {
-			fmt.Println("Received Touch -> Orientation: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Touch -> Orientation: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			id := int32(hostByteOrder.Uint32(buf.Next(4)))
@@ -4829,8 +4880,9 @@ func (this *Touch) Release() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeTouchRelease)
 
-	fmt.Println("Sending Touch -> Release")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Touch -> Release\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -4979,9 +5031,9 @@ func (this *Output) dispatch(opCode uint16, payload []byte, file *os.File) {
 	switch opCode {
 	case opCodeOutputGeometry:
 		if this.l == nil {
-			fmt.Println("ignoring Geometry event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Output -> Geometry event: no listener")
 		} else {
-			fmt.Println("Received Output -> Geometry: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Output -> Geometry: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			x := int32(hostByteOrder.Uint32(buf.Next(4)))
@@ -5005,9 +5057,9 @@ func (this *Output) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeOutputMode:
 		if this.l == nil {
-			fmt.Println("ignoring Mode event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Output -> Mode event: no listener")
 		} else {
-			fmt.Println("Received Output -> Mode: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Output -> Mode: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			flags := hostByteOrder.Uint32(buf.Next(4))
@@ -5019,17 +5071,17 @@ func (this *Output) dispatch(opCode uint16, payload []byte, file *os.File) {
 		}
 	case opCodeOutputDone:
 		if this.l == nil {
-			fmt.Println("ignoring Done event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Output -> Done event: no listener")
 		} else {
-			fmt.Println("Received Output -> Done: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Output -> Done: Dispatching")
 
 			this.l.Done()
 		}
 	case opCodeOutputScale:
 		if this.l == nil {
-			fmt.Println("ignoring Scale event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring Output -> Scale event: no listener")
 		} else {
-			fmt.Println("Received Output -> Scale: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received Output -> Scale: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			factor := int32(hostByteOrder.Uint32(buf.Next(4)))
@@ -5063,8 +5115,9 @@ func (this *Output) Release() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeOutputRelease)
 
-	fmt.Println("Sending Output -> Release")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Output -> Release\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -5154,8 +5207,9 @@ func (this *Region) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeRegionDestroy)
 
-	fmt.Println("Sending Region -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Region -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -5186,8 +5240,9 @@ func (this *Region) Add(x int32, y int32, width int32, height int32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(height))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeRegionAdd)
 
-	fmt.Println("Sending Region -> Add")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Region -> Add\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -5218,8 +5273,9 @@ func (this *Region) Subtract(x int32, y int32, width int32, height int32) error
 	binary.Write(this.c.buf, hostByteOrder, uint32(height))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeRegionSubtract)
 
-	fmt.Println("Sending Region -> Subtract")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Region -> Subtract\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -5329,8 +5385,9 @@ func (this *Subcompositor) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSubcompositorDestroy)
 
-	fmt.Println("Sending Subcompositor -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Subcompositor -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -5367,8 +5424,9 @@ func (this *Subcompositor) GetSubsurface(l SubsurfaceListener, surface uint32, p
 	binary.Write(this.c.buf, hostByteOrder, uint32(parent))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSubcompositorGetSubsurface)
 	ret.l = l
-	fmt.Println("Sending Subcompositor -> GetSubsurface")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Subcompositor -> GetSubsurface\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -5514,8 +5572,9 @@ func (this *Subsurface) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSubsurfaceDestroy)
 
-	fmt.Println("Sending Subsurface -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Subsurface -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -5559,8 +5618,9 @@ func (this *Subsurface) SetPosition(x int32, y int32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(y))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSubsurfaceSetPosition)
 
-	fmt.Println("Sending Subsurface -> SetPosition")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Subsurface -> SetPosition\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -5602,8 +5662,9 @@ func (this *Subsurface) PlaceAbove(sibling uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(sibling))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSubsurfacePlaceAbove)
 
-	fmt.Println("Sending Subsurface -> PlaceAbove")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Subsurface -> PlaceAbove\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -5632,8 +5693,9 @@ func (this *Subsurface) PlaceBelow(sibling uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(sibling))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSubsurfacePlaceBelow)
 
-	fmt.Println("Sending Subsurface -> PlaceBelow")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Subsurface -> PlaceBelow\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -5672,8 +5734,9 @@ func (this *Subsurface) SetSync() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSubsurfaceSetSync)
 
-	fmt.Println("Sending Subsurface -> SetSync")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Subsurface -> SetSync\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -5718,8 +5781,9 @@ func (this *Subsurface) SetDesync() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeSubsurfaceSetDesync)
 
-	fmt.Println("Sending Subsurface -> SetDesync")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending Subsurface -> SetDesync\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/hex"
-	"fmt"
 	"os"
 
+	"github.com/elliotmr/gdl/log"
 	"github.com/pkg/errors"
 )
 
@@ -99,9 +99,9 @@ func (this *ZxdgShellV6) dispatch(opCode uint16, payload []byte, file *os.File)
 	switch opCode {
 	case opCodeZxdgShellV6Ping:
 		if this.l == nil {
-			fmt.Println("ignoring Ping event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring ZxdgShellV6 -> Ping event: no listener")
 		} else {
-			fmt.Println("Received ZxdgShellV6 -> Ping: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received ZxdgShellV6 -> Ping: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -138,8 +138,9 @@ func (this *ZxdgShellV6) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgShellV6Destroy)
 
-	fmt.Println("Sending ZxdgShellV6 -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgShellV6 -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -170,8 +171,9 @@ func (this *ZxdgShellV6) CreatePositioner(l ZxdgPositionerV6Listener) (*ZxdgPosi
 	binary.Write(this.c.buf, hostByteOrder, uint32(ret.i))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgShellV6CreatePositioner)
 	ret.l = l
-	fmt.Println("Sending ZxdgShellV6 -> CreatePositioner")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgShellV6 -> CreatePositioner\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -211,8 +213,9 @@ func (this *ZxdgShellV6) GetXdgSurface(l ZxdgSurfaceV6Listener, surface uint32)
 	binary.Write(this.c.buf, hostByteOrder, uint32(surface))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgShellV6GetXdgSurface)
 	ret.l = l
-	fmt.Println("Sending ZxdgShellV6 -> GetXdgSurface")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgShellV6 -> GetXdgSurface\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -241,8 +244,9 @@ func (this *ZxdgShellV6) Pong(serial uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(serial))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgShellV6Pong)
 
-	fmt.Println("Sending ZxdgShellV6 -> Pong")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgShellV6 -> Pong\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -381,8 +385,9 @@ func (this *ZxdgPositionerV6) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgPositionerV6Destroy)
 
-	fmt.Println("Sending ZxdgPositionerV6 -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgPositionerV6 -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -415,8 +420,9 @@ func (this *ZxdgPositionerV6) SetSize(width int32, height int32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(height))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgPositionerV6SetSize)
 
-	fmt.Println("Sending ZxdgPositionerV6 -> SetSize")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgPositionerV6 -> SetSize\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -456,8 +462,9 @@ func (this *ZxdgPositionerV6) SetAnchorRect(x int32, y int32, width int32, heigh
 	binary.Write(this.c.buf, hostByteOrder, uint32(height))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgPositionerV6SetAnchorRect)
 
-	fmt.Println("Sending ZxdgPositionerV6 -> SetAnchorRect")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgPositionerV6 -> SetAnchorRect\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -494,8 +501,9 @@ func (this *ZxdgPositionerV6) SetAnchor(anchor uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(anchor))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgPositionerV6SetAnchor)
 
-	fmt.Println("Sending ZxdgPositionerV6 -> SetAnchor")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgPositionerV6 -> SetAnchor\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -531,8 +539,9 @@ func (this *ZxdgPositionerV6) SetGravity(gravity uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(gravity))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgPositionerV6SetGravity)
 
-	fmt.Println("Sending ZxdgPositionerV6 -> SetGravity")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgPositionerV6 -> SetGravity\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -572,8 +581,9 @@ func (this *ZxdgPositionerV6) SetConstraintAdjustment(constraintAdjustment uint3
 	binary.Write(this.c.buf, hostByteOrder, uint32(constraintAdjustment))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgPositionerV6SetConstraintAdjustment)
 
-	fmt.Println("Sending ZxdgPositionerV6 -> SetConstraintAdjustment")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgPositionerV6 -> SetConstraintAdjustment\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -612,8 +622,9 @@ func (this *ZxdgPositionerV6) SetOffset(x int32, y int32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(y))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgPositionerV6SetOffset)
 
-	fmt.Println("Sending ZxdgPositionerV6 -> SetOffset")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgPositionerV6 -> SetOffset\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -736,9 +747,9 @@ func (this *ZxdgSurfaceV6) dispatch(opCode uint16, payload []byte, file *os.File
 	switch opCode {
 	case opCodeZxdgSurfaceV6Configure:
 		if this.l == nil {
-			fmt.Println("ignoring Configure event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring ZxdgSurfaceV6 -> Configure event: no listener")
 		} else {
-			fmt.Println("Received ZxdgSurfaceV6 -> Configure: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received ZxdgSurfaceV6 -> Configure: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			serial := hostByteOrder.Uint32(buf.Next(4))
@@ -772,8 +783,9 @@ func (this *ZxdgSurfaceV6) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgSurfaceV6Destroy)
 
-	fmt.Println("Sending ZxdgSurfaceV6 -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgSurfaceV6 -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -806,8 +818,9 @@ func (this *ZxdgSurfaceV6) GetToplevel(l ZxdgToplevelV6Listener) (*ZxdgToplevelV
 	binary.Write(this.c.buf, hostByteOrder, uint32(ret.i))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgSurfaceV6GetToplevel)
 	ret.l = l
-	fmt.Println("Sending ZxdgSurfaceV6 -> GetToplevel")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgSurfaceV6 -> GetToplevel\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -842,8 +855,9 @@ func (this *ZxdgSurfaceV6) GetPopup(l ZxdgPopupV6Listener, parent uint32, positi
 	binary.Write(this.c.buf, hostByteOrder, uint32(positioner))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgSurfaceV6GetPopup)
 	ret.l = l
-	fmt.Println("Sending ZxdgSurfaceV6 -> GetPopup")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgSurfaceV6 -> GetPopup\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return ret, nil
 }
@@ -897,8 +911,9 @@ func (this *ZxdgSurfaceV6) SetWindowGeometry(x int32, y int32, width int32, heig
 	binary.Write(this.c.buf, hostByteOrder, uint32(height))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgSurfaceV6SetWindowGeometry)
 
-	fmt.Println("Sending ZxdgSurfaceV6 -> SetWindowGeometry")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgSurfaceV6 -> SetWindowGeometry\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -944,8 +959,9 @@ func (this *ZxdgSurfaceV6) AckConfigure(serial uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(serial))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgSurfaceV6AckConfigure)
 
-	fmt.Println("Sending ZxdgSurfaceV6 -> AckConfigure")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgSurfaceV6 -> AckConfigure\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1080,9 +1096,9 @@ func (this *ZxdgToplevelV6) dispatch(opCode uint16, payload []byte, file *os.Fil
 	switch opCode {
 	case opCodeZxdgToplevelV6Configure:
 		if this.l == nil {
-			fmt.Println("ignoring Configure event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring ZxdgToplevelV6 -> Configure event: no listener")
 		} else {
-			fmt.Println("Received ZxdgToplevelV6 -> Configure: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received ZxdgToplevelV6 -> Configure: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			width := int32(hostByteOrder.Uint32(buf.Next(4)))
@@ -1098,9 +1114,9 @@ func (this *ZxdgToplevelV6) dispatch(opCode uint16, payload []byte, file *os.Fil
 		}
 	case opCodeZxdgToplevelV6Close:
 		if this.l == nil {
-			fmt.Println("ignoring Close event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring ZxdgToplevelV6 -> Close event: no listener")
 		} else {
-			fmt.Println("Received ZxdgToplevelV6 -> Close: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received ZxdgToplevelV6 -> Close: Dispatching")
 
 			this.l.Close()
 		}
@@ -1132,8 +1148,9 @@ func (this *ZxdgToplevelV6) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6Destroy)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1167,8 +1184,9 @@ func (this *ZxdgToplevelV6) SetParent(parent uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(parent))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6SetParent)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> SetParent")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> SetParent\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1207,8 +1225,9 @@ func (this *ZxdgToplevelV6) SetTitle(title string) error {
 	}
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6SetTitle)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> SetTitle")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> SetTitle\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1260,8 +1279,9 @@ func (this *ZxdgToplevelV6) SetAppID(appID string) error {
 	}
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6SetAppID)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> SetAppID")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> SetAppID\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1302,8 +1322,9 @@ func (this *ZxdgToplevelV6) ShowWindowMenu(seat uint32, serial uint32, x int32,
 	binary.Write(this.c.buf, hostByteOrder, uint32(y))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6ShowWindowMenu)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> ShowWindowMenu")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> ShowWindowMenu\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1347,8 +1368,9 @@ func (this *ZxdgToplevelV6) Move(seat uint32, serial uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(serial))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6Move)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> Move")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> Move\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1407,8 +1429,9 @@ func (this *ZxdgToplevelV6) Resize(seat uint32, serial uint32, edges uint32) err
 	binary.Write(this.c.buf, hostByteOrder, uint32(edges))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6Resize)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> Resize")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> Resize\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1470,8 +1493,9 @@ func (this *ZxdgToplevelV6) SetMaxSize(width int32, height int32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(height))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6SetMaxSize)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> SetMaxSize")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> SetMaxSize\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1533,8 +1557,9 @@ func (this *ZxdgToplevelV6) SetMinSize(width int32, height int32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(height))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6SetMinSize)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> SetMinSize")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> SetMinSize\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1576,8 +1601,9 @@ func (this *ZxdgToplevelV6) SetMaximized() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6SetMaximized)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> SetMaximized")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> SetMaximized\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1620,8 +1646,9 @@ func (this *ZxdgToplevelV6) UnsetMaximized() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6UnsetMaximized)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> UnsetMaximized")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> UnsetMaximized\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1657,8 +1684,9 @@ func (this *ZxdgToplevelV6) SetFullscreen(output uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(output))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6SetFullscreen)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> SetFullscreen")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> SetFullscreen\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1684,8 +1712,9 @@ func (this *ZxdgToplevelV6) UnsetFullscreen() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6UnsetFullscreen)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> UnsetFullscreen")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> UnsetFullscreen\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1719,8 +1748,9 @@ func (this *ZxdgToplevelV6) SetMinimized() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgToplevelV6SetMinimized)
 
-	fmt.Println("Sending ZxdgToplevelV6 -> SetMinimized")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgToplevelV6 -> SetMinimized\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1839,9 +1869,9 @@ func (this *ZxdgPopupV6) dispatch(opCode uint16, payload []byte, file *os.File)
 	switch opCode {
 	case opCodeZxdgPopupV6Configure:
 		if this.l == nil {
-			fmt.Println("ignoring Configure event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring ZxdgPopupV6 -> Configure event: no listener")
 		} else {
-			fmt.Println("Received ZxdgPopupV6 -> Configure: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received ZxdgPopupV6 -> Configure: Dispatching")
 			buf := bytes.NewBuffer(payload)
 			_ = buf
 			x := int32(hostByteOrder.Uint32(buf.Next(4)))
@@ -1853,9 +1883,9 @@ func (this *ZxdgPopupV6) dispatch(opCode uint16, payload []byte, file *os.File)
 		}
 	case opCodeZxdgPopupV6PopupDone:
 		if this.l == nil {
-			fmt.Println("ignoring PopupDone event: no listener")
+			log.Debug(log.CategoryWayland, "ignoring ZxdgPopupV6 -> PopupDone event: no listener")
 		} else {
-			fmt.Println("Received ZxdgPopupV6 -> PopupDone: Dispatching")
+			log.Verbose(log.CategoryWayland, "Received ZxdgPopupV6 -> PopupDone: Dispatching")
 
 			this.l.PopupDone()
 		}
@@ -1889,8 +1919,9 @@ func (this *ZxdgPopupV6) Destroy() error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(0))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgPopupV6Destroy)
 
-	fmt.Println("Sending ZxdgPopupV6 -> Destroy")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgPopupV6 -> Destroy\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }
@@ -1959,8 +1990,9 @@ func (this *ZxdgPopupV6) Grab(seat uint32, serial uint32) error {
 	binary.Write(this.c.buf, hostByteOrder, uint32(serial))
 	hostByteOrder.PutUint32(this.c.buf.Bytes()[4:8], uint32(this.c.buf.Len())<<16|opCodeZxdgPopupV6Grab)
 
-	fmt.Println("Sending ZxdgPopupV6 -> Grab")
-	fmt.Println(hex.Dump(this.c.buf.Bytes()))
+	if log.Enabled(log.CategoryWayland, log.PriorityVerbose) {
+		log.Verbose(log.CategoryWayland, "Sending ZxdgPopupV6 -> Grab\n%s", hex.Dump(this.c.buf.Bytes()))
+	}
 	this.c.c.WriteMsgUnix(this.c.buf.Bytes(), oob, nil)
 	return nil
 }